@@ -41,8 +41,25 @@ const (
 	AnnotationClusterID = "infini.cloud/cluster-id"
 	// AnnotationChangeWebhookURL is the annotation key for webhook URL
 	AnnotationChangeWebhookURL = "infini.cloud/change-webhook-url"
+	// AnnotationChangeWebhookSecret names the Secret, in the application's namespace, whose key signs the
+	// webhook requests with HMAC-SHA256, as "name" or "name/key". The key defaults to DefaultWebhookSecretKey.
+	// Without it the signing secret of the operator configuration, if any, is used.
+	AnnotationChangeWebhookSecret = "infini.cloud/change-webhook-secret"
+	// AnnotationChangeWebhookFormat selects the payload format of the webhook requests: "webhook" (the
+	// plain JSON event), "cloudevents" (CloudEvents structured mode) or "cloudevents-binary" (binary mode).
+	// Without it the format of the operator configuration is used.
+	AnnotationChangeWebhookFormat = "infini.cloud/change-webhook-format"
+	// AnnotationDryRun set to "true" makes the controller compute a plan of the changes with
+	// server-side dry-run applies instead of applying them, until the plan is approved.
+	AnnotationDryRun = "infini.cloud/dry-run"
+	// AnnotationApprovedPlanHash approves the plan with the given hash (see status.plan.hash),
+	// letting the real apply continue while in dry-run mode.
+	AnnotationApprovedPlanHash = "infini.cloud/approved-plan-hash"
 )
 
+// DefaultWebhookSecretKey is the key of the webhook signing Secret used when the annotation names none.
+const DefaultWebhookSecretKey = "secret"
+
 // --- Constants for Phase and Conditions ---
 
 // ApplicationPhase represents the current state of the ApplicationDefinition reconciliation process.
@@ -75,6 +92,12 @@ const (
 	// ConditionReady signifies that the application as a whole is ready and available.
 	// Its status reflects the overall health based on all components.
 	ConditionReady ConditionType = "Ready"
+	// ConditionAppHealthy is set per component and reflects the application-level health check,
+	// evaluated once the component's Kubernetes workload is ready.
+	ConditionAppHealthy ConditionType = "AppHealthy"
+	// ConditionDrifted is True when live resources diverge from the desired state.
+	// Its message lists the diverging fields.
+	ConditionDrifted ConditionType = "Drifted"
 	// Add other standard condition types if needed, e.g., "Progressing"
 )
 
@@ -100,8 +123,45 @@ type ApplicationComponent struct {
 	// +kubebuilder:pruning:PreserveUnknownFields
 	// +kubebuilder:validation:Type=object
 	Properties runtime.RawExtension `json:"properties"`
+
+	// RestartRequestedAt requests a rolling restart of this component's workloads.
+	// Set it to a new value (typically an RFC3339 timestamp) to trigger a restart; the
+	// later of this value and the application-wide spec.restartRequestedAt is honoured.
+	// +optional
+	RestartRequestedAt string `json:"restartRequestedAt,omitempty"`
+
+	// Required marks whether this component counts towards application readiness under the
+	// "Required" health policy. Defaults to true.
+	// +optional
+	Required *bool `json:"required,omitempty"`
 }
 
+// HealthPolicy defines how component health is aggregated into application readiness.
+// +kubebuilder:validation:Enum=All;Quorum;Required
+type HealthPolicy string
+
+const (
+	// HealthPolicyAll requires every component to be healthy.
+	HealthPolicyAll HealthPolicy = "All"
+	// HealthPolicyQuorum requires more than half of the components to be healthy.
+	HealthPolicyQuorum HealthPolicy = "Quorum"
+	// HealthPolicyRequired requires only the components marked as required to be healthy.
+	HealthPolicyRequired HealthPolicy = "Required"
+)
+
+// DriftPolicy defines how the controller reacts to live resources diverging from the desired state.
+// +kubebuilder:validation:Enum=Ignore;Report;Correct
+type DriftPolicy string
+
+const (
+	// DriftPolicyIgnore disables drift detection.
+	DriftPolicyIgnore DriftPolicy = "Ignore"
+	// DriftPolicyReport sets the Drifted condition and emits an event, leaving the live resources as they are.
+	DriftPolicyReport DriftPolicy = "Report"
+	// DriftPolicyCorrect reports the drift and re-applies the desired state.
+	DriftPolicyCorrect DriftPolicy = "Correct"
+)
+
 // --- Spec and Status ---
 
 // ApplicationDefinitionSpec defines the desired state of an ApplicationDefinition.
@@ -117,6 +177,34 @@ type ApplicationDefinitionSpec struct {
 	// When true, all components will be scaled to zero replicas.
 	// +optional
 	Suspend *bool `json:"suspend,omitempty"`
+
+	// RestartRequestedAt requests a rolling restart of all components.
+	// Set it to a new value (typically an RFC3339 timestamp) to trigger a restart.
+	// The honoured value is recorded in status.restartedAt, so re-applying the same value is a no-op.
+	// +optional
+	RestartRequestedAt string `json:"restartRequestedAt,omitempty"`
+
+	// HealthPolicy defines how component health is aggregated into the application's Ready condition.
+	// Defaults to All.
+	// +optional
+	// +kubebuilder:default=All
+	HealthPolicy HealthPolicy `json:"healthPolicy,omitempty"`
+
+	// DriftPolicy defines how changes made to managed resources outside the operator are handled.
+	// Defaults to Report.
+	// +optional
+	// +kubebuilder:default=Report
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+
+	// DriftScanInterval is how often a stable application is compared with its live resources.
+	// Defaults to 5m.
+	// +optional
+	DriftScanInterval *metav1.Duration `json:"driftScanInterval,omitempty"`
+
+	// NotificationChannelSelector selects, by label, the NotificationChannels in the application's namespace
+	// that receive its events, with or without a change ID. No channel is selected when unset.
+	// +optional
+	NotificationChannelSelector *metav1.LabelSelector `json:"notificationChannelSelector,omitempty"`
 }
 
 // ComponentStatusReference provides a summary of the status of a deployed component's primary resource.
@@ -127,12 +215,12 @@ type ComponentStatusReference struct {
 	Name string `json:"name"`
 
 	// Kind is the Kubernetes Kind of the primary workload resource managed for this component (e.g., StatefulSet, Deployment).
-	// Derived from the corresponding ComponentDefinition.
+	// Identified from the built objects using the builder strategy's workload GVK.
 	// +optional
 	Kind string `json:"kind,omitempty"`
 
 	// APIVersion is the API version of the primary workload resource (e.g., apps/v1).
-	// Derived from the corresponding ComponentDefinition.
+	// Identified from the built objects using the builder strategy's workload GVK.
 	// +optional
 	APIVersion string `json:"apiVersion,omitempty"`
 
@@ -152,6 +240,77 @@ type ComponentStatusReference struct {
 	// Message provides a human-readable status message or error details for the component.
 	// +optional
 	Message string `json:"message,omitempty"`
+
+	// Reason is a CamelCase code for the failure that made the component unhealthy in the last reconcile,
+	// e.g. "BuilderFailed" or "FieldManagerConflict". Empty when the component had no error.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Conditions provide observations of the component's state, e.g. "AppHealthy".
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Conflicts lists fields of this component's resources that are owned by other field managers
+	// and made the last Server-Side Apply fail. Empty when the apply succeeded.
+	// +optional
+	Conflicts []FieldConflict `json:"conflicts,omitempty"`
+
+	// Pods summarizes the pods of the component's workload, sorted by name.
+	// +optional
+	Pods []ComponentPodStatus `json:"pods,omitempty"`
+
+	// Diagnosis is a concise explanation of why the component's pods are not running,
+	// e.g. "pod infini-gw-1 OOMKilled (limit 512Mi)". Empty when no problem was detected.
+	// +optional
+	Diagnosis string `json:"diagnosis,omitempty"`
+}
+
+// ComponentPodStatus summarizes one pod of a component's workload.
+type ComponentPodStatus struct {
+	// Name is the name of the pod.
+	Name string `json:"name"`
+
+	// Node is the node the pod is scheduled on. Empty while the pod is unscheduled.
+	// +optional
+	Node string `json:"node,omitempty"`
+
+	// Phase is the pod phase (Pending, Running, Succeeded, Failed, Unknown).
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// Ready is true when the pod's Ready condition is true.
+	// +optional
+	Ready bool `json:"ready"`
+
+	// RestartCount is the sum of the restart counts of the pod's containers.
+	// +optional
+	RestartCount int32 `json:"restartCount,omitempty"`
+
+	// LastTerminationReason is the reason of the most recent container termination, e.g. "OOMKilled" or "Error".
+	// +optional
+	LastTerminationReason string `json:"lastTerminationReason,omitempty"`
+
+	// Image is the image of the pod's main container.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Diagnosis explains why the pod is not running, e.g. "CrashLoopBackOff (container app, exit code 1)".
+	// +optional
+	Diagnosis string `json:"diagnosis,omitempty"`
+}
+
+// FieldConflict describes a field of a managed resource owned by another field manager.
+type FieldConflict struct {
+	// Resource identifies the conflicting object as "Kind/name".
+	Resource string `json:"resource"`
+
+	// Field is the path of the conflicting field, e.g. ".spec.replicas".
+	Field string `json:"field"`
+
+	// Manager is the field manager currently owning the field.
+	Manager string `json:"manager"`
 }
 
 // ApplicationDefinitionStatus defines the observed state of ApplicationDefinition.
@@ -184,15 +343,159 @@ type ApplicationDefinitionStatus struct {
 	// +optional
 	SuspendedReplicas map[string]int32 `json:"suspendedReplicas,omitempty"`
 
+	// RestartedAt records, per component, the restart request value that has been honoured,
+	// i.e. the rolling restart it triggered has completed and the component is healthy again.
+	// +optional
+	RestartedAt map[string]string `json:"restartedAt,omitempty"`
+
 	// LastChangeID records the last change ID that was processed and sent to the webhook.
 	// This is used to avoid sending duplicate webhook events for the same change ID.
 	// +optional
 	LastChangeID string `json:"lastChangeID,omitempty"`
 
+	// Changes tracks the most recent changes, oldest first; the last one is the change named by the
+	// change-id annotation. Reusing a change ID for a new spec generation starts it again.
+	// +optional
+	Changes []ChangeStatus `json:"changes,omitempty"`
+
+	// ConfigChecksums records, per component, the config checksum of the last successfully applied workload.
+	// A change in referenced ConfigMaps/Secrets is detected by comparing against this value.
+	// +optional
+	ConfigChecksums map[string]string `json:"configChecksums,omitempty"`
+
+	// Plan summarizes the pending change plan computed in dry-run mode.
+	// The full per-object plan is stored in the ConfigMap named in plan.configMapName.
+	// +optional
+	Plan *PlanStatus `json:"plan,omitempty"`
+
+	// Webhook reports the change events waiting in the webhook outbox, and those given up on.
+	// Unset when every event has been delivered.
+	// +optional
+	Webhook *WebhookDeliveryStatus `json:"webhook,omitempty"`
+
 	// Annotations holds additional metadata annotations for the application definition.
+	// Deprecated: config hashes are no longer stored here; see ConfigChecksums. The field is cleared by the controller.
+	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
+// PlanStatus summarizes a change plan computed in dry-run mode.
+type PlanStatus struct {
+	// Hash identifies the plan. Approve it by setting the infini.cloud/approved-plan-hash annotation to this value.
+	Hash string `json:"hash"`
+
+	// ConfigMapName is the name of the ConfigMap holding the per-object plan.
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// Creates is the number of objects that would be created.
+	// +optional
+	Creates int32 `json:"creates,omitempty"`
+
+	// Updates is the number of objects that would be updated.
+	// +optional
+	Updates int32 `json:"updates,omitempty"`
+
+	// Orphans is the number of objects labelled as part of the application that are no longer desired.
+	// The apply leaves them in place.
+	// +optional
+	Orphans int32 `json:"orphans,omitempty"`
+
+	// Errors is the number of objects whose dry-run apply was rejected.
+	// +optional
+	Errors int32 `json:"errors,omitempty"`
+
+	// Approved is true once the plan hash has been approved and the changes are being applied.
+	// +optional
+	Approved bool `json:"approved,omitempty"`
+
+	// GeneratedAt is when this plan was first computed.
+	// +optional
+	GeneratedAt *metav1.Time `json:"generatedAt,omitempty"`
+}
+
+// ChangeState is the state of a change.
+// +kubebuilder:validation:Enum=InProgress;Completed;Failed;Superseded
+type ChangeState string
+
+const (
+	// ChangeStateInProgress indicates the change is being applied.
+	ChangeStateInProgress ChangeState = "InProgress"
+	// ChangeStateCompleted indicates all components became ready with the change applied.
+	ChangeStateCompleted ChangeState = "Completed"
+	// ChangeStateFailed indicates the change failed with an error that retrying cannot fix.
+	ChangeStateFailed ChangeState = "Failed"
+	// ChangeStateSuperseded indicates another change ID was set before the change ended.
+	ChangeStateSuperseded ChangeState = "Superseded"
+)
+
+// ChangeStatus tracks a change, identified by the change-id annotation, from its start to its end.
+type ChangeStatus struct {
+	// ID is the change ID.
+	ID string `json:"id"`
+
+	// State is the state of the change.
+	State ChangeState `json:"state"`
+
+	// Generation is the spec generation the change was started for.
+	// +optional
+	Generation int64 `json:"generation,omitempty"`
+
+	// StartedAt is when the change started.
+	StartedAt metav1.Time `json:"startedAt"`
+
+	// EndedAt is when the change left the InProgress state.
+	// +optional
+	EndedAt *metav1.Time `json:"endedAt,omitempty"`
+
+	// Created and Updated list the objects the change created and updated, as "Kind/name".
+	// +optional
+	Created []string `json:"created,omitempty"`
+	// +optional
+	Updated []string `json:"updated,omitempty"`
+}
+
+// WebhookDeliveryStatus reports the delivery of change events to the change webhook.
+// Events are kept in an outbox ConfigMap and delivered in order by the leading operator instance.
+type WebhookDeliveryStatus struct {
+	// Pending is the number of events waiting to be delivered.
+	// +optional
+	Pending int32 `json:"pending,omitempty"`
+
+	// LastError is the error of the last failed attempt to deliver the oldest pending event.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// DeadLetters lists, oldest first, the most recent events given up on after the retry limit.
+	// "kubectl infini replay-webhooks" queues them for delivery again.
+	// +optional
+	DeadLetters []WebhookDeadLetter `json:"deadLetters,omitempty"`
+}
+
+// WebhookDeadLetter describes a change event that could not be delivered.
+type WebhookDeadLetter struct {
+	// ChangeID is the change the event belongs to.
+	ChangeID string `json:"changeID"`
+
+	// Phase, Step and Status identify the event within the change.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+	// +optional
+	Step string `json:"step,omitempty"`
+	// +optional
+	Status string `json:"status,omitempty"`
+
+	// Attempts is how many times delivery was attempted.
+	Attempts int32 `json:"attempts"`
+
+	// LastError is the error of the last attempt.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// DeadLetteredAt is when the event was given up on.
+	DeadLetteredAt metav1.Time `json:"deadLetteredAt"`
+}
+
 // --- Root Object ---
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,165 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// api/app/v1/notificationchannel_types.go
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NotificationFormat is the payload format of the requests sent to a notification channel.
+// +kubebuilder:validation:Enum=webhook;cloudevents;cloudevents-binary
+type NotificationFormat string
+
+const (
+	// NotificationFormatWebhook sends the operator's JSON event payload.
+	NotificationFormatWebhook NotificationFormat = "webhook"
+	// NotificationFormatCloudEvents sends structured-mode CloudEvents.
+	NotificationFormatCloudEvents NotificationFormat = "cloudevents"
+	// NotificationFormatCloudEventsBinary sends binary-mode CloudEvents.
+	NotificationFormatCloudEventsBinary NotificationFormat = "cloudevents-binary"
+)
+
+// ConditionDelivering is True on a NotificationChannel while its events are delivered without error.
+const ConditionDelivering ConditionType = "Delivering"
+
+// NotificationSecretReference selects the key of a Secret in the channel's namespace.
+type NotificationSecretReference struct {
+	// Name of the Secret.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key within the Secret. Defaults to "secret".
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// NotificationFilter selects the events sent to a channel. Empty lists match every event;
+// an event must match all non-empty lists.
+type NotificationFilter struct {
+	// Phases lists the phases of the events to send, e.g. "Reconcile" or "HealthCheck".
+	// +optional
+	Phases []string `json:"phases,omitempty"`
+
+	// Statuses lists the statuses of the events to send: "in_progress", "success" or "failed".
+	// +optional
+	Statuses []string `json:"statuses,omitempty"`
+
+	// Levels lists the Kubernetes event types of the events to send: "Normal" or "Warning".
+	// +optional
+	Levels []string `json:"levels,omitempty"`
+}
+
+// NotificationRateLimit bounds the rate of events queued for a channel. Events over the limit are dropped.
+type NotificationRateLimit struct {
+	// EventsPerMinute is the sustained rate of events.
+	// +kubebuilder:validation:Minimum=1
+	EventsPerMinute int32 `json:"eventsPerMinute"`
+
+	// Burst is how many events may be queued at once above the sustained rate. Defaults to EventsPerMinute.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Burst int32 `json:"burst,omitempty"`
+}
+
+// NotificationChannelSpec defines where and which operator events are sent.
+type NotificationChannelSpec struct {
+	// URL is the endpoint the events are posted to.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// Format is the payload format of the requests. Defaults to the operator's webhook format.
+	// +optional
+	Format NotificationFormat `json:"format,omitempty"`
+
+	// SigningSecret references the key the requests are signed with using HMAC-SHA256.
+	// Defaults to the operator's webhook signing secret, if any.
+	// +optional
+	SigningSecret *NotificationSecretReference `json:"signingSecret,omitempty"`
+
+	// Filter selects the events sent to the channel. Defaults to all events.
+	// +optional
+	Filter NotificationFilter `json:"filter,omitempty"`
+
+	// RateLimit bounds the rate of events sent to the channel. Unlimited when unset.
+	// +optional
+	RateLimit *NotificationRateLimit `json:"rateLimit,omitempty"`
+}
+
+// NotificationChannelStatus reports the delivery of events to the channel.
+// Events are kept in an outbox ConfigMap and delivered in order by the leading operator instance.
+type NotificationChannelStatus struct {
+	// ObservedGeneration is the generation of the spec last delivered with.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions holds the Delivering condition.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// Pending is the number of events waiting to be delivered.
+	// +optional
+	Pending int32 `json:"pending,omitempty"`
+
+	// LastError is the error of the last failed attempt to deliver the oldest pending event.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// DeadLetters lists, oldest first, the most recent events given up on after the retry limit.
+	// +optional
+	DeadLetters []WebhookDeadLetter `json:"deadLetters,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,path=notificationchannels,shortName=notifch,categories={infini}
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=".spec.url"
+// +kubebuilder:printcolumn:name="Delivering",type=string,JSONPath=".status.conditions[?(@.type=='Delivering')].status"
+// +kubebuilder:printcolumn:name="Pending",type=integer,JSONPath=".status.pending"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// NotificationChannel is the Schema for the notificationchannels API, a sink for the events of the
+// ApplicationDefinitions whose notificationChannelSelector matches its labels.
+type NotificationChannel struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NotificationChannelSpec   `json:"spec,omitempty"`
+	Status NotificationChannelStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NotificationChannelList contains a list of NotificationChannel.
+type NotificationChannelList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NotificationChannel `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NotificationChannel{}, &NotificationChannelList{})
+}
@@ -0,0 +1,286 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package v1alpha1 contains the versioned configuration file format of the runtime operator.
+// The file is loaded with the --config flag of the manager; it is not served by the API server.
+// +kubebuilder:object:generate=false
+// +groupName=config.infini.cloud
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// GroupVersion is the apiVersion of the operator configuration file.
+	GroupVersion = schema.GroupVersion{Group: "config.infini.cloud", Version: "v1alpha1"}
+)
+
+// Kind is the kind of the operator configuration file.
+const Kind = "OperatorConfiguration"
+
+// Default values, also used when no configuration file is given.
+const (
+	DefaultRequeueInterval         = 30 * time.Second
+	DefaultConflictRequeueInterval = 5 * time.Second
+	DefaultFinalizerRetries        = 3
+	DefaultStatusUpdateRetries     = 3
+	DefaultMaxConcurrency          = 4
+	DefaultForceOwnership          = true
+	DefaultHealthResyncPeriod      = 1 * time.Minute
+
+	DefaultWebhookEventTTL             = 1 * time.Hour
+	DefaultWebhookTimeout              = 10 * time.Second
+	DefaultWebhookRetryMaxAttempts     = 8
+	DefaultWebhookRetryInitialInterval = 10 * time.Second
+	DefaultWebhookRetryMaxInterval     = 5 * time.Minute
+	DefaultWebhookFormat               = "webhook"
+
+	DefaultInitContainerImage = "busybox:latest"
+
+	DefaultTracingExporter    = "none"
+	DefaultTracingServiceName = "runtime-operator"
+)
+
+// OperatorConfiguration is the configuration file of the runtime operator.
+type OperatorConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Namespace is the namespace the operator watches and reconciles ApplicationDefinitions in.
+	// When empty it is taken from the NAMESPACE environment variable, then from the in-cluster
+	// service account, falling back to "default".
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Reconcile tunes the ApplicationDefinition controller.
+	// +optional
+	Reconcile ReconcileConfiguration `json:"reconcile,omitempty"`
+
+	// Webhook tunes the change webhook event recorder.
+	// +optional
+	Webhook WebhookConfiguration `json:"webhook,omitempty"`
+
+	// Defaults are applied to built workloads when a component leaves the corresponding field unset.
+	// +optional
+	Defaults BuilderDefaults `json:"defaults,omitempty"`
+
+	// Tracing exports OpenTelemetry traces of reconciles.
+	// +optional
+	Tracing TracingConfiguration `json:"tracing,omitempty"`
+}
+
+// ReconcileConfiguration tunes the ApplicationDefinition controller.
+type ReconcileConfiguration struct {
+	// RequeueInterval is how long to wait before reconciling an application that is not ready yet.
+	// +optional
+	RequeueInterval metav1.Duration `json:"requeueInterval,omitempty"`
+
+	// ConflictRequeueInterval replaces RequeueInterval when the status update hit a conflict.
+	// +optional
+	ConflictRequeueInterval metav1.Duration `json:"conflictRequeueInterval,omitempty"`
+
+	// FinalizerRetries is how many times adding or removing the finalizer is attempted on conflicts.
+	// +optional
+	FinalizerRetries int `json:"finalizerRetries,omitempty"`
+
+	// StatusUpdateRetries is how many times a status update is attempted on conflicts.
+	// +optional
+	StatusUpdateRetries int `json:"statusUpdateRetries,omitempty"`
+
+	// MaxConcurrency bounds how many components are built, and objects applied, in parallel.
+	// +optional
+	MaxConcurrency int `json:"maxConcurrency,omitempty"`
+
+	// ForceOwnership makes Server-Side Apply take over fields owned by other field managers, such as
+	// kubectl edits or an autoscaler, instead of failing with a conflict. Defaults to true.
+	// +optional
+	ForceOwnership *bool `json:"forceOwnership,omitempty"`
+
+	// HealthResyncPeriod is how often the health of Running and Degraded applications is re-evaluated
+	// when no owned workload changes. Each resync is jittered by up to 20%.
+	// +optional
+	HealthResyncPeriod metav1.Duration `json:"healthResyncPeriod,omitempty"`
+}
+
+// WebhookConfiguration tunes the change webhook event recorder.
+type WebhookConfiguration struct {
+	// EventTTL is how long a sent event is remembered to suppress duplicates.
+	// +optional
+	EventTTL metav1.Duration `json:"eventTTL,omitempty"`
+
+	// Timeout bounds each webhook request.
+	// +optional
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+
+	// RetryMaxAttempts is how many times an event is sent before it is dead-lettered.
+	// +optional
+	RetryMaxAttempts int `json:"retryMaxAttempts,omitempty"`
+
+	// RetryInitialInterval is the backoff before the first retry; it doubles on every further retry.
+	// +optional
+	RetryInitialInterval metav1.Duration `json:"retryInitialInterval,omitempty"`
+
+	// RetryMaxInterval caps the backoff between retries.
+	// +optional
+	RetryMaxInterval metav1.Duration `json:"retryMaxInterval,omitempty"`
+
+	// SigningSecret references the key of a Secret, in the operator namespace, that signs webhook requests
+	// with HMAC-SHA256. Applications can use their own with the infini.cloud/change-webhook-secret annotation.
+	// Requests are unsigned when neither is set.
+	// +optional
+	SigningSecret *SecretKeyReference `json:"signingSecret,omitempty"`
+
+	// TLS configures how webhook endpoints are trusted and how the operator authenticates to them.
+	// +optional
+	TLS WebhookTLSConfiguration `json:"tls,omitempty"`
+
+	// Format is the payload format of webhook requests: "webhook" (the plain JSON event), "cloudevents"
+	// (CloudEvents 1.0 structured mode) or "cloudevents-binary" (binary mode). Applications can choose
+	// their own with the infini.cloud/change-webhook-format annotation.
+	// +optional
+	Format string `json:"format,omitempty"`
+}
+
+// SecretKeyReference references a key of a Secret.
+type SecretKeyReference struct {
+	// Name is the name of the Secret.
+	Name string `json:"name"`
+
+	// Key is the key of the Secret data holding the value.
+	Key string `json:"key"`
+}
+
+// WebhookTLSConfiguration configures TLS towards webhook endpoints. Endpoints are verified against the
+// system roots unless CAFile is set.
+type WebhookTLSConfiguration struct {
+	// CAFile is the path of a PEM bundle of the certificate authorities trusted for webhook endpoints.
+	// +optional
+	CAFile string `json:"caFile,omitempty"`
+
+	// CertFile and KeyFile are the paths of the PEM client certificate and key presented to endpoints
+	// requiring mutual TLS. Both or neither must be set.
+	// +optional
+	CertFile string `json:"certFile,omitempty"`
+	// +optional
+	KeyFile string `json:"keyFile,omitempty"`
+
+	// InsecureSkipVerify disables the verification of endpoint certificates. For testing only.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// BuilderDefaults are applied to built workloads when a component leaves the corresponding field unset.
+type BuilderDefaults struct {
+	// InitContainerImage is the image of the init container preparing data directories.
+	// +optional
+	InitContainerImage string `json:"initContainerImage,omitempty"`
+
+	// StorageClassName is used by volume claims that do not name a storage class.
+	// When empty the cluster default storage class applies.
+	// +optional
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// RegistryMirrors rewrites image registries, keyed by the registry host to replace
+	// (e.g. "docker.io") with the mirror host, optionally with a path prefix, as value.
+	// +optional
+	RegistryMirrors map[string]string `json:"registryMirrors,omitempty"`
+}
+
+// TracingConfiguration configures the OpenTelemetry traces of reconciles. Spans are sampled according to
+// the standard OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG environment variables, all by default.
+type TracingConfiguration struct {
+	// Exporter is where spans are sent: "none" (tracing disabled), "otlp-grpc", "otlp-http" or "stdout".
+	// +optional
+	Exporter string `json:"exporter,omitempty"`
+
+	// Endpoint is the OTLP collector, as host:port or as a URL. When empty the standard
+	// OTEL_EXPORTER_OTLP_ENDPOINT environment variables apply, then the local collector.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Insecure sends OTLP spans without TLS.
+	// +optional
+	Insecure bool `json:"insecure,omitempty"`
+
+	// ServiceName is the service.name resource attribute of the spans.
+	// +optional
+	ServiceName string `json:"serviceName,omitempty"`
+}
+
+// SetDefaults fills every unset field of the configuration with its default value.
+func SetDefaults(cfg *OperatorConfiguration) {
+	if cfg.APIVersion == "" {
+		cfg.APIVersion = GroupVersion.String()
+	}
+	if cfg.Kind == "" {
+		cfg.Kind = Kind
+	}
+
+	rc := &cfg.Reconcile
+	setDefaultDuration(&rc.RequeueInterval, DefaultRequeueInterval)
+	setDefaultDuration(&rc.ConflictRequeueInterval, DefaultConflictRequeueInterval)
+	setDefaultInt(&rc.FinalizerRetries, DefaultFinalizerRetries)
+	setDefaultInt(&rc.StatusUpdateRetries, DefaultStatusUpdateRetries)
+	setDefaultInt(&rc.MaxConcurrency, DefaultMaxConcurrency)
+	if rc.ForceOwnership == nil {
+		force := DefaultForceOwnership
+		rc.ForceOwnership = &force
+	}
+	setDefaultDuration(&rc.HealthResyncPeriod, DefaultHealthResyncPeriod)
+
+	wc := &cfg.Webhook
+	setDefaultDuration(&wc.EventTTL, DefaultWebhookEventTTL)
+	setDefaultDuration(&wc.Timeout, DefaultWebhookTimeout)
+	setDefaultInt(&wc.RetryMaxAttempts, DefaultWebhookRetryMaxAttempts)
+	setDefaultDuration(&wc.RetryInitialInterval, DefaultWebhookRetryInitialInterval)
+	setDefaultDuration(&wc.RetryMaxInterval, DefaultWebhookRetryMaxInterval)
+	if wc.Format == "" {
+		wc.Format = DefaultWebhookFormat
+	}
+
+	if cfg.Defaults.InitContainerImage == "" {
+		cfg.Defaults.InitContainerImage = DefaultInitContainerImage
+	}
+
+	if cfg.Tracing.Exporter == "" {
+		cfg.Tracing.Exporter = DefaultTracingExporter
+	}
+	if cfg.Tracing.ServiceName == "" {
+		cfg.Tracing.ServiceName = DefaultTracingServiceName
+	}
+}
+
+func setDefaultDuration(d *metav1.Duration, value time.Duration) {
+	if d.Duration == 0 {
+		d.Duration = value
+	}
+}
+
+func setDefaultInt(i *int, value int) {
+	if *i == 0 {
+		*i = value
+	}
+}
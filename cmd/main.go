@@ -24,10 +24,13 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
+	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/cisco-open/operator-tools/pkg/reconciler"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -45,16 +48,23 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	appv1api "github.com/infinilabs/runtime-operator/api/app/v1"
+	configv1alpha1 "github.com/infinilabs/runtime-operator/api/config/v1alpha1"
+	operatorconfig "github.com/infinilabs/runtime-operator/internal/config"
 	appcontroller "github.com/infinilabs/runtime-operator/internal/controller/app"
+	"github.com/infinilabs/runtime-operator/internal/tracing"
 	"github.com/infinilabs/runtime-operator/pkg/apis/common"
 	commonutil "github.com/infinilabs/runtime-operator/pkg/apis/common/util"
+	"github.com/infinilabs/runtime-operator/pkg/webrecorder"
 )
 
 var (
@@ -86,6 +96,10 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var maxConcurrency int
+	var forceOwnership bool
+	var webhookInsecureSkipVerify bool
+	var configFile string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -104,6 +118,16 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.IntVar(&maxConcurrency, "max-concurrency", 4,
+		"Maximum number of components built, and resources applied, in parallel within one reconciliation.")
+	flag.BoolVar(&forceOwnership, "force-ownership", true,
+		"If set, Server-Side Apply takes over fields owned by other field managers instead of reporting conflicts. "+
+			"Use --force-ownership=false to report them as conflicts instead.")
+	flag.BoolVar(&webhookInsecureSkipVerify, "webhook-insecure-skip-verify", false,
+		"If set, the certificates of change webhook endpoints are not verified. For testing only.")
+	flag.StringVar(&configFile, "config", "",
+		"Path to an OperatorConfiguration (config.infini.cloud/v1alpha1) file. "+
+			"Flags set explicitly on the command line take precedence over the file.")
 	opts := zap.Options{
 		Development: false,
 	}
@@ -112,6 +136,53 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	operatorConfig := operatorconfig.Default()
+	if configFile != "" {
+		var err error
+		if operatorConfig, err = operatorconfig.Load(configFile); err != nil {
+			setupLog.Error(err, "unable to load operator configuration")
+			os.Exit(1)
+		}
+		setupLog.Info("loaded operator configuration", "config", configFile)
+	}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "max-concurrency":
+			operatorConfig.Reconcile.MaxConcurrency = maxConcurrency
+		case "force-ownership":
+			operatorConfig.Reconcile.ForceOwnership = &forceOwnership
+		case "webhook-insecure-skip-verify":
+			operatorConfig.Webhook.TLS.InsecureSkipVerify = webhookInsecureSkipVerify
+		}
+	})
+	if operatorConfig.Namespace != "" {
+		common.Namespace = operatorConfig.Namespace
+	}
+	operatorconfig.ApplyBuilderDefaults(operatorConfig)
+	webhookTLSConfig, err := operatorconfig.WebhookTLSConfig(operatorConfig)
+	if err != nil {
+		setupLog.Error(err, "unable to load webhook TLS configuration")
+		os.Exit(1)
+	}
+	if operatorConfig.Webhook.TLS.InsecureSkipVerify {
+		setupLog.Info("WARNING: change webhook endpoint certificates are not verified")
+	}
+	webrecorder.SetDefaultOptions(webrecorder.Options{
+		EventTTL:             operatorConfig.Webhook.EventTTL.Duration,
+		Timeout:              operatorConfig.Webhook.Timeout.Duration,
+		RetryMaxAttempts:     operatorConfig.Webhook.RetryMaxAttempts,
+		RetryInitialInterval: operatorConfig.Webhook.RetryInitialInterval.Duration,
+		RetryMaxInterval:     operatorConfig.Webhook.RetryMaxInterval.Duration,
+		TLSConfig:            webhookTLSConfig,
+		SigningSecret:        operatorconfig.WebhookSigningSecret(operatorConfig, common.Namespace),
+		Format:               webrecorder.Format(operatorConfig.Webhook.Format),
+	})
+	shutdownTracing, err := tracing.Setup(context.Background(), operatorConfig.Tracing)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -205,7 +276,7 @@ func main() {
 		Scheme:                 scheme,
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
-		HealthProbeBindAddress: probeAddr,
+		HealthProbeBindAddress: "0", // Served by newHealthProbeServer, together with /debug/config
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "56afa202.infini.cloud",
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
@@ -230,6 +301,36 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Flush the spans not exported yet when the manager stops
+	if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return shutdownTracing(context.Background())
+	})); err != nil {
+		setupLog.Error(err, "unable to add tracing shutdown to manager")
+		os.Exit(1)
+	}
+
+	// Webhook events are persisted in per-application outboxes and delivered by the leader
+	webhookOutbox := webrecorder.NewOutbox(mgr.GetClient(), mgr.GetAPIReader())
+	webrecorder.SetDefaultOptions(webrecorder.Options{Outbox: webhookOutbox, SecretReader: mgr.GetAPIReader()})
+	if err := mgr.Add(webrecorder.NewDispatcher(webhookOutbox, appcontroller.ReportWebhookDelivery(mgr.GetClient()))); err != nil {
+		setupLog.Error(err, "unable to add webhook outbox dispatcher to manager")
+		os.Exit(1)
+	}
+	// Events of applications selecting notification channels are persisted in per-channel outboxes
+	notificationOutbox := appcontroller.NewNotificationOutbox(mgr.GetClient(), mgr.GetAPIReader())
+	if err := mgr.Add(webrecorder.NewDispatcher(notificationOutbox, appcontroller.ReportNotificationDelivery(mgr.GetClient()))); err != nil {
+		setupLog.Error(err, "unable to add notification outbox dispatcher to manager")
+		os.Exit(1)
+	}
+	notifier := appcontroller.NewNotifier(mgr.GetClient(), notificationOutbox, mgr.GetEventRecorderFor("notification-channel"))
+	// Webhook recorders are shared per change across reconciles and controllers
+	webhookRecorders := webrecorder.NewRegistry()
+	if err := mgr.Add(webhookRecorders); err != nil {
+		setupLog.Error(err, "unable to add webhook recorder registry to manager")
+		os.Exit(1)
+	}
+
 	// SetK8sVersionGreaterOrEqual
 	config := mgr.GetConfig()
 	// 检测集群是否 ≥ v1.21
@@ -238,15 +339,40 @@ func main() {
 	if err = (&appcontroller.ApplicationDefinitionReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
-		Reconciler: reconciler.NewReconcilerWith(mgr.GetClient(),
+		// Objects the resource reconciler recreates are owned by the operator, to be migrated on the next apply
+		Reconciler: reconciler.NewReconcilerWith(client.WithFieldOwner(mgr.GetClient(), common.OperatorName),
 			reconciler.WithEnableRecreateWorkload(),
 		),
+		MaxConcurrency:          operatorConfig.Reconcile.MaxConcurrency,
+		ForceOwnership:          *operatorConfig.Reconcile.ForceOwnership,
+		RequeueInterval:         operatorConfig.Reconcile.RequeueInterval.Duration,
+		ConflictRequeueInterval: operatorConfig.Reconcile.ConflictRequeueInterval.Duration,
+		FinalizerRetries:        operatorConfig.Reconcile.FinalizerRetries,
+		StatusUpdateRetries:     operatorConfig.Reconcile.StatusUpdateRetries,
+		WebhookRecorders:        webhookRecorders,
+		Notifier:                notifier,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ApplicationDefinition")
 		os.Exit(1)
 	}
+	if err = (&appcontroller.HealthMonitorReconciler{
+		Client:           mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		ResyncPeriod:     operatorConfig.Reconcile.HealthResyncPeriod.Duration,
+		WebhookRecorders: webhookRecorders,
+		Notifier:         notifier,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ApplicationDefinitionHealth")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
+	// Application and component state is served next to controller-runtime's metrics on /metrics
+	if err := metrics.Registry.Register(appcontroller.NewApplicationCollector(mgr.GetClient())); err != nil {
+		setupLog.Error(err, "unable to register application metrics")
+		os.Exit(1)
+	}
+
 	if metricsCertWatcher != nil {
 		setupLog.Info("Adding metrics certificate watcher to manager")
 		if err := mgr.Add(metricsCertWatcher); err != nil {
@@ -263,13 +389,11 @@ func main() {
 		}
 	}
 
-	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up health check")
-		os.Exit(1)
-	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up ready check")
-		os.Exit(1)
+	if probeAddr != "0" {
+		if err := mgr.Add(newHealthProbeServer(probeAddr, operatorConfig)); err != nil {
+			setupLog.Error(err, "unable to set up health probe server")
+			os.Exit(1)
+		}
 	}
 
 	setupLog.Info("starting manager")
@@ -278,3 +402,24 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// newHealthProbeServer returns the server of the /healthz and /readyz probes. It also serves the
+// effective operator configuration on /debug/config, so that it is available while the metrics
+// server is disabled (the default).
+func newHealthProbeServer(addr string, cfg *configv1alpha1.OperatorConfiguration) *manager.Server {
+	healthzHandler := &healthz.Handler{Checks: map[string]healthz.Checker{"healthz": healthz.Ping}}
+	readyzHandler := &healthz.Handler{Checks: map[string]healthz.Checker{"readyz": healthz.Ping}}
+
+	mux := http.NewServeMux()
+	// The '/' suffixed paths serve the individual checks, e.g. /healthz/healthz
+	mux.Handle("/healthz", http.StripPrefix("/healthz", healthzHandler))
+	mux.Handle("/healthz/", http.StripPrefix("/healthz", healthzHandler))
+	mux.Handle("/readyz", http.StripPrefix("/readyz", readyzHandler))
+	mux.Handle("/readyz/", http.StripPrefix("/readyz", readyzHandler))
+	mux.Handle("/debug/config", operatorconfig.Handler(cfg))
+
+	return &manager.Server{
+		Name:   "health probe",
+		Server: &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 32 * time.Second},
+	}
+}
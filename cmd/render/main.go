@@ -0,0 +1,146 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// cmd/render/main.go
+// render prints the manifests the operator would apply for ApplicationDefinitions, without a cluster.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+
+	appv1api "github.com/infinilabs/runtime-operator/api/app/v1"
+	operatorconfig "github.com/infinilabs/runtime-operator/internal/config"
+	"github.com/infinilabs/runtime-operator/internal/render"
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+	commonutil "github.com/infinilabs/runtime-operator/pkg/apis/common/util"
+
+	_ "github.com/infinilabs/runtime-operator/pkg/builders/runtime"
+)
+
+func main() {
+	var filename, output, outputDir, diffDir, namespace, kubeVersion, configFile string
+	flag.StringVar(&filename, "f", "-", "ApplicationDefinition YAML or JSON file to render, - reads from stdin.")
+	flag.StringVar(&output, "o", string(render.FormatYAML), "Output format: yaml or json.")
+	flag.StringVar(&outputDir, "output-dir", "", "Write one YAML file per manifest to this directory instead of stdout.")
+	flag.StringVar(&diffDir, "diff", "", "Compare the rendered manifests with a directory written by --output-dir. "+
+		"Prints a unified diff and exits with status 1 when they differ.")
+	flag.StringVar(&namespace, "namespace", common.Namespace, "Namespace for ApplicationDefinitions that do not set one.")
+	flag.StringVar(&kubeVersion, "kube-version", "1.30", "Kubernetes version to render for, e.g. 1.20 renders policy/v1beta1 PodDisruptionBudgets.")
+	flag.StringVar(&configFile, "config", "", "OperatorConfiguration file whose builder defaults (init image, storage class, "+
+		"registry mirrors) apply to the rendered manifests, as in the manager.")
+	flag.Parse()
+
+	if configFile != "" {
+		cfg, err := operatorconfig.Load(configFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "render:", err)
+			os.Exit(2)
+		}
+		operatorconfig.ApplyBuilderDefaults(cfg)
+	}
+
+	code, err := run(filename, render.Format(output), outputDir, diffDir, namespace, kubeVersion)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "render:", err)
+	}
+	os.Exit(code)
+}
+
+func run(filename string, format render.Format, outputDir, diffDir, namespace, kubeVersion string) (int, error) {
+	if err := setKubeVersion(kubeVersion); err != nil {
+		return 2, err
+	}
+
+	var in io.Reader = os.Stdin
+	if filename != "-" {
+		f, err := os.Open(filename)
+		if err != nil {
+			return 2, err
+		}
+		defer f.Close()
+		in = f
+	}
+	apps, err := render.DecodeApplications(in, namespace)
+	if err != nil {
+		return 2, err
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(appv1api.AddToScheme(scheme))
+	renderer := render.NewRenderer(scheme)
+
+	var manifests []render.Manifest
+	for _, appDef := range apps {
+		rendered, err := renderer.Render(context.Background(), appDef)
+		if err != nil {
+			return 2, err
+		}
+		manifests = append(manifests, rendered...)
+	}
+
+	switch {
+	case diffDir != "":
+		diff, err := render.Diff(diffDir, manifests)
+		if err != nil {
+			return 2, err
+		}
+		if diff != "" {
+			fmt.Print(diff)
+			return 1, nil
+		}
+		return 0, nil
+	case outputDir != "":
+		return 0, render.WriteDir(outputDir, manifests)
+	default:
+		return 0, render.Encode(os.Stdout, manifests, format)
+	}
+}
+
+// setKubeVersion selects the API versions builders use, as the manager does from cluster discovery.
+func setKubeVersion(version string) error {
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) < 2 {
+		return fmt.Errorf("invalid --kube-version %q, expected <major>.<minor>", version)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return fmt.Errorf("invalid --kube-version %q: %w", version, err)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return fmt.Errorf("invalid --kube-version %q: %w", version, err)
+	}
+	commonutil.IsV1Supported = major > 1 || (major == 1 && minor >= 21)
+	return nil
+}
@@ -0,0 +1,205 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/config/config.go
+// Package config loads and validates the operator configuration file.
+package config
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+
+	configv1alpha1 "github.com/infinilabs/runtime-operator/api/config/v1alpha1"
+	"github.com/infinilabs/runtime-operator/internal/tracing"
+	builders "github.com/infinilabs/runtime-operator/pkg/builders/k8s"
+	"github.com/infinilabs/runtime-operator/pkg/webrecorder"
+)
+
+// Default returns the configuration used when no file is given.
+func Default() *configv1alpha1.OperatorConfiguration {
+	cfg := &configv1alpha1.OperatorConfiguration{}
+	configv1alpha1.SetDefaults(cfg)
+	return cfg
+}
+
+// Load reads the configuration file at path, fills in defaults and validates it.
+func Load(path string) (*configv1alpha1.OperatorConfiguration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read operator configuration %s: %w", path, err)
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid operator configuration %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse decodes a configuration document, rejecting unknown fields, then fills in defaults and validates it.
+func Parse(data []byte) (*configv1alpha1.OperatorConfiguration, error) {
+	cfg := &configv1alpha1.OperatorConfiguration{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, err
+	}
+	if cfg.APIVersion != configv1alpha1.GroupVersion.String() || cfg.Kind != configv1alpha1.Kind {
+		return nil, fmt.Errorf("expected apiVersion %q and kind %q, got %q and %q",
+			configv1alpha1.GroupVersion.String(), configv1alpha1.Kind, cfg.APIVersion, cfg.Kind)
+	}
+	configv1alpha1.SetDefaults(cfg)
+	if err := Validate(cfg).ToAggregate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks a defaulted configuration.
+func Validate(cfg *configv1alpha1.OperatorConfiguration) field.ErrorList {
+	var errs field.ErrorList
+
+	if cfg.Namespace != "" {
+		for _, msg := range validation.IsDNS1123Label(cfg.Namespace) {
+			errs = append(errs, field.Invalid(field.NewPath("namespace"), cfg.Namespace, msg))
+		}
+	}
+
+	rcPath := field.NewPath("reconcile")
+	rc := cfg.Reconcile
+	errs = append(errs, validatePositive(rcPath.Child("requeueInterval"), int64(rc.RequeueInterval.Duration), rc.RequeueInterval.Duration.String())...)
+	errs = append(errs, validatePositive(rcPath.Child("conflictRequeueInterval"), int64(rc.ConflictRequeueInterval.Duration), rc.ConflictRequeueInterval.Duration.String())...)
+	errs = append(errs, validatePositive(rcPath.Child("finalizerRetries"), int64(rc.FinalizerRetries), rc.FinalizerRetries)...)
+	errs = append(errs, validatePositive(rcPath.Child("statusUpdateRetries"), int64(rc.StatusUpdateRetries), rc.StatusUpdateRetries)...)
+	errs = append(errs, validatePositive(rcPath.Child("maxConcurrency"), int64(rc.MaxConcurrency), rc.MaxConcurrency)...)
+	errs = append(errs, validatePositive(rcPath.Child("healthResyncPeriod"), int64(rc.HealthResyncPeriod.Duration), rc.HealthResyncPeriod.Duration.String())...)
+
+	whPath := field.NewPath("webhook")
+	wc := cfg.Webhook
+	errs = append(errs, validatePositive(whPath.Child("eventTTL"), int64(wc.EventTTL.Duration), wc.EventTTL.Duration.String())...)
+	errs = append(errs, validatePositive(whPath.Child("timeout"), int64(wc.Timeout.Duration), wc.Timeout.Duration.String())...)
+	errs = append(errs, validatePositive(whPath.Child("retryMaxAttempts"), int64(wc.RetryMaxAttempts), wc.RetryMaxAttempts)...)
+	errs = append(errs, validatePositive(whPath.Child("retryInitialInterval"), int64(wc.RetryInitialInterval.Duration), wc.RetryInitialInterval.Duration.String())...)
+	if wc.RetryMaxInterval.Duration < wc.RetryInitialInterval.Duration {
+		errs = append(errs, field.Invalid(whPath.Child("retryMaxInterval"), wc.RetryMaxInterval.Duration.String(), "must not be less than retryInitialInterval"))
+	}
+	if ref := wc.SigningSecret; ref != nil {
+		refPath := whPath.Child("signingSecret")
+		for _, msg := range validation.IsDNS1123Subdomain(ref.Name) {
+			errs = append(errs, field.Invalid(refPath.Child("name"), ref.Name, msg))
+		}
+		for _, msg := range validation.IsConfigMapKey(ref.Key) {
+			errs = append(errs, field.Invalid(refPath.Child("key"), ref.Key, msg))
+		}
+	}
+	if _, err := webrecorder.ParseFormat(wc.Format); err != nil {
+		errs = append(errs, field.NotSupported(whPath.Child("format"), wc.Format,
+			[]string{string(webrecorder.FormatWebhook), string(webrecorder.FormatCloudEvents), string(webrecorder.FormatCloudEventsBinary)}))
+	}
+	tlsPath := whPath.Child("tls")
+	if (wc.TLS.CertFile == "") != (wc.TLS.KeyFile == "") {
+		errs = append(errs, field.Invalid(tlsPath.Child("keyFile"), wc.TLS.KeyFile, "certFile and keyFile must be set together"))
+	}
+	if wc.TLS.InsecureSkipVerify && wc.TLS.CAFile != "" {
+		errs = append(errs, field.Invalid(tlsPath.Child("insecureSkipVerify"), true, "must not be set together with caFile"))
+	}
+
+	defPath := field.NewPath("defaults")
+	if strings.ContainsAny(cfg.Defaults.InitContainerImage, " \t\n") {
+		errs = append(errs, field.Invalid(defPath.Child("initContainerImage"), cfg.Defaults.InitContainerImage, "must not contain whitespace"))
+	}
+	if sc := cfg.Defaults.StorageClassName; sc != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(sc) {
+			errs = append(errs, field.Invalid(defPath.Child("storageClassName"), sc, msg))
+		}
+	}
+	for registry, mirror := range cfg.Defaults.RegistryMirrors {
+		mirrorPath := defPath.Child("registryMirrors").Key(registry)
+		if registry == "" || strings.Contains(registry, "/") {
+			errs = append(errs, field.Invalid(mirrorPath, registry, "must be a registry host without a path"))
+		}
+		if mirror == "" || strings.HasSuffix(mirror, "/") || strings.Contains(mirror, "://") {
+			errs = append(errs, field.Invalid(mirrorPath, mirror, "must be a registry host, optionally followed by a path, without scheme or trailing slash"))
+		}
+	}
+
+	trPath := field.NewPath("tracing")
+	if !slices.Contains(tracing.Exporters, cfg.Tracing.Exporter) {
+		errs = append(errs, field.NotSupported(trPath.Child("exporter"), cfg.Tracing.Exporter, tracing.Exporters))
+	}
+	if cfg.Tracing.Endpoint != "" && (cfg.Tracing.Exporter == tracing.ExporterNone || cfg.Tracing.Exporter == tracing.ExporterStdout) {
+		errs = append(errs, field.Invalid(trPath.Child("endpoint"), cfg.Tracing.Endpoint, "only used by the otlp-grpc and otlp-http exporters"))
+	}
+	return errs
+}
+
+func validatePositive(path *field.Path, value int64, shown interface{}) field.ErrorList {
+	if value <= 0 {
+		return field.ErrorList{field.Invalid(path, shown, "must be greater than zero")}
+	}
+	return nil
+}
+
+// ApplyBuilderDefaults installs the builder defaults of the configuration for every component built afterwards.
+func ApplyBuilderDefaults(cfg *configv1alpha1.OperatorConfiguration) {
+	builders.SetBuilderDefaults(builders.BuilderDefaults{
+		InitContainerImage: cfg.Defaults.InitContainerImage,
+		StorageClassName:   cfg.Defaults.StorageClassName,
+		RegistryMirrors:    cfg.Defaults.RegistryMirrors,
+	})
+}
+
+// WebhookTLSConfig returns the TLS configuration of webhook requests, loading the configured files.
+func WebhookTLSConfig(cfg *configv1alpha1.OperatorConfiguration) (*tls.Config, error) {
+	return webrecorder.NewTLSConfig(webrecorder.TLSOptions{
+		CAFile:             cfg.Webhook.TLS.CAFile,
+		CertFile:           cfg.Webhook.TLS.CertFile,
+		KeyFile:            cfg.Webhook.TLS.KeyFile,
+		InsecureSkipVerify: cfg.Webhook.TLS.InsecureSkipVerify,
+	})
+}
+
+// WebhookSigningSecret returns the operator-wide webhook signing secret in namespace, or nil.
+func WebhookSigningSecret(cfg *configv1alpha1.OperatorConfiguration, namespace string) *webrecorder.SigningSecret {
+	ref := cfg.Webhook.SigningSecret
+	if ref == nil {
+		return nil
+	}
+	return &webrecorder.SigningSecret{Namespace: namespace, Name: ref.Name, Key: ref.Key}
+}
+
+// Handler serves the effective configuration as JSON, for the /debug/config endpoint.
+func Handler(cfg *configv1alpha1.OperatorConfiguration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(cfg)
+	})
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	configv1alpha1 "github.com/infinilabs/runtime-operator/api/config/v1alpha1"
+)
+
+func TestLoadSampleConfiguration(t *testing.T) {
+	cfg, err := Load(filepath.Join("..", "..", "config", "manager", "operator_config.yaml"))
+	if err != nil {
+		t.Fatalf("failed to load the sample configuration: %v", err)
+	}
+	if want := Default(); !reflect.DeepEqual(cfg.Reconcile, want.Reconcile) || cfg.Webhook != want.Webhook ||
+		cfg.Defaults.InitContainerImage != want.Defaults.InitContainerImage || cfg.Tracing != want.Tracing {
+		t.Errorf("sample configuration drifted from the defaults:\n%+v\nwant\n%+v", cfg, want)
+	}
+}
+
+func TestParseAppliesDefaults(t *testing.T) {
+	cfg, err := Parse([]byte(`
+apiVersion: config.infini.cloud/v1alpha1
+kind: OperatorConfiguration
+namespace: infini-system
+reconcile:
+  requeueInterval: 1m
+defaults:
+  storageClassName: fast
+  registryMirrors:
+    docker.io: mirror.example.com/hub
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.Namespace != "infini-system" || cfg.Reconcile.RequeueInterval.Duration != time.Minute {
+		t.Errorf("explicit values were not kept: %+v", cfg)
+	}
+	if cfg.Reconcile.ConflictRequeueInterval.Duration != configv1alpha1.DefaultConflictRequeueInterval ||
+		cfg.Reconcile.StatusUpdateRetries != configv1alpha1.DefaultStatusUpdateRetries ||
+		cfg.Webhook.EventTTL.Duration != configv1alpha1.DefaultWebhookEventTTL {
+		t.Errorf("unset values were not defaulted: %+v", cfg)
+	}
+	if cfg.Reconcile.ForceOwnership == nil || !*cfg.Reconcile.ForceOwnership {
+		t.Errorf("forceOwnership = %v, want true by default", cfg.Reconcile.ForceOwnership)
+	}
+	if cfg.Defaults.RegistryMirrors["docker.io"] != "mirror.example.com/hub" {
+		t.Errorf("registry mirrors were not decoded: %v", cfg.Defaults.RegistryMirrors)
+	}
+}
+
+func TestParseKeepsDisabledForceOwnership(t *testing.T) {
+	cfg, err := Parse([]byte("apiVersion: config.infini.cloud/v1alpha1\nkind: OperatorConfiguration\nreconcile:\n  forceOwnership: false\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.Reconcile.ForceOwnership == nil || *cfg.Reconcile.ForceOwnership {
+		t.Errorf("forceOwnership = %v, want the explicit false", cfg.Reconcile.ForceOwnership)
+	}
+}
+
+func TestParseRejectsInvalidConfiguration(t *testing.T) {
+	tests := map[string]struct {
+		doc  string
+		want string
+	}{
+		"wrong kind": {
+			doc:  "apiVersion: config.infini.cloud/v1alpha1\nkind: Something\n",
+			want: "expected apiVersion",
+		},
+		"unknown field": {
+			doc:  "apiVersion: config.infini.cloud/v1alpha1\nkind: OperatorConfiguration\nreconcile:\n  requeue: 1m\n",
+			want: "unknown field",
+		},
+		"negative interval": {
+			doc:  "apiVersion: config.infini.cloud/v1alpha1\nkind: OperatorConfiguration\nreconcile:\n  requeueInterval: -1s\n",
+			want: "reconcile.requeueInterval",
+		},
+		"bad namespace": {
+			doc:  "apiVersion: config.infini.cloud/v1alpha1\nkind: OperatorConfiguration\nnamespace: Not_Valid\n",
+			want: "namespace",
+		},
+		"retry max interval below initial": {
+			doc:  "apiVersion: config.infini.cloud/v1alpha1\nkind: OperatorConfiguration\nwebhook:\n  retryInitialInterval: 1m\n  retryMaxInterval: 30s\n",
+			want: "webhook.retryMaxInterval",
+		},
+		"client certificate without key": {
+			doc:  "apiVersion: config.infini.cloud/v1alpha1\nkind: OperatorConfiguration\nwebhook:\n  tls:\n    certFile: /tls/tls.crt\n",
+			want: "webhook.tls.keyFile",
+		},
+		"insecure with CA bundle": {
+			doc:  "apiVersion: config.infini.cloud/v1alpha1\nkind: OperatorConfiguration\nwebhook:\n  tls:\n    caFile: /tls/ca.crt\n    insecureSkipVerify: true\n",
+			want: "webhook.tls.insecureSkipVerify",
+		},
+		"signing secret without key": {
+			doc:  "apiVersion: config.infini.cloud/v1alpha1\nkind: OperatorConfiguration\nwebhook:\n  signingSecret:\n    name: webhook-signing\n",
+			want: "webhook.signingSecret.key",
+		},
+		"unknown webhook format": {
+			doc:  "apiVersion: config.infini.cloud/v1alpha1\nkind: OperatorConfiguration\nwebhook:\n  format: xml\n",
+			want: "webhook.format",
+		},
+		"unknown tracing exporter": {
+			doc:  "apiVersion: config.infini.cloud/v1alpha1\nkind: OperatorConfiguration\ntracing:\n  exporter: jaeger\n",
+			want: "tracing.exporter",
+		},
+		"tracing endpoint without OTLP exporter": {
+			doc:  "apiVersion: config.infini.cloud/v1alpha1\nkind: OperatorConfiguration\ntracing:\n  exporter: stdout\n  endpoint: collector:4317\n",
+			want: "tracing.endpoint",
+		},
+		"mirror with scheme": {
+			doc:  "apiVersion: config.infini.cloud/v1alpha1\nkind: OperatorConfiguration\ndefaults:\n  registryMirrors:\n    docker.io: https://mirror.example.com\n",
+			want: "defaults.registryMirrors[docker.io]",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.doc))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a not-exist error, got %v", err)
+	}
+}
+
+func TestHandlerDumpsConfiguration(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Handler(Default()).ServeHTTP(rec, httptest.NewRequest("GET", "/debug/config", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `"requeueInterval": "30s"`) {
+		t.Errorf("dump is missing the requeue interval:\n%s", body)
+	}
+}
@@ -27,8 +27,11 @@ package app
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cisco-open/operator-tools/pkg/reconciler"
@@ -36,18 +39,23 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
 	"github.com/infinilabs/runtime-operator/internal/controller/common/kubeutil"
+	"github.com/infinilabs/runtime-operator/internal/controller/common/reconcileerr"
 	"github.com/infinilabs/runtime-operator/pkg/apis/common"
 	commonutil "github.com/infinilabs/runtime-operator/pkg/apis/common/util"
 	"github.com/infinilabs/runtime-operator/pkg/strategy"
@@ -56,9 +64,15 @@ import (
 
 const (
 	appDefFinalizer   = "infini.cloud/finalizer"
-	appNameLabel      = "infini.cloud/application-name"
-	compNameLabel     = "infini.cloud/component-name"
-	compInstanceLabel = "infini.cloud/component-instance"
+	appNameLabel      = common.AppNameLabel
+	compNameLabel     = common.CompNameLabel
+	compInstanceLabel = common.CompInstanceLabel
+
+	// operatorComponentType is the strategy type every component is dispatched to
+	operatorComponentType = "operator"
+	// legacyFieldManager owns the fields of objects created or updated with Create/Update before
+	// Server-Side Apply was used; the API server derived it from the user agent of the manager binary.
+	legacyFieldManager = "manager"
 )
 
 // reconcileState holds the state throughout a single reconciliation loop.
@@ -69,6 +83,9 @@ type reconcileState struct {
 	componentStatuses   map[string]*appv1.ComponentStatusReference // Current status per component
 	applyResults        map[string]kubeutil.ApplyResult            // Results from applying desiredObjects
 	unmarshalledConfigs map[string]interface{}                     // Store unmarshalled config per component [Added]
+	pendingRestarts     map[string]string                          // Restart tokens stamped but not yet honoured, per component
+	healthSummary       string                                     // Health aggregation summary for the Ready condition
+	forceApply          bool                                       // Take over conflicting fields, set when correcting drift
 	firstError          error                                      // First critical error encountered
 }
 
@@ -79,21 +96,50 @@ type ApplicationDefinitionReconciler struct {
 	Recorder   record.EventRecorder
 	RESTMapper meta.RESTMapper // Keep if needed for GC or complex lookups
 	Reconciler reconciler.ResourceReconciler
+	// MaxConcurrency bounds how many components are built, and objects applied, in parallel.
+	// Zero or a negative value uses defaultMaxConcurrency.
+	MaxConcurrency int
+	// ForceOwnership makes Server-Side Apply take over fields owned by other field managers
+	// instead of reporting them as conflicts. The manager enables it unless configured otherwise.
+	ForceOwnership bool
+	// RequeueInterval and ConflictRequeueInterval control how soon an application that is not ready yet
+	// is reconciled again. Zero uses the configuration defaults (30s and 5s).
+	RequeueInterval         time.Duration
+	ConflictRequeueInterval time.Duration
+	// FinalizerRetries and StatusUpdateRetries bound the attempts on update conflicts. Zero uses 3.
+	FinalizerRetries    int
+	StatusUpdateRetries int
+	// WebhookRecorders shares webhook recorders across reconciles and controllers. Nil uses
+	// a new registry per event, without deduplication across events.
+	WebhookRecorders *webrecorder.Registry
+	// Notifier routes events to the NotificationChannels the applications select. Nil sends none.
+	Notifier *Notifier
+
+	// traceContexts holds the trace context of the reconcile in progress per application, for its events.
+	traceContexts sync.Map
+	// configChanged holds the applications whose referenced ConfigMaps or Secrets changed since their last build.
+	configChanged sync.Map
+	// driftScannedAt holds when each stable application was last scanned for drift.
+	driftScannedAt sync.Map
 }
 
 // RBAC markers... (Ensure they cover all necessary types, including ComponentDefinitions)
 //+kubebuilder:rbac:groups=infini.cloud,resources=applicationdefinitions,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=infini.cloud,resources=applicationdefinitions/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=infini.cloud,resources=applicationdefinitions/finalizers,verbs=update
+//+kubebuilder:rbac:groups=infini.cloud,resources=notificationchannels,verbs=get;list;watch
+//+kubebuilder:rbac:groups=infini.cloud,resources=notificationchannels/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core.infini.cloud,resources=componentdefinitions,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=services;persistentvolumeclaims;configmaps;secrets;serviceaccounts,verbs=get;list;watch;create;update;patch;delete;deletecollection
 //+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets,verbs=get;list;watch;create;update;patch;delete;deletecollection
 //+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete;deletecollection
 //+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete;deletecollection
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors;podmonitors,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups="",resources=endpoints,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
 
-func (r *ApplicationDefinitionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *ApplicationDefinitionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	logger := log.FromContext(ctx)
 
 	// check if the request is for the correct namespace
@@ -106,15 +152,22 @@ func (r *ApplicationDefinitionReconciler) Reconcile(ctx context.Context, req ctr
 	logger.V(1).Info("Starting ApplicationDefinition reconciliation")
 
 	// 1. Initialize state and fetch the ApplicationDefinition
-	state := &reconcileState{
-		appDef:              &appv1.ApplicationDefinition{},
-		desiredObjects:      []client.Object{},
-		componentStatuses:   make(map[string]*appv1.ComponentStatusReference),
-		applyResults:        make(map[string]kubeutil.ApplyResult),
-		unmarshalledConfigs: make(map[string]interface{}), // Initialize map [Added]
-	}
-
-	if err := r.Client.Get(ctx, req.NamespacedName, state.appDef); err != nil {
+	state := newReconcileState(&appv1.ApplicationDefinition{})
+	ctx, span := startRequestSpan(ctx, "Reconcile", req.NamespacedName)
+	defer func() {
+		// Conflicts and progressing errors are requeued without an error, the first error is the outcome
+		outcome := err
+		if state.firstError != nil {
+			outcome = state.firstError
+		}
+		observeReconcile(startTime, outcome)
+		endSpan(span, outcome)
+	}()
+	defer r.trackTraceContext(ctx, req.NamespacedName)()
+
+	initCtx, initSpan := startRequestSpan(ctx, "Init", req.NamespacedName)
+	if err := r.Client.Get(initCtx, req.NamespacedName, state.appDef); err != nil {
+		endSpan(initSpan, client.IgnoreNotFound(err))
 		// Ignore not-found errors, since they can't be fixed by an immediate requeue.
 		// No need to change state if we don't find the object.
 		if client.IgnoreNotFound(err) != nil {
@@ -122,8 +175,11 @@ func (r *ApplicationDefinitionReconciler) Reconcile(ctx context.Context, req ctr
 			return ctrl.Result{}, err // Return error for retry
 		}
 		logger.Info("ApplicationDefinition resource not found, assuming deleted")
+		r.forgetSteadyState(req.NamespacedName)
 		return ctrl.Result{}, nil // Object is gone, stop reconciliation
 	}
+	span.SetAttributes(applicationAttributes(state.appDef)...)
+	initSpan.SetAttributes(applicationAttributes(state.appDef)...)
 
 	appDef := state.appDef
 	// Initialize Status if nil
@@ -134,10 +190,15 @@ func (r *ApplicationDefinitionReconciler) Reconcile(ctx context.Context, req ctr
 	// DeepCopy the status for comparison later
 	state.originalStatus = state.appDef.Status.DeepCopy()
 
+	// Status.Annotations used to hold config hashes and is superseded by Status.ConfigChecksums
+	state.appDef.Status.Annotations = nil
+
 	// Initialize component status map based on current spec
-	if err := r.initializeComponentStatuses(state); err != nil {
+	initErr := r.initializeComponentStatuses(state)
+	endSpan(initSpan, initErr)
+	if initErr != nil {
 		// Initialization error is critical, update status and stop
-		return r.handleReconcileError(ctx, state, "InitializationFailed", err)
+		return r.handleReconcileError(ctx, state, initErr)
 	}
 	logger.V(1).Info("Initialized component status map", "count", len(state.componentStatuses))
 
@@ -152,6 +213,7 @@ func (r *ApplicationDefinitionReconciler) Reconcile(ctx context.Context, req ctr
 		return ctrl.Result{}, err
 	}
 	if isDeleted {
+		r.forgetSteadyState(req.NamespacedName)
 		return ctrl.Result{}, nil
 	}
 
@@ -175,25 +237,81 @@ func (r *ApplicationDefinitionReconciler) Reconcile(ctx context.Context, req ctr
 	}
 	// If suspended but phase not yet set, continue to apply the suspend logic below
 
-	// 3.6. Fast path: Skip reconciliation if already Running and no spec changes
-	if state.appDef.Status.Phase == appv1.ApplicationPhaseRunning &&
+	// 3.55. Start tracking a new change, or a change ID reused for a new spec generation.
+	// The change is saved before it is announced, a conflicting status update must not announce it twice.
+	if recordChangeEvents := r.startChange(state.appDef); recordChangeEvents != nil {
+		if err := r.Client.Status().Update(ctx, state.appDef); err != nil {
+			return ctrl.Result{}, err
+		}
+		state.originalStatus.Changes = state.appDef.Status.DeepCopy().Changes
+		recordChangeEvents()
+	}
+
+	// 3.6. Fast path candidate: already Running and no spec changes.
+	// Applications without a change-id annotation are stable as well (both IDs are empty);
+	// their health keeps being followed by HealthMonitorReconciler.
+	isStable := state.appDef.Status.Phase == appv1.ApplicationPhaseRunning &&
 		state.appDef.Status.ObservedGeneration == state.appDef.Generation &&
-		state.appDef.Status.LastChangeID != "" &&
-		state.appDef.Annotations[appv1.AnnotationChangeID] == state.appDef.Status.LastChangeID {
-		// Application is stable and running, no spec changes detected
-		logger.V(1).Info("Application stable and running, skipping reconciliation")
-		return ctrl.Result{}, nil
+		state.appDef.Annotations[appv1.AnnotationChangeID] == state.appDef.Status.LastChangeID
+
+	// 3.7. Steady state: skip building the components when nothing was requested since the last reconcile.
+	// Referenced config changes and due drift scans go through the build and the fast path below.
+	if isStable && r.nothingRequested(state.appDef, startTime) {
+		logger.V(1).Info("Application stable and running, nothing requested")
+		return r.steadyStateResult(state.appDef, startTime), nil
 	}
+	// Config changes seen from now on are picked up by the next reconcile
+	r.configChanged.Delete(req.NamespacedName)
 
 	// 4. Process components: Unmarshal Config, Dispatch to Builder Strategy, Build Objects
 	processErr := r.processComponentsAndBuildObjects(ctx, state)
 	if processErr != nil {
 		// Building failed, update status and stop reconciliation for this cycle
 		state.firstError = processErr // Store the error
-		return r.handleReconcileError(ctx, state, "ProcessingFailed", processErr)
+		return r.handleReconcileError(ctx, state, processErr)
 	}
 	logger.V(1).Info("Object building successful", "objectCount", len(state.desiredObjects))
 
+	// 4.2. Dry-run mode: plan the changes with server-side dry-run and hold the apply until the plan is approved
+	if dryRunEnabled(state.appDef) {
+		proceed, planErr := r.handleDryRun(ctx, state)
+		if planErr != nil {
+			planErr = reconcileerr.Classify(planErr, reconcileerr.Apply, "PlanFailed")
+			state.firstError = planErr
+			return r.handleReconcileError(ctx, state, planErr)
+		}
+		if !proceed {
+			if _, err := r.updateStatusIfNeeded(ctx, state.appDef, state.originalStatus); err != nil {
+				return ctrl.Result{}, err
+			}
+			logger.V(1).Info("Changes planned, waiting for plan approval", "hash", state.appDef.Status.Plan.Hash)
+			return ctrl.Result{}, nil
+		}
+	} else if err := r.clearPlan(ctx, state); err != nil {
+		logger.Error(err, "Failed to clear plan")
+	}
+
+	// 4.5. Fast path: skip applying if stable and the consumed configuration did not change.
+	// Referenced ConfigMaps/Secrets can change without a spec change, so the checksums are compared.
+	// Out-of-band edits are not noticed otherwise, so a stable application is scanned for drift here.
+	if isStable && !configChecksumsChanged(state) {
+		correctDrift, driftErr := r.scanForDrift(ctx, state)
+		if driftErr != nil {
+			logger.Error(driftErr, "Drift scan failed")
+		} else {
+			r.driftScannedAt.Store(req.NamespacedName, startTime)
+		}
+		if !correctDrift {
+			if _, err := r.updateStatusIfNeeded(ctx, state.appDef, state.originalStatus); err != nil {
+				return ctrl.Result{}, err
+			}
+			logger.V(1).Info("Application stable and running, skipping reconciliation")
+			return driftScanResult(state.appDef), nil
+		}
+		logger.Info("Correcting drift by re-applying the desired state")
+		state.forceApply = true
+	}
+
 	// 5. Apply generated resources using Server-Side Apply
 	applyErr := r.applyResources(ctx, state)
 	if applyErr != nil && state.firstError == nil {
@@ -201,6 +319,10 @@ func (r *ApplicationDefinitionReconciler) Reconcile(ctx context.Context, req ctr
 		state.firstError = applyErr
 	}
 	// Note: Even if applyErr occurs, we continue to health checks to report current state.
+	if applyErr == nil {
+		recordConfigChecksums(state)
+		r.markDriftCorrected(state)
+	}
 
 	// 6. Check health and calculate overall status
 	var allReady bool
@@ -226,6 +348,16 @@ func (r *ApplicationDefinitionReconciler) Reconcile(ctx context.Context, req ctr
 		r.updateComponentStatusesForError(state, state.firstError)
 	}
 
+	// 6.5. Record restarts whose rollout has completed
+	if state.firstError == nil {
+		r.completeRestarts(ctx, state)
+		if len(state.pendingRestarts) > 0 {
+			// A restart is still rolling out, keep the app out of Running until it is honoured
+			allReady = false
+			needsRequeue = true
+		}
+	}
+
 	// 7. Determine final phase and update status if needed
 	r.determineFinalPhase(state, allReady)                                              // Update phase based on errors and readiness
 	state.appDef.Status.Components = mapToSliceComponentStatus(state.componentStatuses) // Update components status list
@@ -233,8 +365,8 @@ func (r *ApplicationDefinitionReconciler) Reconcile(ctx context.Context, req ctr
 	// Record reconciliation completion event BEFORE updating LastChangeID
 	// This ensures the webhook event is sent before the change ID is marked as processed
 	if state.firstError != nil {
-		r.recordEventf(state.appDef, "Reconcile", webrecorder.StatusFailure, "SyncComponent",
-			corev1.EventTypeWarning, "ReconcileFailed", "Reconciliation failed: %v", state.firstError)
+		r.recordEventf(state.appDef, "Reconcile", webhookStatusForError(state.firstError), "SyncComponent",
+			corev1.EventTypeWarning, reconcileerr.ReasonOf(state.firstError), "Reconciliation failed: %v", state.firstError)
 	} else if allReady {
 		r.recordEvent(state.appDef, "Reconcile", webrecorder.StatusSuccess, "SyncComponent",
 			corev1.EventTypeNormal, "ReconcileCompleted", "Reconciliation completed successfully, all components ready")
@@ -243,11 +375,14 @@ func (r *ApplicationDefinitionReconciler) Reconcile(ctx context.Context, req ctr
 			corev1.EventTypeNormal, "ReconcileProgressing", "Reconciliation in progress, waiting for components to be ready")
 	}
 
-	// Update LastChangeID in status if reconciliation was successful
+	// Update LastChangeID in status if reconciliation was successful, ending the change with its summary
 	if allReady && state.firstError == nil {
+		r.endChange(state.appDef, appv1.ChangeStateCompleted)
 		if changeID, exists := state.appDef.Annotations[appv1.AnnotationChangeID]; exists && changeID != "" {
 			state.appDef.Status.LastChangeID = changeID
 		}
+	} else if state.appDef.Status.Phase == appv1.ApplicationPhaseFailed {
+		r.endChange(state.appDef, appv1.ChangeStateFailed)
 	}
 
 	_, statusUpdateErr := r.updateStatusIfNeeded(ctx, state.appDef, state.originalStatus)
@@ -290,20 +425,18 @@ func (r *ApplicationDefinitionReconciler) Reconcile(ctx context.Context, req ctr
 		)
 	}
 
+	if state.firstError != nil {
+		// The type of the first error decides how (and whether) the reconcile is retried
+		return r.resultForError(state.firstError)
+	}
 	if needsRequeue {
-		// Use a default requeue interval, or adjust based on error type later
-		requeueInterval := 30 * time.Second
-		if apierrors.IsConflict(statusUpdateErr) {
-			requeueInterval = 5 * time.Second // Shorter retry for conflicts
-		}
-		logger.V(1).Info("Requeuing requested", "interval", requeueInterval.String())
-		// Return the first critical error encountered. If statusUpdateErr occurred, it might hide the root cause.
-		// Prioritizing the firstError seems reasonable.
-		return ctrl.Result{RequeueAfter: requeueInterval}, state.firstError
+		logger.V(1).Info("Requeuing requested", "interval", r.requeueInterval().String())
+		return ctrl.Result{RequeueAfter: r.requeueInterval()}, nil
 	}
-
-	// No requeue needed and no error occurred (or errors were handled and don't require immediate retry)
-	return ctrl.Result{}, state.firstError // Return firstError (might be nil)
+	if allReady {
+		return driftScanResult(state.appDef), nil // Come back for the next drift scan
+	}
+	return ctrl.Result{}, nil
 	// return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, state.firstError // Return firstError (might be nil)
 }
 
@@ -311,21 +444,28 @@ func (r *ApplicationDefinitionReconciler) Reconcile(ctx context.Context, req ctr
 
 // initializeComponentStatuses populates the initial status map.
 func (r *ApplicationDefinitionReconciler) initializeComponentStatuses(state *reconcileState) error {
+	// Carry over component conditions so their LastTransitionTime survives reconciles
+	previousConditions := make(map[string][]metav1.Condition)
+	for _, prev := range state.appDef.Status.Components {
+		previousConditions[prev.Name] = prev.Conditions
+	}
+
 	names := make(map[string]bool)
 	// Add statuses for current components in spec
 	for _, comp := range state.appDef.Spec.Components {
 		if comp.Name == "" {
-			return fmt.Errorf("component name cannot be empty in spec")
+			return reconcileerr.Validation("InvalidComponentName", fmt.Errorf("component name cannot be empty in spec"))
 		}
 		if names[comp.Name] {
-			return fmt.Errorf("duplicate component name found in spec: %s", comp.Name)
+			return reconcileerr.Validation("DuplicateComponentName", fmt.Errorf("duplicate component name found in spec: %s", comp.Name))
 		}
 		names[comp.Name] = true
 
 		state.componentStatuses[comp.Name] = &appv1.ComponentStatusReference{
-			Name:    comp.Name,
-			Health:  false, // Default to unhealthy
-			Message: "Initializing",
+			Name:       comp.Name,
+			Health:     false, // Default to unhealthy
+			Message:    "Initializing",
+			Conditions: previousConditions[comp.Name],
 		}
 	}
 
@@ -388,6 +528,8 @@ func (r *ApplicationDefinitionReconciler) handleEmptyApp(ctx context.Context, st
 
 // handleFinalizer adds or removes the finalizer.
 func (r *ApplicationDefinitionReconciler) handleFinalizer(ctx context.Context, state *reconcileState) (isDeleted bool, err error) {
+	ctx, span := startSpan(ctx, "Finalizer", state.appDef)
+	defer func() { endSpan(span, err) }()
 	logger := log.FromContext(ctx)
 	appDef := state.appDef
 
@@ -397,7 +539,7 @@ func (r *ApplicationDefinitionReconciler) handleFinalizer(ctx context.Context, s
 			logger.V(1).Info("Adding Finalizer")
 			controllerutil.AddFinalizer(appDef, appDefFinalizer)
 			// Retry loop for adding finalizer
-			for i := 0; i < 3; i++ {
+			for i := 0; i < r.finalizerRetries(); i++ {
 				if err := r.Client.Update(ctx, appDef); err != nil {
 					if apierrors.IsConflict(err) {
 						logger.V(1).Info("Conflict adding finalizer, retrying...", "attempt", i+1)
@@ -417,7 +559,7 @@ func (r *ApplicationDefinitionReconciler) handleFinalizer(ctx context.Context, s
 				return false, nil
 			}
 			// If we get here, all retries failed
-			return false, fmt.Errorf("failed to add finalizer after 3 attempts")
+			return false, fmt.Errorf("failed to add finalizer after %d attempts", r.finalizerRetries())
 		}
 	} else {
 		// Object IS being deleted
@@ -454,7 +596,7 @@ func (r *ApplicationDefinitionReconciler) handleFinalizer(ctx context.Context, s
 			logger.Info("Cleanup complete, removing Finalizer")
 			controllerutil.RemoveFinalizer(appDef, appDefFinalizer)
 			// Retry loop for removing finalizer
-			for i := 0; i < 3; i++ {
+			for i := 0; i < r.finalizerRetries(); i++ {
 				if err := r.Client.Update(ctx, appDef); err != nil {
 					if apierrors.IsConflict(err) {
 						logger.V(1).Info("Conflict removing finalizer, retrying...", "attempt", i+1)
@@ -496,7 +638,7 @@ func (r *ApplicationDefinitionReconciler) setInitialPhase(ctx context.Context, s
 
 		// 需要持久化更新到cr中，否则status一直不会更新，不会触发后续的apply
 		// Retry loop for status update
-		for i := 0; i < 3; i++ {
+		for i := 0; i < r.statusUpdateRetries(); i++ {
 			err := r.Client.Status().Update(ctx, state.appDef)
 			if err != nil {
 				if apierrors.IsConflict(err) {
@@ -525,109 +667,185 @@ func (r *ApplicationDefinitionReconciler) setInitialPhase(ctx context.Context, s
 			return true, nil // Signal that phase changed and might need status update + requeue
 		}
 		// If we get here, all retries failed
-		return false, fmt.Errorf("failed to update status in setInitialPhase after 3 attempts")
+		return false, fmt.Errorf("failed to update status in setInitialPhase after %d attempts", r.statusUpdateRetries())
 	}
 	return false, nil // Phase already set, no update needed now
 }
 
-// processComponentsAndBuildObjects iterates through components, gets strategies, builds objects.
+// componentBuild holds the outcome of building a single component.
+type componentBuild struct {
+	config     interface{}
+	objects    []client.Object
+	primary    client.Object
+	primaryGVK schema.GroupVersionKind
+	err        error // Classified with reconcileerr
+}
+
+// processComponentsAndBuildObjects builds the objects of all components with a bounded worker pool.
+// Results are merged in spec order, so the desired objects and the reported error are the same as for
+// a serial build: the error of the first failing component (by index) wins.
 func (r *ApplicationDefinitionReconciler) processComponentsAndBuildObjects(ctx context.Context, state *reconcileState) error {
 	logger := log.FromContext(ctx)
 	appDef := state.appDef
 	state.desiredObjects = []client.Object{} // Ensure clean slate for this cycle
 
 	for i := range appDef.Spec.Components {
-		appComp := appDef.Spec.Components[i] // Use index to get mutable reference if needed, but copy is safer
-		appComp.Type = "operator"
+		state.componentStatuses[appDef.Spec.Components[i].Name].Message = "Processing"
+	}
 
-		compLogger := logger.WithValues("component", appComp.Name, "componentType", appComp.Type)
-		compStatus := state.componentStatuses[appComp.Name] // Get status entry
+	builds := make([]componentBuild, len(appDef.Spec.Components))
+	runBounded(ctx, r.maxConcurrency(), len(builds), func(ctx context.Context, i int) {
+		builds[i] = r.buildComponent(ctx, appDef, i)
+	})
 
-		compStatus.Message = "Processing" // Update status message
+	for i := range appDef.Spec.Components {
+		appComp := &appDef.Spec.Components[i]
+		compLogger := logger.WithValues("component", appComp.Name, "componentType", operatorComponentType)
+		compStatus := state.componentStatuses[appComp.Name]
+		build := builds[i]
+
+		if build.err != nil {
+			compLogger.Error(build.err, "Component build failed")
+			reason := reconcileerr.ReasonOf(build.err)
+			r.recordComponentEventf(appDef, appComp.Name, nil, "BuildObjects", webhookStatusForError(build.err), "SyncComponent",
+				corev1.EventTypeWarning, reason, "%s", build.err.Error())
+			r.updateComponentStatusWithError(compStatus, reason, build.err.Error())
+			return build.err
+		}
 
-		compLogger.V(1).Info("Calling builder strategy BuildObjects")
+		state.unmarshalledConfigs[appComp.Name] = build.config // Store for later use
+		state.desiredObjects = append(state.desiredObjects, build.objects...)
 
-		// 1. Get Builder Strategy
-		builder, found := strategy.GetAppBuilderStrategy(appComp.Type)
-		if !found {
-			err := fmt.Errorf("no builder strategy registered for component type: %s", appComp.Type)
-			logger.Error(err, "Builder strategy not found")
-			r.recordEventf(appDef, "BuildObjects", webrecorder.StatusFailure, "SyncComponent",
-				corev1.EventTypeWarning, "BuilderStrategyNotFound", "%s", err.Error())
-			return err
+		// Record the component's primary workload among its built objects
+		if build.primary != nil {
+			compStatus.Kind = build.primaryGVK.Kind
+			compStatus.APIVersion = build.primaryGVK.GroupVersion().String()
+			compStatus.ResourceName = build.primary.GetName()
+			compStatus.Namespace = build.primary.GetNamespace()
+			compLogger.V(1).Info("Identified primary resource", "kind", build.primaryGVK.Kind, "name", build.primary.GetName())
+		} else {
+			compLogger.Info("No primary workload found among built objects")
 		}
 
-		// 2. Unmarshal Specific Config
-		config, err := commonutil.UnmarshalAppSpecificConfig(appComp.Type, appComp.Properties)
-		if err != nil {
-			err = fmt.Errorf("failed to unmarshal properties for component '%s': %w", appComp.Name, err)
-			logger.Error(err, "Config unmarshal failed")
-			return err
-		}
-		state.unmarshalledConfigs[appComp.Name] = config // Store for later use
+		compStatus.Message = "Built successfully" // Update status after successful build for this component
+		compLogger.V(1).Info("Component processed successfully", "builtObjectCount", len(build.objects))
+	}
 
-		// 3. Build Objects
-		objects, err := builder.BuildObjects(ctx, r.Client, r.Scheme, state.appDef, state.appDef, &appDef.Spec.Components[i], config)
-		if err != nil {
-			err = fmt.Errorf("builder strategy failed for component %s: %w", appComp.Name, err)
-			logger.Error(err, "Builder strategy failed", "error", err)
-			r.recordEventf(appDef, "BuildObjects", webrecorder.StatusFailure, "SyncComponent",
-				corev1.EventTypeWarning, "BuilderFailed", "%s", err.Error())
-			return err
-		}
+	// [Added] Handle Pause/Resume Logic
+	if err := r.handlePauseResume(ctx, state); err != nil {
+		return reconcileerr.Classify(fmt.Errorf("failed to handle pause/resume: %w", err), reconcileerr.Apply, "PauseResumeFailed")
+	}
 
-		// Process built objects
-		for _, obj := range objects {
-			if obj == nil {
-				compLogger.Info("Warning: Builder strategy returned a nil object, skipping")
-				continue
-			}
-			// Ensure metadata like Name and Namespace are set by builders
-			if obj.GetName() == "" || obj.GetNamespace() == "" {
-				gvkStr := "unknown GVK"
-				if gvk := obj.GetObjectKind().GroupVersionKind(); gvk.Kind != "" {
-					gvkStr = gvk.String()
-				}
-				err := fmt.Errorf("builder returned object of type %s without name or namespace for component %s", gvkStr, appComp.Name)
-				compLogger.Error(err, "Invalid built object")
-				r.updateComponentStatusWithError(compStatus, "InvalidBuiltObject", err.Error())
-				return err // Critical build error
-			}
-			// Apply standard labels
-			labels := obj.GetLabels()
-			if labels == nil {
-				labels = make(map[string]string)
-			}
-			labels[appNameLabel] = appDef.Name
-			labels[compNameLabel] = appComp.Type
-			labels[compInstanceLabel] = appComp.Name
-			labels[common.ManagedByLabel] = common.OperatorName
-			obj.SetLabels(labels)
+	// Handle declarative restart requests
+	if err := r.handleRestart(ctx, state); err != nil {
+		return reconcileerr.Classify(fmt.Errorf("failed to handle restart: %w", err), reconcileerr.Apply, "RestartFailed")
+	}
 
-			state.desiredObjects = append(state.desiredObjects, obj)
+	return nil // All components processed without critical error
+}
 
-			// Update status with primary resource details if matching GVK from CompDef
-			gvk := obj.GetObjectKind().GroupVersionKind()
-			if gvk.Kind == compStatus.Kind && gvk.GroupVersion().String() == compStatus.APIVersion {
-				compStatus.ResourceName = obj.GetName()
-				compStatus.Namespace = obj.GetNamespace()
-				compLogger.V(1).Info("Identified primary resource", "kind", gvk.Kind, "name", obj.GetName())
+// buildComponent gets the strategy for a component and builds its objects.
+// It runs concurrently with other components and therefore only reads the shared reconcile state.
+func (r *ApplicationDefinitionReconciler) buildComponent(ctx context.Context, appDef *appv1.ApplicationDefinition, index int) (build componentBuild) {
+	appComp := appDef.Spec.Components[index] // Copy, the type is overridden below
+	ctx, span := startSpan(ctx, "BuildComponent", appDef, attrComponent.String(appComp.Name))
+	defer func() { endSpan(span, build.err) }()
+	appComp.Type = operatorComponentType
+	compLogger := log.FromContext(ctx).WithValues("component", appComp.Name, "componentType", appComp.Type)
+	compLogger.V(1).Info("Calling builder strategy BuildObjects")
+
+	// 1. Get Builder Strategy
+	builder, found := strategy.GetAppBuilderStrategy(appComp.Type)
+	if !found {
+		err := fmt.Errorf("no builder strategy registered for component type: %s", appComp.Type)
+		return componentBuild{err: reconcileerr.Build("BuilderStrategyNotFound", err)}
+	}
+
+	// 2. Unmarshal Specific Config
+	config, err := commonutil.UnmarshalAppSpecificConfig(appComp.Type, appComp.Properties)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal properties for component '%s': %w", appComp.Name, err)
+		return componentBuild{err: reconcileerr.Validation("ConfigUnmarshalFailed", err)}
+	}
+
+	// 3. Build Objects
+	objects, err := builder.BuildObjects(ctx, r.Client, r.Scheme, appDef, appDef, &appDef.Spec.Components[index], config)
+	if err != nil {
+		err = fmt.Errorf("builder strategy failed for component %s: %w", appComp.Name, err)
+		// Objects or APIs the builder depends on may be unavailable for now (e.g. a referenced Secret), retry those
+		return componentBuild{err: classifyBuildError(err)}
+	}
+
+	// Process built objects
+	built := make([]client.Object, 0, len(objects))
+	for _, obj := range objects {
+		if obj == nil {
+			compLogger.Info("Warning: Builder strategy returned a nil object, skipping")
+			continue
+		}
+		// Ensure metadata like Name and Namespace are set by builders
+		if obj.GetName() == "" || obj.GetNamespace() == "" {
+			gvkStr := "unknown GVK"
+			if gvk := obj.GetObjectKind().GroupVersionKind(); gvk.Kind != "" {
+				gvkStr = gvk.String()
 			}
+			err := fmt.Errorf("builder returned object of type %s without name or namespace for component %s", gvkStr, appComp.Name)
+			return componentBuild{err: reconcileerr.Build("InvalidBuiltObject", err)} // Critical build error
 		}
+		// Apply standard labels
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[appNameLabel] = appDef.Name
+		labels[compNameLabel] = appComp.Type
+		labels[compInstanceLabel] = appComp.Name
+		labels[common.ManagedByLabel] = common.OperatorName
+		obj.SetLabels(labels)
 
-		compStatus.Message = "Built successfully" // Update status after successful build for this component
-		compLogger.V(1).Info("Component processed successfully", "builtObjectCount", len(objects))
+		built = append(built, obj)
 	}
 
-	// [Added] Handle Pause/Resume Logic
-	if err := r.handlePauseResume(ctx, state); err != nil {
-		return fmt.Errorf("failed to handle pause/resume: %w", err)
+	primary, gvk := r.findPrimaryWorkload(built, builder.GetWorkloadGVK())
+	return componentBuild{config: config, objects: built, primary: primary, primaryGVK: gvk}
+}
+
+// findPrimaryWorkload returns the built object matching the strategy's workload GVK, falling back to
+// the first Deployment, StatefulSet or DaemonSet when the strategy's GVK is not among the objects.
+func (r *ApplicationDefinitionReconciler) findPrimaryWorkload(objects []client.Object, workloadGVK schema.GroupVersionKind) (client.Object, schema.GroupVersionKind) {
+	var fallback client.Object
+	var fallbackGVK schema.GroupVersionKind
+	for _, obj := range objects {
+		if obj == nil {
+			continue
+		}
+		gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+		if err != nil {
+			continue
+		}
+		if gvk == workloadGVK {
+			return obj, gvk
+		}
+		if fallback == nil && gvk.Group == appsv1.GroupName &&
+			(gvk.Kind == "Deployment" || gvk.Kind == "StatefulSet" || gvk.Kind == "DaemonSet") {
+			fallback, fallbackGVK = obj, gvk
+		}
 	}
+	return fallback, fallbackGVK
+}
 
-	return nil // All components processed without critical error
+// objectApply holds the outcome of applying a single object.
+type objectApply struct {
+	result kubeutil.ApplyResult
+	// fatalErr stops the apply after the current wave (service lookup or owner reference failures)
+	fatalErr error
+	// ownerRefErr marks fatalErr as an owner reference failure, which is recorded as an apply result
+	ownerRefErr bool
 }
 
 // applyResources applies the generated objects using SSA.
+// Objects are applied in waves (see applyWave) with a bounded worker pool inside each wave.
+// Outcomes are merged in desired-object order, so the returned error is the first one a serial apply would have hit.
 func (r *ApplicationDefinitionReconciler) applyResources(ctx context.Context, state *reconcileState) error {
 	logger := log.FromContext(ctx)
 	appDef := state.appDef
@@ -635,81 +853,84 @@ func (r *ApplicationDefinitionReconciler) applyResources(ctx context.Context, st
 		logger.V(1).Info("No desired objects to apply.")
 		return nil
 	}
-	logger.V(1).Info("Applying generated resources", "count", len(state.desiredObjects))
+	logger.V(1).Info("Applying generated resources", "count", len(state.desiredObjects), "maxConcurrency", r.maxConcurrency())
 
 	var firstApplyErr error
 
-	for _, obj := range state.desiredObjects {
-		gvk := obj.GetObjectKind().GroupVersionKind()
-		objKey := client.ObjectKeyFromObject(obj)
-		resultMapKey := kubeutil.BuildObjectResultMapKey(obj)
-
-		// Before applying, check if the object is a Service and preserve its ClusterIP.
-		// This is the core fix for the "field is immutable" error.
-		if svc, ok := obj.(*corev1.Service); ok {
-			// Create a placeholder for the current service state in the cluster
-			currentSvc := &corev1.Service{}
-			// Try to get the service from the cluster
-			err := r.Client.Get(ctx, objKey, currentSvc)
-
-			if err != nil && !apierrors.IsNotFound(err) {
-				// If we failed to get the service for any reason other than it not existing,
-				// this is a real error. We should stop and requeue.
-				return fmt.Errorf("failed to get existing service %s: %w", objKey, err)
-			}
-
-			// If the service was found (err is nil)
-			if err == nil {
-				// Preserve the existing ClusterIP in our desired object.
-				// This prevents the apply operation from trying to change an immutable field.
-				svc.Spec.ClusterIP = currentSvc.Spec.ClusterIP
-			}
-			// If the service was not found (apierrors.IsNotFound(err) was true),
-			// we do nothing. The 'svc' object with an empty ClusterIP will be used to create a new service,
-			// and Kubernetes will assign a new IP, which is the correct behavior.
-		}
+	for _, wave := range groupIntoApplyWaves(state.desiredObjects) {
+		outcomes := make([]objectApply, len(wave))
+		runBounded(ctx, r.maxConcurrency(), len(wave), func(ctx context.Context, i int) {
+			outcomes[i] = r.applyObject(ctx, appDef, state.desiredObjects[wave[i]], r.ForceOwnership || state.forceApply)
+		})
 
-		// Set Owner Reference before applying
-		if err := controllerutil.SetControllerReference(appDef, obj, r.Scheme); err != nil {
-			// This is critical, log and potentially stop/return error
-			errMsg := fmt.Sprintf("Failed to set OwnerReference on %s %s: %v", gvk.Kind, objKey.String(), err)
-			logger.Error(err, errMsg)
-			r.recordEventf(appDef, "ApplyResources", webrecorder.StatusFailure, "SyncComponent",
-				corev1.EventTypeWarning, "SetOwnerRefFailed", "%s", errMsg)
-			if firstApplyErr == nil {
-				firstApplyErr = fmt.Errorf("%s", errMsg) // Wrap error
+		var fatalErr error
+		for i, idx := range wave {
+			obj := state.desiredObjects[idx]
+			outcome := outcomes[i]
+			gvk := obj.GetObjectKind().GroupVersionKind()
+			objKey := client.ObjectKeyFromObject(obj)
+			resultMapKey := kubeutil.BuildObjectResultMapKey(obj)
+
+			if outcome.fatalErr != nil {
+				countApply(gvk.Kind, controllerutil.OperationResultNone, false, outcome.fatalErr)
+				if outcome.ownerRefErr {
+					logger.Error(outcome.fatalErr, outcome.fatalErr.Error())
+					r.recordEventf(appDef, "ApplyResources", webhookStatusForError(outcome.fatalErr), "SyncComponent",
+						corev1.EventTypeWarning, reconcileerr.ReasonOf(outcome.fatalErr), "%s", outcome.fatalErr.Error())
+					if firstApplyErr == nil {
+						firstApplyErr = outcome.fatalErr
+					}
+					state.applyResults[resultMapKey] = kubeutil.ApplyResult{Error: firstApplyErr}
+					r.updateComponentStatusForApplyError(state.componentStatuses, obj, firstApplyErr)
+				}
+				if fatalErr == nil {
+					fatalErr = outcome.fatalErr
+				}
+				continue
 			}
-			state.applyResults[resultMapKey] = kubeutil.ApplyResult{Error: firstApplyErr}
-			r.updateComponentStatusForApplyError(state.componentStatuses, obj, firstApplyErr)
-			return firstApplyErr // Stop applying on owner ref failure
-		}
 
-		// Apply using Server-Side Apply utility
-		applyResult := kubeutil.ApplyObjectV2(ctx, r.Reconciler, obj, common.OperatorName) // Use constant for field manager
-		state.applyResults[resultMapKey] = applyResult
-
-		if applyResult.Error != nil {
-			if apierrors.IsConflict(applyResult.Error) {
-				logger.Info("Optimistic lock conflict detected, will requeue and retry.", "resource", objKey, "kind", gvk.Kind)
-				if firstApplyErr == nil {
-					firstApplyErr = applyResult.Error
-				}
-			} else {
-				errMsg := fmt.Sprintf("Failed to apply resource %s %s: %v", gvk.Kind, objKey.String(), applyResult.Error)
-				logger.Error(applyResult.Error, errMsg)
-				r.recordEventf(appDef, "ApplyResources", webrecorder.StatusFailure, "SyncComponent",
-					corev1.EventTypeWarning, "ResourceApplyFailed", "%s", errMsg)
-				if firstApplyErr == nil {
-					firstApplyErr = applyResult.Error
+			applyResult := outcome.result
+			state.applyResults[resultMapKey] = applyResult
+			countApply(gvk.Kind, applyResult.Operation, len(applyResult.Conflicts) > 0, applyResult.Error)
+
+			if applyResult.Error != nil {
+				if len(applyResult.Conflicts) > 0 {
+					errMsg := fmt.Sprintf("Apply of %s %s conflicts with other field managers: %s",
+						gvk.Kind, objKey.String(), describeFieldConflicts(applyResult.Conflicts))
+					logger.Info(errMsg)
+					// Retried with backoff, the other manager may let go of the fields; force-ownership takes them over
+					conflictErr := reconcileerr.New(reconcileerr.KindApply, reasonFieldManagerConflict, true, applyResult.Error)
+					r.recordEventf(appDef, "ApplyResources", webhookStatusForError(conflictErr), "SyncComponent",
+						corev1.EventTypeWarning, conflictErr.Reason, "%s", errMsg)
+					if firstApplyErr == nil {
+						firstApplyErr = conflictErr
+					}
+					r.updateComponentStatusForConflicts(state.componentStatuses, obj, applyResult.Conflicts)
+				} else if apierrors.IsConflict(applyResult.Error) {
+					logger.Info("Optimistic lock conflict detected, will requeue and retry.", "resource", objKey, "kind", gvk.Kind)
+					if firstApplyErr == nil {
+						firstApplyErr = reconcileerr.Apply("OptimisticLockConflict", applyResult.Error)
+					}
+				} else {
+					applyErr := reconcileerr.Apply("ResourceApplyFailed", applyResult.Error)
+					errMsg := fmt.Sprintf("Failed to apply resource %s %s: %v", gvk.Kind, objKey.String(), applyResult.Error)
+					logger.Error(applyResult.Error, errMsg)
+					r.recordEventf(appDef, "ApplyResources", webhookStatusForError(applyErr), "SyncComponent",
+						corev1.EventTypeWarning, applyErr.Reason, "%s", errMsg)
+					if firstApplyErr == nil {
+						firstApplyErr = applyErr
+					}
+					r.updateComponentStatusForApplyError(state.componentStatuses, obj, applyErr)
 				}
-				r.updateComponentStatusForApplyError(state.componentStatuses, obj, applyResult.Error)
+				continue
 			}
-		} else {
+
 			logger.V(1).Info("Successfully applied resource", "kind", gvk.Kind, "name", objKey.String(), "operation", applyResult.Operation)
 
 			// Record webhook event for resource creation/update
 			// Use "Sync" prefix for step names to match SyncComponent convention
 			var eventMessage, eventReason, stepName string
+			recordChangedObject(appDef, applyResult.Operation, gvk.Kind+"/"+objKey.Name)
 			switch applyResult.Operation {
 			case controllerutil.OperationResultCreated:
 				eventMessage = fmt.Sprintf("Created %s: %s", gvk.Kind, objKey.Name)
@@ -728,10 +949,15 @@ func (r *ApplicationDefinitionReconciler) applyResources(ctx context.Context, st
 			// Only send webhook events for actual creates/updates
 			if stepName != "" {
 				logger.V(1).Info("Sending resource event", "kind", gvk.Kind, "name", objKey.Name, "operation", applyResult.Operation, "step", stepName)
-				r.recordEvent(appDef, "ApplyResources", webrecorder.StatusSuccess, stepName,
-					corev1.EventTypeNormal, eventReason, eventMessage)
+				r.recordComponentEventf(appDef, obj.GetLabels()[compInstanceLabel], resourceChange(applyResult.Previous, obj),
+					"ApplyResources", webrecorder.StatusSuccess, stepName, corev1.EventTypeNormal, eventReason, "%s", eventMessage)
 			}
 		}
+
+		if fatalErr != nil {
+			// Later waves depend on this one, stop here
+			return fatalErr
+		}
 	}
 
 	updateComponentStatusesFromApplyResults(state.componentStatuses, state.desiredObjects, state.applyResults)
@@ -739,6 +965,69 @@ func (r *ApplicationDefinitionReconciler) applyResources(ctx context.Context, st
 	return firstApplyErr
 }
 
+// applyObject prepares and applies a single object. It runs concurrently with the other objects of
+// its wave, so it must not touch the reconcile state; the caller records results and events.
+func (r *ApplicationDefinitionReconciler) applyObject(ctx context.Context, appDef *appv1.ApplicationDefinition, obj client.Object, forceOwnership bool) (outcome objectApply) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	objKey := client.ObjectKeyFromObject(obj)
+	ctx, span := startSpan(ctx, "ApplyObject", appDef, attrComponent.String(obj.GetLabels()[compInstanceLabel]),
+		attrObjectKind.String(gvk.Kind), attrObjectName.String(objKey.Name))
+	defer func() {
+		span.SetAttributes(attrOperation.String(string(outcome.result.Operation)))
+		if outcome.fatalErr != nil {
+			endSpan(span, outcome.fatalErr)
+			return
+		}
+		endSpan(span, outcome.result.Error)
+	}()
+
+	// Before applying, check if the object is a Service and preserve its ClusterIP.
+	// This is the core fix for the "field is immutable" error.
+	if svc, ok := obj.(*corev1.Service); ok {
+		// Create a placeholder for the current service state in the cluster
+		currentSvc := &corev1.Service{}
+		// Try to get the service from the cluster
+		err := r.Client.Get(ctx, objKey, currentSvc)
+
+		if err != nil && !apierrors.IsNotFound(err) {
+			// If we failed to get the service for any reason other than it not existing,
+			// this is a real error. We should stop and requeue.
+			err = fmt.Errorf("failed to get existing service %s: %w", objKey, err)
+			return objectApply{fatalErr: reconcileerr.Dependency("ServiceLookupFailed", err)}
+		}
+
+		// If the service was found (err is nil)
+		if err == nil {
+			// Preserve the existing ClusterIP in our desired object.
+			// This prevents the apply operation from trying to change an immutable field.
+			svc.Spec.ClusterIP = currentSvc.Spec.ClusterIP
+		}
+		// If the service was not found (apierrors.IsNotFound(err) was true),
+		// we do nothing. The 'svc' object with an empty ClusterIP will be used to create a new service,
+		// and Kubernetes will assign a new IP, which is the correct behavior.
+	}
+
+	// Set Owner Reference before applying
+	if err := controllerutil.SetControllerReference(appDef, obj, r.Scheme); err != nil {
+		err = fmt.Errorf("failed to set OwnerReference on %s %s: %w", gvk.Kind, objKey.String(), err)
+		return objectApply{fatalErr: reconcileerr.Validation("SetOwnerRefFailed", err), ownerRefErr: true}
+	}
+
+	// Apply using Server-Side Apply with the operator as field manager
+	// Fields written with Create/Update, by an older operator or the resource reconciler below, are
+	// migrated to the apply field manager first so they do not conflict with it
+	result := kubeutil.ApplyObject(ctx, r.Client, obj, common.OperatorName, kubeutil.WithForceOwnership(forceOwnership),
+		kubeutil.WithUpgradeManagers(legacyFieldManager, common.OperatorName))
+	if kubeutil.IsImmutableFieldError(result.Error) && r.Reconciler != nil {
+		// SSA cannot change immutable fields; the resource reconciler can recreate the object instead
+		log.FromContext(ctx).Info("Immutable field change, falling back to resource reconciler", "kind", gvk.Kind, "name", objKey.String())
+		previous := result.Previous
+		result = kubeutil.ApplyObjectV2(ctx, r.Reconciler, obj, common.OperatorName)
+		result.Previous = previous
+	}
+	return objectApply{result: result}
+}
+
 // checkHealthAndCalculateStatus checks K8s and Application level health. [Modified]
 func (r *ApplicationDefinitionReconciler) checkHealthAndCalculateStatus(ctx context.Context, state *reconcileState) (allComponentsReady bool, needsRequeue bool, firstCheckErr error) {
 	logger := log.FromContext(ctx)
@@ -749,14 +1038,10 @@ func (r *ApplicationDefinitionReconciler) checkHealthAndCalculateStatus(ctx cont
 	appDef := state.appDef // For convenience
 
 	for compName, compStatus := range state.componentStatuses {
-		if len(appDef.Spec.Components) == 0 {
-			continue
+		// Primary workload (Kind/APIVersion/ResourceName) was identified per component while building
+		if compStatus.Namespace == "" {
+			compStatus.Namespace = appDef.Namespace
 		}
-		spec := appDef.Spec.Components[0]
-		compStatus.Namespace = appDef.Namespace
-		compStatus.ResourceName = compName
-		compStatus.APIVersion = spec.APIVersion
-		compStatus.Kind = spec.Kind
 		compLogger := logger.WithValues("component", compName, "kind", compStatus.Kind, "resourceName", compStatus.ResourceName)
 
 		// Find the corresponding component spec (needed for app health check)
@@ -771,21 +1056,14 @@ func (r *ApplicationDefinitionReconciler) checkHealthAndCalculateStatus(ctx cont
 			compLogger.Error(nil, "Internal error: Cannot find component spec in AppDef corresponding to status entry")
 			allComponentsReady = false
 			if firstCheckErr == nil {
-				firstCheckErr = fmt.Errorf("component spec not found for %s", compName)
+				firstCheckErr = reconcileerr.Health("ComponentSpecNotFound", fmt.Errorf("component spec not found for %s", compName))
 			}
 			continue // Skip health check for this inconsistent entry
 		}
 
 		// Prerequisite checks for health checking
 		isInfoMissing := compStatus.ResourceName == "" || compStatus.Kind == "" || compStatus.APIVersion == ""
-		isPreviousError := strings.Contains(compStatus.Message, "Error:") || // Generic error marker
-			strings.Contains(compStatus.Message, "Failed") || // Common failure keyword
-			compStatus.Message == "BuilderStrategyNotFound" || // Specific error messages
-			compStatus.Message == "CompDefNotFound" ||
-			compStatus.Message == "InvalidCompDefSpec" ||
-			compStatus.Message == "ConfigUnmarshalFailed" ||
-			compStatus.Message == "BuildObjectsFailed" ||
-			compStatus.Message == "InvalidBuiltObject"
+		isPreviousError := compStatus.Reason != "" // A build or apply error was recorded in this reconcile
 
 		if isInfoMissing || isPreviousError {
 			// If resource info is missing or a critical build/apply error occurred, mark as not ready.
@@ -798,17 +1076,33 @@ func (r *ApplicationDefinitionReconciler) checkHealthAndCalculateStatus(ctx cont
 			continue // Skip actual health checks
 		}
 
-		// --- 1. Check K8s Resource Health ---
-		k8sHealthy, k8sMessage, k8sCheckErr := kubeutil.CheckHealth(ctx, r.Client, r.Scheme, compStatus.Namespace, compStatus.ResourceName, compStatus.APIVersion, compStatus.Kind)
+		// --- 1. Run the registered reconcile strategy (task list + app-level health) ---
+		if reconcileStrategy, found := strategy.GetAppReconcileStrategy(operatorComponentType); found {
+			healthy, requeue, checkErr := r.checkComponentHealthWithStrategy(ctx, state, reconcileStrategy, appComp, compStatus)
+			if checkErr != nil && firstCheckErr == nil {
+				firstCheckErr = checkErr
+			}
+			if !healthy {
+				allComponentsReady = false
+			}
+			needsRequeue = needsRequeue || requeue
+			continue
+		}
+
+		// --- 2. Fallback: Check K8s Resource Health only ---
+		compLogger.V(1).Info("No reconcile strategy registered, checking K8s resource health only")
+		healthCtx, healthSpan := startSpan(ctx, "HealthCheck", appDef, attrComponent.String(compName))
+		k8sHealthy, k8sMessage, k8sCheckErr := kubeutil.CheckHealth(healthCtx, r.Client, r.Scheme, compStatus.Namespace, compStatus.ResourceName, compStatus.APIVersion, compStatus.Kind)
+		healthSpan.SetAttributes(attrHealthy.Bool(k8sHealthy))
+		endSpan(healthSpan, k8sCheckErr)
 		if k8sCheckErr != nil {
 			// Error during the K8s health check process itself
 			compLogger.Error(k8sCheckErr, "Failed to execute K8s resource health check process")
-			compStatus.Health = false
-			compStatus.Message = fmt.Sprintf("K8sHealthCheckError: %v", k8sCheckErr)
+			r.updateComponentStatusWithError(compStatus, "K8sHealthCheckError", k8sCheckErr.Error())
 			allComponentsReady = false
 			needsRequeue = true // Requeue needed to retry the check
 			if firstCheckErr == nil {
-				firstCheckErr = k8sCheckErr
+				firstCheckErr = reconcileerr.Health("K8sHealthCheckError", k8sCheckErr)
 			}
 			continue // Skip further checks for this component
 		}
@@ -820,19 +1114,91 @@ func (r *ApplicationDefinitionReconciler) checkHealthAndCalculateStatus(ctx cont
 			allComponentsReady = false
 			needsRequeue = true // Requeue needed as resource is not ready yet
 			compLogger.V(1).Info("K8s resource health check failed", "reason", k8sMessage)
-			continue // Skip app-level check if K8s level isn't healthy
+			continue
 		}
 
-		// --- 2. Check Application-Level Health (if K8s resource is healthy) ---
-		compLogger.V(1).Info("K8s resource is healthy, proceeding to application-level health check")
-
 		// Mark this component as healthy
 		compStatus.Health = true
 		compStatus.Message = "Component is ready and healthy"
 		compLogger.V(1).Info("Component health check passed")
 	}
 
-	return allComponentsReady, needsRequeue, firstCheckErr
+	// Per-pod detail and crash diagnosis, so "not ready" comes with the reason
+	if err := refreshPodStatuses(ctx, r.Client, appDef, state.componentStatuses); err != nil {
+		logger.Error(err, "Failed to collect pod statuses")
+	}
+
+	// Aggregate per-component health according to the application's health policy.
+	// Unhealthy components still request a requeue so their status keeps being refreshed.
+	policyReady, summary := aggregateComponentHealth(appDef, state.componentStatuses)
+	state.healthSummary = summary
+	logger.V(1).Info("Aggregated component health", "allComponentsReady", allComponentsReady, "policyReady", policyReady, "summary", summary)
+
+	return policyReady, needsRequeue, firstCheckErr
+}
+
+// checkComponentHealthWithStrategy runs the strategy's task list (which includes the K8s readiness check)
+// through the TaskRunner and, once the tasks are complete and the workload is ready, the application-level
+// health check. The component status is updated in place.
+func (r *ApplicationDefinitionReconciler) checkComponentHealthWithStrategy(ctx context.Context, state *reconcileState,
+	reconcileStrategy strategy.AppReconcileStrategy, appComp *appv1.ApplicationComponent,
+	compStatus *appv1.ComponentStatusReference) (healthy bool, needsRequeue bool, checkErr error) {
+	ctx, span := startSpan(ctx, "HealthCheck", state.appDef, attrComponent.String(appComp.Name))
+	defer func() {
+		span.SetAttributes(attrHealthy.Bool(healthy))
+		endSpan(span, checkErr)
+	}()
+	compLogger := log.FromContext(ctx).WithValues("component", appComp.Name)
+	config := state.unmarshalledConfigs[appComp.Name]
+
+	// Only hand the strategy the objects belonging to this component
+	var compObjects []client.Object
+	for _, obj := range state.desiredObjects {
+		if obj.GetLabels()[compInstanceLabel] == appComp.Name {
+			compObjects = append(compObjects, obj)
+		}
+	}
+
+	requeue, err := reconcileStrategy.Reconcile(ctx, r.Client, r.Scheme, state.appDef, appComp, compStatus,
+		config, compObjects, state.applyResults, r.Recorder)
+	if err != nil {
+		compLogger.Error(err, "Reconcile strategy tasks failed")
+		r.updateComponentStatusWithError(compStatus, "ReconcileTaskFailed", err.Error())
+		setAppHealthyCondition(compStatus, metav1.ConditionUnknown, "WorkloadNotReady", "Application health not evaluated: workload check failed")
+		return false, true, reconcileerr.Classify(fmt.Errorf("health check tasks failed for component %s: %w", appComp.Name, err),
+			reconcileerr.Health, "ReconcileTaskFailed")
+	}
+	if requeue || !compStatus.Health {
+		// A task is pending (e.g. workload rollout) or the workload is not ready; the task set the message
+		compStatus.Health = false
+		setAppHealthyCondition(compStatus, metav1.ConditionUnknown, "WorkloadNotReady", "Waiting for the workload to become ready")
+		compLogger.V(1).Info("Component tasks pending or workload not ready", "message", compStatus.Message)
+		return false, true, nil
+	}
+
+	// --- Application-level health (K8s workload is ready at this point) ---
+	compLogger.V(1).Info("K8s resource is healthy, proceeding to application-level health check")
+	appHealthy, appMessage, appErr := reconcileStrategy.CheckAppHealth(ctx, r.Client, r.Scheme, state.appDef, appComp, config)
+	if appErr != nil {
+		// The check itself failed (e.g. endpoints could not be read); report unhealthy and retry
+		compLogger.Error(appErr, "Application health check could not be executed")
+		r.updateComponentStatusWithError(compStatus, "AppHealthCheckError", appMessage)
+		setAppHealthyCondition(compStatus, metav1.ConditionFalse, "AppHealthCheckError", appMessage)
+		return false, true, nil
+	}
+	if !appHealthy {
+		compStatus.Health = false
+		compStatus.Message = appMessage
+		setAppHealthyCondition(compStatus, metav1.ConditionFalse, "AppHealthCheckFailed", appMessage)
+		compLogger.V(1).Info("Application health check failed", "reason", appMessage)
+		return false, true, nil
+	}
+
+	compStatus.Health = true
+	compStatus.Message = "Component is ready and healthy"
+	setAppHealthyCondition(compStatus, metav1.ConditionTrue, "AppHealthCheckPassed", appMessage)
+	compLogger.V(1).Info("Component health check passed", "appHealth", appMessage)
+	return true, false, nil
 }
 
 // determineFinalPhase sets the overall AppDef phase based on errors and readiness.
@@ -846,44 +1212,40 @@ func (r *ApplicationDefinitionReconciler) determineFinalPhase(state *reconcileSt
 	}
 
 	if state.firstError != nil {
-		// If any critical error occurred during the reconcile cycle
-		state.appDef.Status.Phase = appv1.ApplicationPhaseFailed
-		reason := "ReconcileFailed"
-		errMsg := state.firstError.Error()
-		// Try to determine a more specific reason based on common error patterns
-		if strings.Contains(errMsg, "apply") || strings.Contains(errMsg, "Apply") || strings.Contains(errMsg, "OwnerRef") {
-			reason = "ApplyFailed"
-		} else if strings.Contains(errMsg, "build") || strings.Contains(errMsg, "Build") || strings.Contains(errMsg, "Config") || strings.Contains(errMsg, "CompDef") || strings.Contains(errMsg, "strategy") || strings.Contains(errMsg, "Strategy") {
-			reason = "ProcessingFailed"
-		} else if strings.Contains(errMsg, "HealthCheck") || strings.Contains(errMsg, "health check") {
-			reason = "HealthCheckFailed" // Covers both K8s and App health check process errors
-		}
-		setCondition(state.appDef, metav1.Condition{Type: string(appv1.ConditionReady), Status: metav1.ConditionFalse, Reason: reason, Message: errMsg})
+		setErrorPhase(state.appDef, state.firstError)
 		return
 	}
 
 	// No critical errors encountered in this cycle
 	if allComponentsReady {
 		state.appDef.Status.Phase = appv1.ApplicationPhaseRunning
-		setCondition(state.appDef, metav1.Condition{Type: string(appv1.ConditionReady), Status: metav1.ConditionTrue, Reason: "ComponentsReady", Message: "All components reconciled and healthy"})
+		message := "All components reconciled and healthy"
+		if state.healthSummary != "" {
+			message = fmt.Sprintf("Components reconciled and healthy: %s", state.healthSummary)
+		}
+		setCondition(state.appDef, metav1.Condition{Type: string(appv1.ConditionReady), Status: metav1.ConditionTrue, Reason: "ComponentsReady", Message: message})
 	} else {
 		// No errors, but not all components are ready/healthy yet
 		var reason, message string
 
 		// Determine if it's creating, updating, or degraded
-		if currentPhase == appv1.ApplicationPhaseRunning || currentPhase == appv1.ApplicationPhaseDegraded {
-			// Was previously Running or Degraded, now not ready -> Degraded
-			state.appDef.Status.Phase = appv1.ApplicationPhaseDegraded
+		state.appDef.Status.Phase = progressingPhase(currentPhase)
+		restarting := len(state.pendingRestarts) > 0 && state.appDef.Status.Phase != appv1.ApplicationPhaseCreating
+		if restarting {
+			// The pods roll because the user asked for it, the application is not degraded
+			state.appDef.Status.Phase = appv1.ApplicationPhaseUpdateing
+		}
+		switch {
+		case restarting:
+			reason = "ComponentsRestarting"
+			message = "Waiting for the requested rolling restart to complete"
+		case state.appDef.Status.Phase == appv1.ApplicationPhaseDegraded:
 			reason = "ComponentsDegraded"
 			message = "One or more previously ready components are now unhealthy or not ready"
-		} else if currentPhase == appv1.ApplicationPhaseCreating {
-			// Keep Creating phase until components are ready for the first time
-			state.appDef.Status.Phase = appv1.ApplicationPhaseCreating
+		case state.appDef.Status.Phase == appv1.ApplicationPhaseCreating:
 			reason = "ComponentsCreating"
 			message = "Waiting for components to become ready for the first time"
-		} else {
-			// Previously in Updating, Failed, or other transitional state
-			state.appDef.Status.Phase = appv1.ApplicationPhaseUpdateing
+		default:
 			reason = "ComponentsApplying"
 			message = "Waiting for components to become ready and healthy"
 		}
@@ -892,12 +1254,14 @@ func (r *ApplicationDefinitionReconciler) determineFinalPhase(state *reconcileSt
 }
 
 // handleReconcileError updates status for critical errors and returns.
-func (r *ApplicationDefinitionReconciler) handleReconcileError(ctx context.Context, state *reconcileState, reason string, err error) (ctrl.Result, error) {
+func (r *ApplicationDefinitionReconciler) handleReconcileError(ctx context.Context, state *reconcileState, err error) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
-	logger.Error(err, "Reconciliation failed critically", "reason", reason)
+	logger.Error(err, "Reconciliation failed critically", "reason", reconcileerr.ReasonOf(err), "retryable", reconcileerr.IsRetryable(err))
 
-	state.appDef.Status.Phase = appv1.ApplicationPhaseFailed
-	setCondition(state.appDef, metav1.Condition{Type: string(appv1.ConditionReady), Status: metav1.ConditionFalse, Reason: reason, Message: err.Error()})
+	setErrorPhase(state.appDef, err)
+	if state.appDef.Status.Phase == appv1.ApplicationPhaseFailed {
+		r.endChange(state.appDef, appv1.ChangeStateFailed)
+	}
 
 	// Update individual component statuses to reflect the failure if possible
 	r.updateComponentStatusesForError(state, err)
@@ -915,11 +1279,11 @@ func (r *ApplicationDefinitionReconciler) handleReconcileError(ctx context.Conte
 		state.firstError = err
 	}
 	// Return the original critical error to controller-runtime for potential backoff
-	return ctrl.Result{}, state.firstError
+	return r.resultForError(state.firstError)
 }
 
 // updateStatusIfNeeded compares current and original status and updates if necessary.
-func (r *ApplicationDefinitionReconciler) updateStatusIfNeeded(ctx context.Context, currentApp *appv1.ApplicationDefinition, originalStatus *appv1.ApplicationDefinitionStatus) (bool, error) {
+func (r *ApplicationDefinitionReconciler) updateStatusIfNeeded(ctx context.Context, currentApp *appv1.ApplicationDefinition, originalStatus *appv1.ApplicationDefinitionStatus) (updated bool, err error) {
 	logger := log.FromContext(ctx)
 	// Ensure Generation matches ObservedGeneration after successful reconcile
 	currentApp.Status.ObservedGeneration = currentApp.Generation
@@ -929,18 +1293,25 @@ func (r *ApplicationDefinitionReconciler) updateStatusIfNeeded(ctx context.Conte
 		conditionsEqual(currentApp.Status.Conditions, originalStatus.Conditions) &&
 		componentStatusesEqual(currentApp.Status.Components, originalStatus.Components) &&
 		currentApp.Status.ObservedGeneration == originalStatus.ObservedGeneration &&
-		currentApp.Status.LastChangeID == originalStatus.LastChangeID {
+		currentApp.Status.LastChangeID == originalStatus.LastChangeID &&
+		equality.Semantic.DeepEqual(currentApp.Status.Changes, originalStatus.Changes) &&
+		maps.Equal(currentApp.Status.RestartedAt, originalStatus.RestartedAt) &&
+		maps.Equal(currentApp.Status.ConfigChecksums, originalStatus.ConfigChecksums) &&
+		maps.Equal(currentApp.Status.Annotations, originalStatus.Annotations) &&
+		equality.Semantic.DeepEqual(currentApp.Status.Plan, originalStatus.Plan) {
 		logger.V(1).Info("Status unchanged, skipping update.")
 		return false, nil // No changes detected
 	}
 
 	// Status has changed, attempt update with retry
 	logger.V(1).Info("Status has changed, attempting update.", "newPhase", currentApp.Status.Phase)
+	ctx, span := startSpan(ctx, "UpdateStatus", currentApp)
+	defer func() { endSpan(span, err) }()
 
 	// Create a copy of the desired status to reapply if needed
 	desiredStatus := currentApp.Status.DeepCopy()
 
-	for i := 0; i < 3; i++ {
+	for i := 0; i < r.statusUpdateRetries(); i++ {
 		if err := r.Client.Status().Update(ctx, currentApp); err != nil {
 			if apierrors.IsConflict(err) {
 				logger.V(1).Info("Status update conflict, retrying...", "attempt", i+1)
@@ -949,9 +1320,9 @@ func (r *ApplicationDefinitionReconciler) updateStatusIfNeeded(ctx context.Conte
 					logger.Error(fetchErr, "Failed to re-fetch appDef after status update conflict")
 					return false, fetchErr
 				}
-				// Reapply the desired status changes since currentApp was re-fetched
-				currentApp.Status = *desiredStatus.DeepCopy()
-				// Update ObservedGeneration again since we're overwriting the status
+				// Reapply the changes of this reconcile on top of the status written meanwhile,
+				// e.g. the webhook delivery status or a phase set by the health monitor
+				mergeOwnedStatus(&currentApp.Status, desiredStatus, originalStatus)
 				currentApp.Status.ObservedGeneration = currentApp.Generation
 				continue
 			}
@@ -962,7 +1333,40 @@ func (r *ApplicationDefinitionReconciler) updateStatusIfNeeded(ctx context.Conte
 		return true, nil // Status updated successfully
 	}
 	// If we get here, all retries failed
-	return false, fmt.Errorf("failed to update status after 3 attempts")
+	return false, fmt.Errorf("failed to update status after %d attempts", r.statusUpdateRetries())
+}
+
+// mergeOwnedStatus applies desired, the status computed by a reconcile that started from original, to
+// latest, the status re-read after an update conflict. The fields only the reconciler writes are copied.
+// The phase, conditions and components, also written by the health monitor, are copied only where the
+// reconcile changed them. Fields of other writers, such as status.webhook, are kept.
+func mergeOwnedStatus(latest, desired, original *appv1.ApplicationDefinitionStatus) {
+	desired = desired.DeepCopy()
+	latest.LastChangeID = desired.LastChangeID
+	latest.Changes = desired.Changes
+	latest.SuspendedReplicas = desired.SuspendedReplicas
+	latest.RestartedAt = desired.RestartedAt
+	latest.ConfigChecksums = desired.ConfigChecksums
+	latest.Annotations = desired.Annotations
+	latest.Plan = desired.Plan
+
+	if desired.Phase != original.Phase {
+		latest.Phase = desired.Phase
+	}
+	if !componentStatusesEqual(desired.Components, original.Components) {
+		latest.Components = desired.Components
+	}
+	for _, condition := range desired.Conditions {
+		if previous := meta.FindStatusCondition(original.Conditions, condition.Type); previous == nil ||
+			!conditionsEqual([]metav1.Condition{*previous}, []metav1.Condition{condition}) {
+			meta.SetStatusCondition(&latest.Conditions, condition)
+		}
+	}
+	for _, condition := range original.Conditions {
+		if meta.FindStatusCondition(desired.Conditions, condition.Type) == nil {
+			meta.RemoveStatusCondition(&latest.Conditions, condition.Type)
+		}
+	}
 }
 
 // --- Status Comparison Helpers ---
@@ -1003,7 +1407,12 @@ func componentStatusesEqual(s1, s2 []appv1.ComponentStatusReference) bool {
 			existing.ResourceName != s.ResourceName ||
 			existing.Namespace != s.Namespace ||
 			existing.Health != s.Health ||
-			existing.Message != s.Message {
+			existing.Message != s.Message ||
+			existing.Reason != s.Reason ||
+			!conditionsEqual(existing.Conditions, s.Conditions) ||
+			!slices.Equal(existing.Conflicts, s.Conflicts) ||
+			!slices.Equal(existing.Pods, s.Pods) ||
+			existing.Diagnosis != s.Diagnosis {
 			return false
 		}
 	}
@@ -1018,6 +1427,16 @@ func setCondition(appDef *appv1.ApplicationDefinition, newCondition metav1.Condi
 	meta.SetStatusCondition(&appDef.Status.Conditions, newCondition)
 }
 
+// setAppHealthyCondition sets the component's AppHealthy condition, keeping LastTransitionTime if the status is unchanged.
+func setAppHealthyCondition(compStatus *appv1.ComponentStatusReference, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&compStatus.Conditions, metav1.Condition{
+		Type:    string(appv1.ConditionAppHealthy),
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
 func mapToSliceComponentStatus(statusMap map[string]*appv1.ComponentStatusReference) []appv1.ComponentStatusReference {
 	statusSlice := make([]appv1.ComponentStatusReference, 0, len(statusMap))
 	for _, statusPtr := range statusMap {
@@ -1030,13 +1449,14 @@ func mapToSliceComponentStatus(statusMap map[string]*appv1.ComponentStatusRefere
 	return statusSlice
 }
 
-// updateComponentStatusWithError sets the component's status message and health upon encountering a specific error.
+// updateComponentStatusWithError marks the component unhealthy with the error's reason code and message.
 func (r *ApplicationDefinitionReconciler) updateComponentStatusWithError(status *appv1.ComponentStatusReference, reason, errMsg string) {
 	if status == nil {
 		return // Should not happen if initialized correctly
 	}
 	status.Health = false
-	status.Message = fmt.Sprintf("%s: %s", reason, errMsg) // Prefix message with reason
+	status.Reason = reason
+	status.Message = errMsg
 }
 
 // updateComponentStatusForApplyError updates the relevant component status when an apply fails for one of its objects.
@@ -1046,16 +1466,43 @@ func (r *ApplicationDefinitionReconciler) updateComponentStatusForApplyError(sta
 		return // Cannot map object to component
 	}
 	if compStatus, ok := statusMap[compNameLabel]; ok {
-		// Only update if the current message isn't already reflecting a more critical prior error
-		if !strings.Contains(compStatus.Message, "Error:") && !strings.Contains(compStatus.Message, "Failed") {
+		// Keep the first error recorded for the component
+		if compStatus.Reason == "" {
 			gvk := failedObj.GetObjectKind().GroupVersionKind()
 			objKey := client.ObjectKeyFromObject(failedObj)
-			errMsg := fmt.Sprintf("ApplyError: Failed for %s %s: %v", gvk.Kind, objKey.String(), applyErr)
-			r.updateComponentStatusWithError(compStatus, "ApplyFailed", errMsg)
+			errMsg := fmt.Sprintf("Failed to apply %s %s: %v", gvk.Kind, objKey.String(), applyErr)
+			r.updateComponentStatusWithError(compStatus, reconcileerr.ReasonOf(applyErr), errMsg)
 		}
 	}
 }
 
+// updateComponentStatusForConflicts records the field manager conflicts of a failed apply on the owning component.
+func (r *ApplicationDefinitionReconciler) updateComponentStatusForConflicts(statusMap map[string]*appv1.ComponentStatusReference, failedObj client.Object, conflicts []kubeutil.FieldConflict) {
+	compStatus, ok := statusMap[failedObj.GetLabels()[compInstanceLabel]]
+	if !ok {
+		return // Cannot map object to component
+	}
+	resource := fmt.Sprintf("%s/%s", failedObj.GetObjectKind().GroupVersionKind().Kind, failedObj.GetName())
+	for _, conflict := range conflicts {
+		compStatus.Conflicts = append(compStatus.Conflicts, appv1.FieldConflict{
+			Resource: resource,
+			Field:    conflict.Field,
+			Manager:  conflict.Manager,
+		})
+	}
+	r.updateComponentStatusWithError(compStatus, reasonFieldManagerConflict,
+		fmt.Sprintf("%s fields are owned by other field managers: %s", resource, describeFieldConflicts(conflicts)))
+}
+
+// describeFieldConflicts formats conflicts as "field (manager)" pairs.
+func describeFieldConflicts(conflicts []kubeutil.FieldConflict) string {
+	parts := make([]string, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		parts = append(parts, fmt.Sprintf("%s (%s)", conflict.Field, conflict.Manager))
+	}
+	return strings.Join(parts, ", ")
+}
+
 // updateComponentStatusesFromApplyResults updates component statuses based *only* on the apply result.
 // Health checks happen later.
 func updateComponentStatusesFromApplyResults(statusMap map[string]*appv1.ComponentStatusReference, desiredObjectList []client.Object, applyResults map[string]kubeutil.ApplyResult) {
@@ -1093,14 +1540,11 @@ func updateComponentStatusesFromApplyResults(statusMap map[string]*appv1.Compone
 	}
 }
 
-// updateComponentStatusesForError sets an error message on all component statuses.
+// updateComponentStatusesForError records err on all component statuses that have no error of their own.
 func (r *ApplicationDefinitionReconciler) updateComponentStatusesForError(state *reconcileState, err error) {
-	errMsg := err.Error()
 	for _, compStatus := range state.componentStatuses {
-		// Avoid overwriting specific errors with a generic one unless the current status is non-terminal
-		if !strings.Contains(compStatus.Message, "Error:") && !strings.Contains(compStatus.Message, "Failed") {
-			compStatus.Health = false
-			compStatus.Message = fmt.Sprintf("OverallReconcileError: %s", errMsg)
+		if compStatus.Reason == "" {
+			r.updateComponentStatusWithError(compStatus, reconcileerr.ReasonOf(err), err.Error())
 		}
 	}
 }
@@ -1137,14 +1581,22 @@ func (r *ApplicationDefinitionReconciler) SetupWithManager(mgr ctrl.Manager) err
 		builder = builder.Owns(t)
 	}
 
+	// Watch ConfigMaps/Secrets referenced (not owned) by components, so external edits roll the pods
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &appv1.ApplicationDefinition{}, configRefIndexKey, indexConfigReferences); err != nil {
+		return fmt.Errorf("failed to index config references: %w", err)
+	}
+	builder = builder.
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.findAppsForConfigMap)).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.findAppsForSecret))
+
 	return builder.Complete(r)
 }
 
-// getEventRecorder returns the appropriate event recorder based on ApplicationDefinition annotations.
-// If webhook annotations are present, it returns a WebhookEventRecorder; otherwise, returns the standard recorder.
-func (r *ApplicationDefinitionReconciler) getEventRecorder(app *appv1.ApplicationDefinition) record.EventRecorder {
+// webhookRecorder returns the recorder sending the application's events to its change webhook,
+// or nil when the change ID and webhook URL annotations are not both set.
+func (r *ApplicationDefinitionReconciler) webhookRecorder(app *appv1.ApplicationDefinition) *webrecorder.WebhookEventRecorder {
 	if app == nil || app.Annotations == nil {
-		return r.Recorder
+		return nil
 	}
 
 	changeID := app.Annotations[appv1.AnnotationChangeID]
@@ -1152,56 +1604,96 @@ func (r *ApplicationDefinitionReconciler) getEventRecorder(app *appv1.Applicatio
 	webhookURL := app.Annotations[appv1.AnnotationChangeWebhookURL]
 
 	if webhookURL == "" || changeID == "" {
-		return r.Recorder
+		return nil
+	}
+
+	registry := r.WebhookRecorders
+	if registry == nil {
+		registry = webrecorder.NewRegistry()
+	}
+	endpoint := webrecorder.Endpoint{URL: webhookURL, SigningSecret: webhookSigningSecret(app), Format: webhookFormat(app)}
+	return registry.Recorder(endpoint, changeID, clusterID)
+}
+
+// webhookFormat returns the payload format named by the application's annotation, or empty to use the
+// operator's default. Unknown formats are ignored.
+func webhookFormat(app *appv1.ApplicationDefinition) webrecorder.Format {
+	value := app.Annotations[appv1.AnnotationChangeWebhookFormat]
+	if value == "" {
+		return ""
 	}
+	format, err := webrecorder.ParseFormat(value)
+	if err != nil {
+		log.Log.Error(err, "Ignoring webhook format annotation", "application", client.ObjectKeyFromObject(app))
+		return ""
+	}
+	return format
+}
 
-	return webrecorder.NewWebhookEventRecorder(webhookURL, changeID, clusterID)
+// webhookSigningSecret returns the signing secret referenced by the application's annotation,
+// "name" or "name/key" of a Secret in its namespace, or nil to use the operator's default.
+func webhookSigningSecret(app *appv1.ApplicationDefinition) *webrecorder.SigningSecret {
+	ref := app.Annotations[appv1.AnnotationChangeWebhookSecret]
+	if ref == "" {
+		return nil
+	}
+	name, key, found := strings.Cut(ref, "/")
+	if !found || key == "" {
+		key = appv1.DefaultWebhookSecretKey
+	}
+	return &webrecorder.SigningSecret{Namespace: app.Namespace, Name: name, Key: key}
 }
 
 // recordEvent is a helper method to record events with optional webhook support
 func (r *ApplicationDefinitionReconciler) recordEvent(app *appv1.ApplicationDefinition, phase, status, step, eventType, reason, message string) {
-	recorder := r.getEventRecorder(app)
+	r.recordEventf(app, phase, status, step, eventType, reason, "%s", message)
+}
 
-	if wr, ok := recorder.(*webrecorder.WebhookEventRecorder); ok {
-		// Get current change ID from annotations
-		currentChangeID := app.Annotations[appv1.AnnotationChangeID]
+// recordEventf is a helper method to record formatted events with optional webhook support.
+// The Kubernetes event is always recorded; the webhook is skipped once the change ID has been processed.
+func (r *ApplicationDefinitionReconciler) recordEventf(app *appv1.ApplicationDefinition, phase, status, step, eventType, reason, messageFmt string, args ...interface{}) {
+	r.recordComponentEventf(app, "", nil, phase, status, step, eventType, reason, messageFmt, args...)
+}
 
-		// Check if this change ID has already been processed
-		if app.Status.LastChangeID == currentChangeID {
-			// Skip sending duplicate webhook event for the same change ID
-			return
-		}
-		annotations := map[string]string{
-			webrecorder.PhaseKey:  phase,
-			webrecorder.StatusKey: status,
-			webrecorder.StepKey:   step,
-		}
-		wr.AnnotatedEventf(app, annotations, eventType, reason, "%s", message)
-	} else {
-		recorder.Event(app, eventType, reason, message)
+// recordComponentEventf records an event like recordEventf about a component, if not empty, attaching to
+// the webhook event how an applied object changed the resources it allocates, if change is not nil.
+// Notification channels receive the event unless its change has ended; without a change ID,
+// they are only spared repeats within the event TTL.
+func (r *ApplicationDefinitionReconciler) recordComponentEventf(app *appv1.ApplicationDefinition, component string, change *webrecorder.ResourceChange, phase, status, step, eventType, reason, messageFmt string, args ...interface{}) {
+	r.Recorder.Eventf(app, eventType, reason, messageFmt, args...)
+
+	// Skip sending duplicate events once the change has ended
+	if changeSettled(app) {
+		return
+	}
+	annotations := r.withTraceContext(app, webhookAnnotations(phase, status, step))
+	if component != "" {
+		annotations[webrecorder.ComponentKey] = component
+	}
+	r.Notifier.Notify(app, annotations, change, nil, true, eventType, reason, fmt.Sprintf(messageFmt, args...))
+
+	if wr := r.webhookRecorder(app); wr != nil {
+		wr.AnnotatedEventfWithResourceChange(app, annotations, change, eventType, reason, messageFmt, args...)
 	}
 }
 
-// recordEventf is a helper method to record formatted events with optional webhook support
-func (r *ApplicationDefinitionReconciler) recordEventf(app *appv1.ApplicationDefinition, phase, status, step, eventType, reason, messageFmt string, args ...interface{}) {
-	recorder := r.getEventRecorder(app)
+// recordUndedupedEventf records an event like recordEventf, but is not deduplicated by change ID:
+// drift and health changes happen without a new change, and every occurrence must reach the webhook.
+func (r *ApplicationDefinitionReconciler) recordUndedupedEventf(app *appv1.ApplicationDefinition, phase, status, step, eventType, reason, messageFmt string, args ...interface{}) {
+	r.Recorder.Eventf(app, eventType, reason, messageFmt, args...)
+	annotations := r.withTraceContext(app, webhookAnnotations(phase, status, step))
+	r.Notifier.Notify(app, annotations, nil, nil, false, eventType, reason, fmt.Sprintf(messageFmt, args...))
 
-	if wr, ok := recorder.(*webrecorder.WebhookEventRecorder); ok {
-		// Get current change ID from annotations
-		currentChangeID := app.Annotations[appv1.AnnotationChangeID]
+	if wr := r.webhookRecorder(app); wr != nil {
+		wr.UndedupedEventf(app, annotations, eventType, reason, messageFmt, args...)
+	}
+}
 
-		// Check if this change ID has already been processed
-		if app.Status.LastChangeID == currentChangeID {
-			// Skip sending duplicate webhook event for the same change ID
-			return
-		}
-		annotations := map[string]string{
-			webrecorder.PhaseKey:  phase,
-			webrecorder.StatusKey: status,
-			webrecorder.StepKey:   step,
-		}
-		wr.AnnotatedEventf(app, annotations, eventType, reason, messageFmt, args...)
-	} else {
-		recorder.Eventf(app, eventType, reason, messageFmt, args...)
+// webhookAnnotations returns the annotations describing an event to the webhook.
+func webhookAnnotations(phase, status, step string) map[string]string {
+	return map[string]string{
+		webrecorder.PhaseKey:  phase,
+		webrecorder.StatusKey: status,
+		webrecorder.StepKey:   step,
 	}
 }
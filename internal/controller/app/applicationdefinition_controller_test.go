@@ -32,16 +32,21 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/internal/controller/common/kubeutil"
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
 	_ "github.com/infinilabs/runtime-operator/pkg/builders/runtime"
+	_ "github.com/infinilabs/runtime-operator/pkg/reconcilers/runtime"
 )
 
 var _ = Describe("ApplicationDefinition Controller", func() {
@@ -231,5 +236,99 @@ var _ = Describe("ApplicationDefinition Controller", func() {
 				return *sts.Spec.Replicas
 			}, time.Second*10, time.Millisecond*500).Should(Equal(int32(3)))
 		})
+
+		It("should stamp the restart request on the workload pod template", func() {
+			controllerReconciler := &ApplicationDefinitionReconciler{
+				Client:     k8sClient,
+				Scheme:     k8sClient.Scheme(),
+				Recorder:   record.NewFakeRecorder(100),
+				Reconciler: reconciler.NewReconcilerWith(k8sClient),
+			}
+
+			By("Initial reconcile")
+			for i := 0; i < 5; i++ {
+				result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				if !result.Requeue && result.RequeueAfter == 0 {
+					break
+				}
+			}
+
+			By("Requesting a restart")
+			const token = "2025-01-01T00:00:00Z"
+			Expect(k8sClient.Get(ctx, typeNamespacedName, applicationdefinition)).To(Succeed())
+			applicationdefinition.Spec.RestartRequestedAt = token
+			Expect(k8sClient.Update(ctx, applicationdefinition)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			sts := &appsv1.StatefulSet{}
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: "test-comp", Namespace: "default"}, sts); err != nil {
+					return ""
+				}
+				return sts.Spec.Template.Annotations[restartedAtAnnotation]
+			}, time.Second*10, time.Millisecond*500).Should(Equal(token))
+
+			// The rollout never completes in envtest, so the request must not be recorded as honoured yet
+			Expect(k8sClient.Get(ctx, typeNamespacedName, applicationdefinition)).To(Succeed())
+			Expect(applicationdefinition.Status.RestartedAt).NotTo(HaveKey("test-comp"))
+		})
+	})
+})
+
+var _ = Describe("Field ownership of objects created before Server-Side Apply", func() {
+	ctx := context.Background()
+
+	// managers returns the operation of each field manager of the named ConfigMap.
+	managers := func(key types.NamespacedName) map[string]metav1.ManagedFieldsOperationType {
+		live := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, key, live)).To(Succeed())
+		operations := map[string]metav1.ManagedFieldsOperationType{}
+		for _, entry := range live.ManagedFields {
+			operations[entry.Manager] = entry.Operation
+		}
+		return operations
+	}
+
+	It("migrates the fields of the previous Create/Update field managers instead of conflicting with them", func() {
+		key := types.NamespacedName{Name: "pre-ssa-config", Namespace: "default"}
+		desired := func(mode string) *corev1.ConfigMap {
+			return &corev1.ConfigMap{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Data:       map[string]string{"mode": mode},
+			}
+		}
+		upgrade := kubeutil.WithUpgradeManagers(legacyFieldManager, common.OperatorName)
+
+		By("creating the object like the operator did before, with the resource reconciler")
+		legacy := reconciler.NewReconcilerWith(client.WithFieldOwner(k8sClient, legacyFieldManager))
+		Expect(kubeutil.ApplyObjectV2(ctx, legacy, desired("old"), common.OperatorName).Error).NotTo(HaveOccurred())
+		DeferCleanup(func() { _ = k8sClient.Delete(ctx, desired("")) })
+		Expect(managers(key)).To(HaveKeyWithValue(legacyFieldManager, metav1.ManagedFieldsOperationUpdate))
+
+		By("applying a changed value without forcing ownership")
+		result := kubeutil.ApplyObject(ctx, k8sClient, desired("new"), common.OperatorName, upgrade)
+		Expect(result.Error).NotTo(HaveOccurred())
+		Expect(result.Conflicts).To(BeEmpty())
+		Expect(result.Operation).To(Equal(controllerutil.OperationResultUpdated))
+		Expect(managers(key)).To(And(
+			Not(HaveKey(legacyFieldManager)),
+			HaveKeyWithValue(common.OperatorName, metav1.ManagedFieldsOperationApply),
+		))
+
+		By("taking back the fields the resource reconciler fallback updated")
+		fallback := reconciler.NewReconcilerWith(client.WithFieldOwner(k8sClient, common.OperatorName))
+		Expect(kubeutil.ApplyObjectV2(ctx, fallback, desired("recreated"), common.OperatorName).Error).NotTo(HaveOccurred())
+		result = kubeutil.ApplyObject(ctx, k8sClient, desired("applied"), common.OperatorName, upgrade)
+		Expect(result.Error).NotTo(HaveOccurred())
+		live := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, key, live)).To(Succeed())
+		Expect(live.Data).To(HaveKeyWithValue("mode", "applied"))
+		for _, entry := range live.ManagedFields {
+			Expect(entry.Operation).To(Equal(metav1.ManagedFieldsOperationApply), "manager %s", entry.Manager)
+		}
 	})
 })
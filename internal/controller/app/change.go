@@ -0,0 +1,195 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/app/change.go
+package app
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/pkg/webrecorder"
+)
+
+const (
+	// changePhase, changeStartStep and changeEndStep describe the lifecycle events of a change.
+	changePhase     = "Change"
+	changeStartStep = "StartChange"
+	changeEndStep   = "EndChange"
+
+	// maxTrackedChanges bounds status.changes; the oldest changes are dropped first.
+	maxTrackedChanges = 10
+)
+
+// currentChange returns the most recent change of the application, or nil.
+func currentChange(appDef *appv1.ApplicationDefinition) *appv1.ChangeStatus {
+	if len(appDef.Status.Changes) == 0 {
+		return nil
+	}
+	return &appDef.Status.Changes[len(appDef.Status.Changes)-1]
+}
+
+// changeSettled reports whether the change named by the change-id annotation has ended, so its events
+// are no longer sent. Applications tracked before status.changes existed fall back to lastChangeID.
+func changeSettled(appDef *appv1.ApplicationDefinition) bool {
+	changeID := appDef.Annotations[appv1.AnnotationChangeID]
+	if changeID == "" {
+		return false
+	}
+	if change := currentChange(appDef); change != nil && change.ID == changeID {
+		return change.State != appv1.ChangeStateInProgress
+	}
+	return appDef.Status.LastChangeID == changeID
+}
+
+// startChange starts tracking the change named by the change-id annotation when it is new or reused for
+// a new spec generation. A change still in progress is superseded by it. It returns the function recording
+// the "change started" event, and the summary of a superseded change, to be called once the status is
+// saved; nil when no change was started.
+func (r *ApplicationDefinitionReconciler) startChange(appDef *appv1.ApplicationDefinition) (recordEvents func()) {
+	changeID := appDef.Annotations[appv1.AnnotationChangeID]
+	if changeID == "" {
+		return nil
+	}
+	current := currentChange(appDef)
+	if current == nil && appDef.Status.LastChangeID == changeID {
+		return nil // Completed before changes were tracked
+	}
+	if current != nil && current.ID == changeID &&
+		(current.State == appv1.ChangeStateInProgress || current.Generation >= appDef.Generation) {
+		return nil
+	}
+	recordSuperseded := func() {}
+	if current != nil && current.State == appv1.ChangeStateInProgress {
+		recordSuperseded = r.finishChange(appDef, appv1.ChangeStateSuperseded)
+	}
+
+	appDef.Status.Changes = append(appDef.Status.Changes, appv1.ChangeStatus{
+		ID:         changeID,
+		State:      appv1.ChangeStateInProgress,
+		Generation: appDef.Generation,
+		StartedAt:  metav1.Now(),
+	})
+	if excess := len(appDef.Status.Changes) - maxTrackedChanges; excess > 0 {
+		appDef.Status.Changes = slices.Delete(appDef.Status.Changes, 0, excess)
+	}
+	generation := appDef.Generation
+	return func() {
+		recordSuperseded()
+		r.recordEventf(appDef, changePhase, webrecorder.StatusInProgress, changeStartStep, corev1.EventTypeNormal,
+			"ChangeStarted", "Change %s started for generation %d", changeID, generation)
+	}
+}
+
+// recordChangedObject adds an object created or updated by the apply to the current change.
+func recordChangedObject(appDef *appv1.ApplicationDefinition, operation controllerutil.OperationResult, object string) {
+	change := currentChange(appDef)
+	if change == nil || change.State != appv1.ChangeStateInProgress {
+		return
+	}
+	switch operation {
+	case controllerutil.OperationResultCreated:
+		if !slices.Contains(change.Created, object) {
+			change.Created = append(change.Created, object)
+		}
+	case controllerutil.OperationResultUpdated:
+		if !slices.Contains(change.Created, object) && !slices.Contains(change.Updated, object) {
+			change.Updated = append(change.Updated, object)
+		}
+	}
+}
+
+// endChange ends the current change, if in progress, in the given state and sends its summary event:
+// the objects it created and updated, its duration and the application's phase.
+func (r *ApplicationDefinitionReconciler) endChange(appDef *appv1.ApplicationDefinition, state appv1.ChangeState) {
+	r.finishChange(appDef, state)()
+}
+
+// finishChange ends the current change, if in progress, in the given state and returns the function
+// sending its summary event.
+func (r *ApplicationDefinitionReconciler) finishChange(appDef *appv1.ApplicationDefinition, state appv1.ChangeState) (recordSummary func()) {
+	change := currentChange(appDef)
+	if change == nil || change.State != appv1.ChangeStateInProgress {
+		return func() {}
+	}
+	now := metav1.Now()
+	duration := now.Sub(change.StartedAt.Time).Round(time.Second)
+	summary := &webrecorder.ChangeSummary{
+		State:     changeSummaryState(state),
+		Phase:     string(appDef.Status.Phase),
+		StartedAt: change.StartedAt.UTC().Format(time.RFC3339),
+		Duration:  duration.String(),
+		Created:   slices.Clone(change.Created),
+		Updated:   slices.Clone(change.Updated),
+	}
+
+	status, eventType := webrecorder.StatusSuccess, corev1.EventTypeNormal
+	if state != appv1.ChangeStateCompleted {
+		status, eventType = webrecorder.StatusFailure, corev1.EventTypeWarning
+	}
+	message := fmt.Sprintf("Change %s %s after %s in phase %s: %d objects created, %d updated",
+		change.ID, summary.State, summary.Duration, summary.Phase, len(summary.Created), len(summary.Updated))
+	changeID := change.ID
+
+	change.State = state
+	change.EndedAt = &now
+	return func() {
+		r.recordChangeSummary(appDef, changeID, summary, status, eventType, "Change"+string(state), message)
+	}
+}
+
+// recordChangeSummary records the terminal event of the change, which may not be the one named by the
+// change-id annotation anymore when it was superseded.
+func (r *ApplicationDefinitionReconciler) recordChangeSummary(appDef *appv1.ApplicationDefinition, changeID string, summary *webrecorder.ChangeSummary, status, eventType, reason, message string) {
+	r.Recorder.Event(appDef, eventType, reason, message)
+
+	subject := appDef.DeepCopy()
+	if subject.Annotations == nil {
+		// The change-id annotation may have been removed while the change was in progress
+		subject.Annotations = map[string]string{}
+	}
+	subject.Annotations[appv1.AnnotationChangeID] = changeID
+	annotations := r.withTraceContext(appDef, webhookAnnotations(changePhase, status, changeEndStep))
+	r.Notifier.Notify(subject, annotations, nil, summary, true, eventType, reason, message)
+	if wr := r.webhookRecorder(subject); wr != nil {
+		wr.SummaryEventf(subject, annotations, summary, eventType, reason, "%s", message)
+	}
+}
+
+// changeSummaryState returns the state of a change as reported in its summary.
+func changeSummaryState(state appv1.ChangeState) string {
+	switch state {
+	case appv1.ChangeStateCompleted:
+		return "completed"
+	case appv1.ChangeStateFailed:
+		return "failed"
+	default:
+		return "superseded"
+	}
+}
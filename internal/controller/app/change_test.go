@@ -0,0 +1,215 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/pkg/webrecorder"
+)
+
+var _ = Describe("Change lifecycle", func() {
+	var (
+		c        client.Client
+		recorder *record.FakeRecorder
+		r        *ApplicationDefinitionReconciler
+		appDef   *appv1.ApplicationDefinition
+	)
+
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(s))
+		utilruntime.Must(appv1.AddToScheme(s))
+		channel := &appv1.NotificationChannel{
+			ObjectMeta: metav1.ObjectMeta{Name: "changes", Namespace: "apps", UID: "uid-changes", Labels: map[string]string{"team": "ops"}},
+			Spec: appv1.NotificationChannelSpec{
+				URL:    "https://hooks.example.com/changes",
+				Filter: appv1.NotificationFilter{Phases: []string{changePhase}},
+			},
+		}
+		c = fake.NewClientBuilder().WithScheme(s).WithObjects(channel).Build()
+		recorder = record.NewFakeRecorder(100)
+		r = &ApplicationDefinitionReconciler{Client: c, Scheme: s, Recorder: recorder,
+			Notifier: NewNotifier(c, NewNotificationOutbox(c, c), nil)}
+		appDef = &appv1.ApplicationDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "search", Namespace: "apps", UID: "uid-search", Generation: 1,
+				Annotations: map[string]string{appv1.AnnotationChangeID: "c1"}},
+			Spec: appv1.ApplicationDefinitionSpec{
+				NotificationChannelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "ops"}},
+			},
+			Status: appv1.ApplicationDefinitionStatus{Phase: appv1.ApplicationPhaseRunning},
+		}
+	})
+
+	// start starts a change and records its events, as done once the change is saved.
+	start := func() {
+		if recordEvents := r.startChange(appDef); recordEvents != nil {
+			recordEvents()
+		}
+	}
+
+	// sent returns the events queued for the notification channel.
+	sent := func() []webrecorder.WebhookEvent {
+		cm := &corev1.ConfigMap{}
+		if err := c.Get(context.Background(), client.ObjectKey{Namespace: "apps", Name: "changes" + NotificationOutboxSuffix}, cm); err != nil {
+			return nil
+		}
+		var entries []webrecorder.OutboxEntry
+		Expect(json.Unmarshal([]byte(cm.Data["entries.json"]), &entries)).To(Succeed())
+		var events []webrecorder.WebhookEvent
+		for _, entry := range entries {
+			events = append(events, entry.Event)
+		}
+		return events
+	}
+
+	It("starts a change once and ends it with a summary of the objects it changed", func() {
+		start()
+		start()
+		Expect(appDef.Status.Changes).To(HaveLen(1))
+		Expect(appDef.Status.Changes[0].State).To(Equal(appv1.ChangeStateInProgress))
+		Expect(recorder.Events).To(Receive(ContainSubstring("ChangeStarted")))
+		Expect(changeSettled(appDef)).To(BeFalse())
+
+		recordChangedObject(appDef, controllerutil.OperationResultCreated, "Service/search")
+		recordChangedObject(appDef, controllerutil.OperationResultUpdated, "Service/search")
+		recordChangedObject(appDef, controllerutil.OperationResultUpdated, "StatefulSet/search")
+		recordChangedObject(appDef, controllerutil.OperationResultNone, "ConfigMap/search")
+
+		r.endChange(appDef, appv1.ChangeStateCompleted)
+		change := appDef.Status.Changes[0]
+		Expect(change.State).To(Equal(appv1.ChangeStateCompleted))
+		Expect(change.EndedAt).NotTo(BeNil())
+		Expect(change.Created).To(Equal([]string{"Service/search"}))
+		Expect(change.Updated).To(Equal([]string{"StatefulSet/search"}))
+		Expect(recorder.Events).To(Receive(ContainSubstring("1 objects created, 1 updated")))
+		Expect(changeSettled(appDef)).To(BeTrue())
+
+		events := sent()
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Step).To(Equal(changeStartStep))
+		Expect(events[0].Sequence).To(Equal(int64(1)))
+		Expect(events[1].Step).To(Equal(changeEndStep))
+		Expect(events[1].Sequence).To(Equal(int64(2)))
+		Expect(events[1].Summary).NotTo(BeNil())
+		Expect(events[1].Summary.State).To(Equal("completed"))
+		Expect(events[1].Summary.Phase).To(Equal(string(appv1.ApplicationPhaseRunning)))
+		Expect(events[1].Summary.Created).To(Equal([]string{"Service/search"}))
+	})
+
+	It("announces a change only once it is saved", func() {
+		recordEvents := r.startChange(appDef)
+		Expect(recordEvents).NotTo(BeNil())
+		Expect(appDef.Status.Changes).To(HaveLen(1))
+		Expect(recorder.Events).NotTo(Receive())
+		Expect(sent()).To(BeEmpty())
+
+		// A status update that failed is followed by a reconcile starting from the saved status
+		appDef.Status.Changes = nil
+		recordEvents = r.startChange(appDef)
+		recordEvents()
+		Expect(sent()).To(HaveLen(1))
+		Expect(sent()[0].Sequence).To(Equal(int64(1)))
+		Expect(r.startChange(appDef)).To(BeNil())
+	})
+
+	It("starts a reused change ID again for a new spec generation", func() {
+		start()
+		r.endChange(appDef, appv1.ChangeStateCompleted)
+		start()
+		Expect(appDef.Status.Changes).To(HaveLen(1))
+
+		appDef.Generation = 2
+		start()
+		Expect(appDef.Status.Changes).To(HaveLen(2))
+		Expect(appDef.Status.Changes[1].ID).To(Equal("c1"))
+		Expect(appDef.Status.Changes[1].Generation).To(Equal(int64(2)))
+		Expect(changeSettled(appDef)).To(BeFalse())
+		Expect(sent()[2].Sequence).To(Equal(int64(3)))
+	})
+
+	It("supersedes a change in progress when another change ID is set", func() {
+		start()
+		appDef.Annotations[appv1.AnnotationChangeID] = "c2"
+		appDef.Generation = 2
+		start()
+
+		Expect(appDef.Status.Changes).To(HaveLen(2))
+		Expect(appDef.Status.Changes[0].State).To(Equal(appv1.ChangeStateSuperseded))
+		Expect(appDef.Status.Changes[1].ID).To(Equal("c2"))
+
+		events := sent()
+		Expect(events).To(HaveLen(3))
+		Expect(events[1].ChangeID).To(Equal("c1"))
+		Expect(events[1].Summary.State).To(Equal("superseded"))
+		Expect(events[2].ChangeID).To(Equal("c2"))
+		Expect(events[2].Sequence).To(Equal(int64(1)))
+	})
+
+	It("ends a change whose application lost all its annotations while it was in progress", func() {
+		start()
+		appDef.Annotations = nil
+
+		Expect(func() { r.endChange(appDef, appv1.ChangeStateCompleted) }).NotTo(Panic())
+		Expect(appDef.Status.Changes[0].State).To(Equal(appv1.ChangeStateCompleted))
+		Expect(appDef.Annotations).To(BeNil())
+
+		events := sent()
+		Expect(events).To(HaveLen(2))
+		Expect(events[1].ChangeID).To(Equal("c1"))
+		Expect(events[1].Summary.State).To(Equal("completed"))
+	})
+
+	It("does not restart changes completed before changes were tracked", func() {
+		appDef.Status.LastChangeID = "c1"
+		start()
+		Expect(appDef.Status.Changes).To(BeEmpty())
+		Expect(changeSettled(appDef)).To(BeTrue())
+	})
+
+	It("keeps only the most recent changes", func() {
+		for i := 0; i < maxTrackedChanges+3; i++ {
+			appDef.Annotations[appv1.AnnotationChangeID] = fmt.Sprintf("c%d", i)
+			start()
+			r.endChange(appDef, appv1.ChangeStateCompleted)
+		}
+		Expect(appDef.Status.Changes).To(HaveLen(maxTrackedChanges))
+		Expect(appDef.Status.Changes[0].ID).To(Equal("c3"))
+	})
+})
@@ -0,0 +1,142 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/app/config_watch.go
+package app
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+	commonutil "github.com/infinilabs/runtime-operator/pkg/apis/common/util"
+)
+
+const (
+	// configRefIndexKey indexes ApplicationDefinitions by the ConfigMaps/Secrets their components reference.
+	// Index values have the form "ConfigMap/<name>" or "Secret/<name>".
+	configRefIndexKey = "spec.components.configRefs"
+)
+
+// indexConfigReferences extracts the referenced ConfigMaps/Secrets of all components for the field indexer.
+func indexConfigReferences(obj client.Object) []string {
+	appDef, ok := obj.(*appv1.ApplicationDefinition)
+	if !ok {
+		return nil
+	}
+	var refs []string
+	for _, comp := range appDef.Spec.Components {
+		config, err := commonutil.UnmarshalAppSpecificConfig(comp.Type, comp.Properties)
+		if err != nil {
+			continue // Invalid properties are reported by the reconcile loop
+		}
+		runtimeConfig, ok := config.(*common.RuntimeConfig)
+		if !ok || runtimeConfig == nil {
+			continue
+		}
+		for _, name := range runtimeConfig.ReferencedConfigMaps() {
+			refs = append(refs, "ConfigMap/"+name)
+		}
+		for _, name := range runtimeConfig.ReferencedSecrets() {
+			refs = append(refs, "Secret/"+name)
+		}
+	}
+	return refs
+}
+
+// findAppsForConfigMap maps a ConfigMap event to the ApplicationDefinitions referencing it.
+func (r *ApplicationDefinitionReconciler) findAppsForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	return r.findAppsReferencing(ctx, "ConfigMap/"+obj.GetName(), obj.GetNamespace())
+}
+
+// findAppsForSecret maps a Secret event to the ApplicationDefinitions referencing it.
+func (r *ApplicationDefinitionReconciler) findAppsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	return r.findAppsReferencing(ctx, "Secret/"+obj.GetName(), obj.GetNamespace())
+}
+
+func (r *ApplicationDefinitionReconciler) findAppsReferencing(ctx context.Context, ref, namespace string) []reconcile.Request {
+	appList := &appv1.ApplicationDefinitionList{}
+	if err := r.Client.List(ctx, appList, client.InNamespace(namespace), client.MatchingFields{configRefIndexKey: ref}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list ApplicationDefinitions referencing config", "ref", ref)
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(appList.Items))
+	for _, app := range appList.Items {
+		key := client.ObjectKeyFromObject(&app)
+		r.configChanged.Store(key, struct{}{}) // Builds the application even if it is stable
+		requests = append(requests, reconcile.Request{NamespacedName: key})
+	}
+	return requests
+}
+
+// desiredConfigChecksums collects the config checksum annotation of each component's workloads.
+func desiredConfigChecksums(objects []client.Object) map[string]string {
+	checksums := make(map[string]string)
+	for _, obj := range objects {
+		var annotations map[string]string
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			annotations = o.Spec.Template.Annotations
+		case *appsv1.StatefulSet:
+			annotations = o.Spec.Template.Annotations
+		default:
+			continue
+		}
+		compName := obj.GetLabels()[compInstanceLabel]
+		if sum := annotations[common.ConfigChecksumAnnotation]; compName != "" && sum != "" {
+			checksums[compName] = sum
+		}
+	}
+	return checksums
+}
+
+// configChecksumsChanged reports whether the freshly built workloads consume different configuration
+// than the one recorded in status after the last successful apply.
+func configChecksumsChanged(state *reconcileState) bool {
+	desired := desiredConfigChecksums(state.desiredObjects)
+	recorded := state.appDef.Status.ConfigChecksums
+	if len(desired) != len(recorded) {
+		return true
+	}
+	for comp, sum := range desired {
+		if recorded[comp] != sum {
+			return true
+		}
+	}
+	return false
+}
+
+// recordConfigChecksums stores the applied config checksums in status.
+func recordConfigChecksums(state *reconcileState) {
+	desired := desiredConfigChecksums(state.desiredObjects)
+	if len(desired) == 0 {
+		state.appDef.Status.ConfigChecksums = nil
+		return
+	}
+	state.appDef.Status.ConfigChecksums = desired
+}
@@ -0,0 +1,257 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/app/drift.go
+package app
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/pkg/webrecorder"
+)
+
+const (
+	// defaultDriftScanInterval is how often a stable application is scanned for drift.
+	defaultDriftScanInterval = 5 * time.Minute
+
+	// maxDriftPathsInMessage bounds the number of paths listed in the Drifted condition message.
+	maxDriftPathsInMessage = 10
+
+	driftPhase = "Drift"
+	driftStep  = "DetectDrift"
+)
+
+// effectiveDriftPolicy returns the drift policy of an application, defaulting to Report.
+func effectiveDriftPolicy(appDef *appv1.ApplicationDefinition) appv1.DriftPolicy {
+	if appDef.Spec.DriftPolicy == "" {
+		return appv1.DriftPolicyReport
+	}
+	return appDef.Spec.DriftPolicy
+}
+
+// driftScanInterval returns how often the application is scanned for drift.
+func driftScanInterval(appDef *appv1.ApplicationDefinition) time.Duration {
+	if appDef.Spec.DriftScanInterval != nil && appDef.Spec.DriftScanInterval.Duration > 0 {
+		return appDef.Spec.DriftScanInterval.Duration
+	}
+	return defaultDriftScanInterval
+}
+
+// detectDrift compares the live state of every desired object with its freshly built desired state.
+// It returns the diverging paths as "Kind/name:.field.path", sorted.
+func (r *ApplicationDefinitionReconciler) detectDrift(ctx context.Context, state *reconcileState) ([]string, error) {
+	var drifted []string
+	for _, obj := range state.desiredObjects {
+		gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get GVK of %s: %w", obj.GetName(), err)
+		}
+		resource := fmt.Sprintf("%s/%s", gvk.Kind, obj.GetName())
+
+		liveObj, err := r.Scheme.New(gvk)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create live object for %s: %w", resource, err)
+		}
+		live, ok := liveObj.(client.Object)
+		if !ok {
+			continue
+		}
+		if err := r.Client.Get(ctx, client.ObjectKeyFromObject(obj), live); err != nil {
+			if apierrors.IsNotFound(err) {
+				drifted = append(drifted, resource+": missing")
+				continue
+			}
+			return nil, fmt.Errorf("failed to get live %s: %w", resource, err)
+		}
+
+		desiredMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert desired %s: %w", resource, err)
+		}
+		liveMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(live)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert live %s: %w", resource, err)
+		}
+		for _, path := range diffDesiredFields(desiredMap, liveMap) {
+			drifted = append(drifted, resource+":"+path)
+		}
+	}
+	sort.Strings(drifted)
+	return drifted, nil
+}
+
+// diffDesiredFields returns the paths of desired leaf fields whose live value differs.
+// Only fields present in the desired state are compared, so values defaulted by the API server or
+// set by other controllers are ignored. Status and server-managed metadata are skipped.
+func diffDesiredFields(desired, live map[string]interface{}) []string {
+	var paths []string
+	for key, value := range desired {
+		switch key {
+		case "status", "apiVersion", "kind":
+			continue
+		case "metadata":
+			desiredMeta, _ := value.(map[string]interface{})
+			liveMeta, _ := live[key].(map[string]interface{})
+			for _, field := range []string{"labels", "annotations"} {
+				paths = append(paths, diffValue(".metadata."+field, desiredMeta[field], liveMeta[field])...)
+			}
+			continue
+		}
+		paths = append(paths, diffValue("."+key, value, live[key])...)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func diffValue(path string, desired, live interface{}) []string {
+	if isEmptyValue(desired) {
+		return nil // Unset in the desired state, not owned by the operator
+	}
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return []string{path}
+		}
+		var paths []string
+		for key, value := range d {
+			if path == ".spec.template.metadata" && key == "creationTimestamp" {
+				continue
+			}
+			paths = append(paths, diffValue(path+"."+key, value, l[key])...)
+		}
+		return paths
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok || len(l) != len(d) {
+			return []string{path}
+		}
+		var paths []string
+		for i := range d {
+			paths = append(paths, diffValue(fmt.Sprintf("%s[%d]", path, i), d[i], l[i])...)
+		}
+		return paths
+	default:
+		if !reflect.DeepEqual(desired, live) {
+			return []string{path}
+		}
+		return nil
+	}
+}
+
+func isEmptyValue(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(t) == 0
+	case []interface{}:
+		return len(t) == 0
+	}
+	return false
+}
+
+// scanForDrift runs a drift scan on a stable application and records the Drifted condition.
+// It returns true when drift was found and the policy asks for it to be corrected.
+func (r *ApplicationDefinitionReconciler) scanForDrift(ctx context.Context, state *reconcileState) (bool, error) {
+	logger := log.FromContext(ctx)
+	appDef := state.appDef
+	policy := effectiveDriftPolicy(appDef)
+	if policy == appv1.DriftPolicyIgnore {
+		meta.RemoveStatusCondition(&appDef.Status.Conditions, string(appv1.ConditionDrifted))
+		return false, nil
+	}
+
+	paths, err := r.detectDrift(ctx, state)
+	if err != nil {
+		return false, err
+	}
+	if len(paths) == 0 {
+		setCondition(appDef, metav1.Condition{Type: string(appv1.ConditionDrifted), Status: metav1.ConditionFalse,
+			Reason: "NoDrift", Message: "Live resources match the desired state"})
+		return false, nil
+	}
+
+	message := driftMessage(paths)
+	previous := meta.FindStatusCondition(appDef.Status.Conditions, string(appv1.ConditionDrifted))
+	if previous == nil || previous.Status != metav1.ConditionTrue || previous.Message != message {
+		// A new detection, announce it
+		logger.Info("Drift detected", "policy", policy, "paths", paths)
+		r.recordUndedupedEventf(appDef, driftPhase, webrecorder.StatusFailure, driftStep, corev1.EventTypeWarning, "DriftDetected",
+			"Drift detected (policy %s): %s", policy, message)
+	}
+	setCondition(appDef, metav1.Condition{Type: string(appv1.ConditionDrifted), Status: metav1.ConditionTrue,
+		Reason: "DriftDetected", Message: message})
+
+	return policy == appv1.DriftPolicyCorrect, nil
+}
+
+// markDriftCorrected records that the desired state has just been applied, which removes any drift.
+func (r *ApplicationDefinitionReconciler) markDriftCorrected(state *reconcileState) {
+	appDef := state.appDef
+	if effectiveDriftPolicy(appDef) == appv1.DriftPolicyIgnore {
+		meta.RemoveStatusCondition(&appDef.Status.Conditions, string(appv1.ConditionDrifted))
+		return
+	}
+	if state.forceApply {
+		r.recordUndedupedEventf(appDef, driftPhase, webrecorder.StatusSuccess, driftStep, corev1.EventTypeNormal, "DriftCorrected",
+			"Drift corrected by re-applying the desired state")
+		setCondition(appDef, metav1.Condition{Type: string(appv1.ConditionDrifted), Status: metav1.ConditionFalse,
+			Reason: "DriftCorrected", Message: "Desired state re-applied"})
+		return
+	}
+	setCondition(appDef, metav1.Condition{Type: string(appv1.ConditionDrifted), Status: metav1.ConditionFalse,
+		Reason: "Applied", Message: "Desired state applied"})
+}
+
+// driftScanResult requeues a stable application for its next drift scan.
+func driftScanResult(appDef *appv1.ApplicationDefinition) ctrl.Result {
+	if effectiveDriftPolicy(appDef) == appv1.DriftPolicyIgnore {
+		return ctrl.Result{}
+	}
+	return ctrl.Result{RequeueAfter: driftScanInterval(appDef)}
+}
+
+// driftMessage lists the drifted paths, truncated to maxDriftPathsInMessage.
+func driftMessage(paths []string) string {
+	if len(paths) <= maxDriftPathsInMessage {
+		return strings.Join(paths, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(paths[:maxDriftPathsInMessage], ", "), len(paths)-maxDriftPathsInMessage)
+}
@@ -0,0 +1,86 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Drift comparison", func() {
+	desired := func() map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "StatefulSet",
+			"metadata": map[string]interface{}{
+				"name":   "web",
+				"labels": map[string]interface{}{"app": "web"},
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(3),
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{"creationTimestamp": nil},
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "web", "image": "web:1.0"},
+						},
+					},
+				},
+			},
+			"status": map[string]interface{}{},
+		}
+	}
+
+	It("ignores defaulted and server-managed fields", func() {
+		live := desired()
+		live["metadata"].(map[string]interface{})["resourceVersion"] = "42"
+		live["metadata"].(map[string]interface{})["labels"].(map[string]interface{})["extra"] = "x"
+		live["spec"].(map[string]interface{})["podManagementPolicy"] = "OrderedReady"
+		live["status"] = map[string]interface{}{"readyReplicas": int64(3)}
+		Expect(diffDesiredFields(desired(), live)).To(BeEmpty())
+	})
+
+	It("reports fields changed outside the operator", func() {
+		live := desired()
+		spec := live["spec"].(map[string]interface{})
+		spec["replicas"] = int64(5)
+		container := spec["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})[0]
+		container.(map[string]interface{})["image"] = "web:2.0"
+		delete(live["metadata"].(map[string]interface{})["labels"].(map[string]interface{}), "app")
+
+		Expect(diffDesiredFields(desired(), live)).To(Equal([]string{
+			".metadata.labels.app",
+			".spec.replicas",
+			".spec.template.spec.containers[0].image",
+		}))
+	})
+
+	It("truncates long path lists in the condition message", func() {
+		paths := make([]string, maxDriftPathsInMessage+2)
+		for i := range paths {
+			paths[i] = "p"
+		}
+		Expect(driftMessage(paths)).To(HaveSuffix("and 2 more"))
+	})
+})
@@ -0,0 +1,247 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/app/health_monitor.go
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	configv1alpha1 "github.com/infinilabs/runtime-operator/api/config/v1alpha1"
+	"github.com/infinilabs/runtime-operator/internal/controller/common/kubeutil"
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+	commonutil "github.com/infinilabs/runtime-operator/pkg/apis/common/util"
+	"github.com/infinilabs/runtime-operator/pkg/strategy"
+	"github.com/infinilabs/runtime-operator/pkg/webrecorder"
+)
+
+const (
+	// healthResyncJitter spreads resyncs of many applications by up to this fraction of the period.
+	healthResyncJitter = 0.2
+
+	healthPhase = "Health"
+	healthStep  = "MonitorHealth"
+)
+
+// HealthMonitorReconciler keeps the phase of settled applications in line with the health of their
+// components. It re-evaluates the workloads and the application-level checks of Running and Degraded
+// applications on owned workload events and on a jittered resync period, and moves them between Running
+// and Degraded. It never applies resources; spec changes are left to ApplicationDefinitionReconciler.
+type HealthMonitorReconciler struct {
+	Client   client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// WebhookRecorders is shared with ApplicationDefinitionReconciler; see its field.
+	WebhookRecorders *webrecorder.Registry
+	// Notifier is shared with ApplicationDefinitionReconciler; see its field.
+	Notifier *Notifier
+	// ResyncPeriod is the base period between evaluations. Zero uses the configuration default (1m).
+	ResyncPeriod time.Duration
+}
+
+func (r *HealthMonitorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if req.NamespacedName.Namespace != common.Namespace {
+		return ctrl.Result{}, nil
+	}
+	logger := log.FromContext(ctx).WithValues("appdefinition", req.NamespacedName)
+
+	appDef := &appv1.ApplicationDefinition{}
+	if err := r.Client.Get(ctx, req.NamespacedName, appDef); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !healthMonitored(appDef) {
+		// Not settled yet; the spec reconciler checks health while it converges
+		return ctrl.Result{}, nil
+	}
+
+	original := appDef.DeepCopy()
+	ready, summary := r.evaluateHealth(ctx, appDef)
+	previous, current := applyHealthTransition(appDef, ready, summary)
+
+	if !equality.Semantic.DeepEqual(original.Status, appDef.Status) {
+		patch := client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})
+		if err := r.Client.Status().Patch(ctx, appDef, patch); err != nil {
+			if apierrors.IsConflict(err) {
+				// The spec reconciler updated the status meanwhile; evaluate again on top of it
+				logger.V(1).Info("Status changed during health evaluation, retrying")
+				return ctrl.Result{Requeue: true}, nil
+			}
+			return ctrl.Result{}, fmt.Errorf("failed to update health status: %w", err)
+		}
+	}
+
+	if previous != current {
+		logger.Info("Application health changed", "from", previous, "to", current, "summary", summary)
+		apps := r.appReconciler()
+		if current == appv1.ApplicationPhaseDegraded {
+			apps.recordUndedupedEventf(appDef, healthPhase, webrecorder.StatusFailure, healthStep, corev1.EventTypeWarning,
+				"ApplicationDegraded", "Application is degraded: %s", summary)
+		} else {
+			apps.recordUndedupedEventf(appDef, healthPhase, webrecorder.StatusSuccess, healthStep, corev1.EventTypeNormal,
+				"ApplicationRecovered", "Application recovered: %s", summary)
+		}
+	}
+	return ctrl.Result{RequeueAfter: wait.Jitter(r.resyncPeriod(), healthResyncJitter)}, nil
+}
+
+func (r *HealthMonitorReconciler) resyncPeriod() time.Duration {
+	if r.ResyncPeriod > 0 {
+		return r.ResyncPeriod
+	}
+	return configv1alpha1.DefaultHealthResyncPeriod
+}
+
+// appReconciler returns an ApplicationDefinitionReconciler sharing this monitor's client and recorders,
+// to reuse its event helpers.
+func (r *HealthMonitorReconciler) appReconciler() *ApplicationDefinitionReconciler {
+	return &ApplicationDefinitionReconciler{Client: r.Client, Scheme: r.Scheme, Recorder: r.Recorder, WebhookRecorders: r.WebhookRecorders, Notifier: r.Notifier}
+}
+
+// healthMonitored reports whether the application is settled: Running or Degraded, with its spec
+// observed and not suspended or being deleted.
+func healthMonitored(appDef *appv1.ApplicationDefinition) bool {
+	if !appDef.DeletionTimestamp.IsZero() || (appDef.Spec.Suspend != nil && *appDef.Spec.Suspend) {
+		return false
+	}
+	if appDef.Status.ObservedGeneration != appDef.Generation {
+		return false
+	}
+	return appDef.Status.Phase == appv1.ApplicationPhaseRunning || appDef.Status.Phase == appv1.ApplicationPhaseDegraded
+}
+
+// evaluateHealth checks every component's workload and, when the workload is ready, its application-level
+// health, updating the component statuses in place. It returns the readiness under the health policy.
+func (r *HealthMonitorReconciler) evaluateHealth(ctx context.Context, appDef *appv1.ApplicationDefinition) (bool, string) {
+	statuses := make(map[string]*appv1.ComponentStatusReference, len(appDef.Status.Components))
+	for i := range appDef.Status.Components {
+		status := appDef.Status.Components[i].DeepCopy()
+		statuses[status.Name] = status
+	}
+
+	reconcileStrategy, hasStrategy := strategy.GetAppReconcileStrategy(operatorComponentType)
+	for i := range appDef.Spec.Components {
+		appComp := &appDef.Spec.Components[i]
+		compStatus, found := statuses[appComp.Name]
+		if !found || compStatus.Kind == "" || compStatus.ResourceName == "" || compStatus.APIVersion == "" {
+			continue // Left to the spec reconciler, counted as unhealthy by the policy
+		}
+		compStatus.Reason = "" // Only errors of this evaluation are reported
+		namespace := compStatus.Namespace
+		if namespace == "" {
+			namespace = appDef.Namespace
+		}
+
+		healthy, message, err := kubeutil.CheckHealth(ctx, r.Client, r.Scheme, namespace, compStatus.ResourceName, compStatus.APIVersion, compStatus.Kind)
+		if err != nil {
+			compStatus.Health = false
+			compStatus.Reason = "K8sHealthCheckError"
+			compStatus.Message = err.Error()
+			continue
+		}
+		if !healthy {
+			compStatus.Health = false
+			compStatus.Message = message
+			setAppHealthyCondition(compStatus, metav1.ConditionUnknown, "WorkloadNotReady", "Waiting for the workload to become ready")
+			continue
+		}
+		if !hasStrategy {
+			compStatus.Health = true
+			compStatus.Message = "Component is ready and healthy"
+			continue
+		}
+
+		config, err := commonutil.UnmarshalAppSpecificConfig(operatorComponentType, appComp.Properties)
+		if err != nil {
+			compStatus.Health = false
+			compStatus.Reason = "ConfigUnmarshalFailed"
+			compStatus.Message = err.Error()
+			continue
+		}
+		appHealthy, appMessage, appErr := reconcileStrategy.CheckAppHealth(ctx, r.Client, r.Scheme, appDef, appComp, config)
+		switch {
+		case appErr != nil:
+			compStatus.Health = false
+			compStatus.Reason = "AppHealthCheckError"
+			compStatus.Message = appMessage
+			setAppHealthyCondition(compStatus, metav1.ConditionFalse, "AppHealthCheckError", appMessage)
+		case !appHealthy:
+			compStatus.Health = false
+			compStatus.Message = appMessage
+			setAppHealthyCondition(compStatus, metav1.ConditionFalse, "AppHealthCheckFailed", appMessage)
+		default:
+			compStatus.Health = true
+			compStatus.Message = "Component is ready and healthy"
+			setAppHealthyCondition(compStatus, metav1.ConditionTrue, "AppHealthCheckPassed", appMessage)
+		}
+	}
+
+	if err := refreshPodStatuses(ctx, r.Client, appDef, statuses); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to collect pod statuses")
+	}
+	appDef.Status.Components = mapToSliceComponentStatus(statuses)
+	return aggregateComponentHealth(appDef, statuses)
+}
+
+// applyHealthTransition moves a settled application between Running and Degraded according to its
+// readiness and refreshes the Ready condition. It returns the phase before and after.
+func applyHealthTransition(appDef *appv1.ApplicationDefinition, ready bool, summary string) (appv1.ApplicationPhase, appv1.ApplicationPhase) {
+	previous := appDef.Status.Phase
+	if ready {
+		appDef.Status.Phase = appv1.ApplicationPhaseRunning
+		setCondition(appDef, metav1.Condition{Type: string(appv1.ConditionReady), Status: metav1.ConditionTrue,
+			Reason: "ComponentsReady", Message: fmt.Sprintf("Components reconciled and healthy: %s", summary)})
+	} else {
+		appDef.Status.Phase = appv1.ApplicationPhaseDegraded
+		setCondition(appDef, metav1.Condition{Type: string(appv1.ConditionReady), Status: metav1.ConditionFalse,
+			Reason: "ComponentsDegraded", Message: fmt.Sprintf("One or more previously ready components are now unhealthy or not ready: %s", summary)})
+	}
+	return previous, appDef.Status.Phase
+}
+
+// SetupWithManager sets up the health monitor as a controller separate from spec reconciliation.
+func (r *HealthMonitorReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("applicationdefinition-health-monitor")
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("applicationdefinition-health").
+		For(&appv1.ApplicationDefinition{}).
+		Owns(&appsv1.StatefulSet{}).
+		Owns(&appsv1.Deployment{}).
+		Complete(r)
+}
@@ -0,0 +1,166 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+)
+
+var _ = Describe("Health monitor", func() {
+	runningApp := func() *appv1.ApplicationDefinition {
+		return &appv1.ApplicationDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "monitored", Namespace: common.Namespace, Generation: 2},
+			Spec: appv1.ApplicationDefinitionSpec{
+				Components: []appv1.ApplicationComponent{{Name: "web", Type: operatorComponentType}},
+			},
+			Status: appv1.ApplicationDefinitionStatus{
+				Phase:              appv1.ApplicationPhaseRunning,
+				ObservedGeneration: 2,
+				Components: []appv1.ComponentStatusReference{{
+					Name: "web", Kind: "StatefulSet", APIVersion: "apps/v1", ResourceName: "web", Health: true,
+				}},
+			},
+		}
+	}
+
+	It("only follows settled applications", func() {
+		Expect(healthMonitored(runningApp())).To(BeTrue())
+
+		pending := runningApp()
+		pending.Generation = 3
+		Expect(healthMonitored(pending)).To(BeFalse())
+
+		suspended := runningApp()
+		suspend := true
+		suspended.Spec.Suspend = &suspend
+		Expect(healthMonitored(suspended)).To(BeFalse())
+
+		creating := runningApp()
+		creating.Status.Phase = appv1.ApplicationPhaseCreating
+		Expect(healthMonitored(creating)).To(BeFalse())
+	})
+
+	It("moves a Running application to Degraded when its workload becomes unready", func() {
+		scheme := runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+		utilruntime.Must(appv1.AddToScheme(scheme))
+		replicas := int32(2)
+		sts := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: common.Namespace},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+			Status:     appsv1.StatefulSetStatus{Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 1},
+		}
+		app := runningApp()
+		c := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(app, sts).WithStatusSubresource(app).Build()
+		recorder := record.NewFakeRecorder(10)
+		monitor := &HealthMonitorReconciler{Client: c, Scheme: scheme, Recorder: recorder}
+
+		result, err := monitor.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(app)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">=", time.Minute))
+		Expect(result.RequeueAfter).To(BeNumerically("<=", time.Minute+12*time.Second))
+
+		updated := &appv1.ApplicationDefinition{}
+		Expect(c.Get(context.Background(), client.ObjectKeyFromObject(app), updated)).To(Succeed())
+		Expect(updated.Status.Phase).To(Equal(appv1.ApplicationPhaseDegraded))
+		ready := meta.FindStatusCondition(updated.Status.Conditions, string(appv1.ConditionReady))
+		Expect(ready).NotTo(BeNil())
+		Expect(ready.Reason).To(Equal("ComponentsDegraded"))
+		Expect(updated.Status.Components[0].Health).To(BeFalse())
+		Expect(recorder.Events).To(Receive(ContainSubstring("ApplicationDegraded")))
+	})
+
+	It("keeps the health and webhook status written while the spec reconciler's status update conflicts", func() {
+		scheme := runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+		utilruntime.Must(appv1.AddToScheme(scheme))
+		app := runningApp()
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(app).WithStatusSubresource(app).Build()
+		ctx := context.Background()
+
+		stale := &appv1.ApplicationDefinition{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(app), stale)).To(Succeed())
+		original := stale.Status.DeepCopy()
+
+		// The health monitor and the webhook dispatcher update the status meanwhile
+		latest := stale.DeepCopy()
+		applyHealthTransition(latest, false, "0/1 components healthy")
+		latest.Status.Webhook = &appv1.WebhookDeliveryStatus{Pending: 2, LastError: "connection refused"}
+		Expect(c.Status().Update(ctx, latest)).To(Succeed())
+
+		stale.Status.ConfigChecksums = map[string]string{"web": "abc"}
+		reconciler := &ApplicationDefinitionReconciler{Client: c, Scheme: scheme}
+		updated, err := reconciler.updateStatusIfNeeded(ctx, stale, original)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated).To(BeTrue())
+
+		stored := &appv1.ApplicationDefinition{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(app), stored)).To(Succeed())
+		Expect(stored.Status.ConfigChecksums).To(HaveKeyWithValue("web", "abc"))
+		Expect(stored.Status.Phase).To(Equal(appv1.ApplicationPhaseDegraded))
+		Expect(meta.FindStatusCondition(stored.Status.Conditions, string(appv1.ConditionReady)).Reason).To(Equal("ComponentsDegraded"))
+		Expect(stored.Status.Webhook).NotTo(BeNil())
+		Expect(stored.Status.Webhook.Pending).To(Equal(int32(2)))
+
+		// A phase changed by the reconcile itself wins
+		stale = stored.DeepCopy()
+		original = stale.Status.DeepCopy()
+		latest = stored.DeepCopy()
+		latest.Status.Webhook.Pending = 0
+		Expect(c.Status().Update(ctx, latest)).To(Succeed())
+		stale.Status.Phase = appv1.ApplicationPhaseUpdateing
+		_, err = reconciler.updateStatusIfNeeded(ctx, stale, original)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(app), stored)).To(Succeed())
+		Expect(stored.Status.Phase).To(Equal(appv1.ApplicationPhaseUpdateing))
+		Expect(stored.Status.Webhook.Pending).To(BeZero())
+	})
+
+	It("moves a Degraded application back to Running once healthy", func() {
+		app := runningApp()
+		app.Status.Phase = appv1.ApplicationPhaseDegraded
+		previous, current := applyHealthTransition(app, true, "1/1 components healthy")
+		Expect(previous).To(Equal(appv1.ApplicationPhaseDegraded))
+		Expect(current).To(Equal(appv1.ApplicationPhaseRunning))
+		Expect(meta.IsStatusConditionTrue(app.Status.Conditions, string(appv1.ConditionReady))).To(BeTrue())
+	})
+})
@@ -0,0 +1,72 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/app/health_policy.go
+package app
+
+import (
+	"fmt"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+)
+
+// isComponentRequired reports whether a component counts under the Required health policy (default true).
+func isComponentRequired(comp *appv1.ApplicationComponent) bool {
+	return comp.Required == nil || *comp.Required
+}
+
+// aggregateComponentHealth applies the application's health policy to the per-component health results.
+// It returns whether the application is ready and a short summary for the Ready condition.
+func aggregateComponentHealth(appDef *appv1.ApplicationDefinition, statuses map[string]*appv1.ComponentStatusReference) (bool, string) {
+	policy := appDef.Spec.HealthPolicy
+	if policy == "" {
+		policy = appv1.HealthPolicyAll
+	}
+
+	total, healthy := 0, 0
+	requiredTotal, requiredHealthy := 0, 0
+	for i := range appDef.Spec.Components {
+		comp := &appDef.Spec.Components[i]
+		compHealthy := statuses[comp.Name] != nil && statuses[comp.Name].Health
+		total++
+		if compHealthy {
+			healthy++
+		}
+		if isComponentRequired(comp) {
+			requiredTotal++
+			if compHealthy {
+				requiredHealthy++
+			}
+		}
+	}
+
+	switch policy {
+	case appv1.HealthPolicyQuorum:
+		return healthy*2 > total, fmt.Sprintf("%d/%d components healthy (policy %s)", healthy, total, policy)
+	case appv1.HealthPolicyRequired:
+		return requiredHealthy == requiredTotal, fmt.Sprintf("%d/%d required components healthy, %d/%d overall (policy %s)",
+			requiredHealthy, requiredTotal, healthy, total, policy)
+	default:
+		return healthy == total, fmt.Sprintf("%d/%d components healthy (policy %s)", healthy, total, appv1.HealthPolicyAll)
+	}
+}
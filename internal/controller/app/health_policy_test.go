@@ -0,0 +1,71 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+)
+
+var _ = Describe("Component health aggregation", func() {
+	optional := false
+	appDef := func(policy appv1.HealthPolicy) *appv1.ApplicationDefinition {
+		return &appv1.ApplicationDefinition{Spec: appv1.ApplicationDefinitionSpec{
+			HealthPolicy: policy,
+			Components: []appv1.ApplicationComponent{
+				{Name: "gateway"},
+				{Name: "console", Required: &optional},
+				{Name: "agent"},
+			},
+		}}
+	}
+	statuses := map[string]*appv1.ComponentStatusReference{
+		"gateway": {Name: "gateway", Health: true},
+		"console": {Name: "console", Health: false},
+		"agent":   {Name: "agent", Health: true},
+	}
+
+	It("requires all components by default", func() {
+		ready, _ := aggregateComponentHealth(appDef(""), statuses)
+		Expect(ready).To(BeFalse())
+	})
+
+	It("is ready with a majority under Quorum", func() {
+		ready, summary := aggregateComponentHealth(appDef(appv1.HealthPolicyQuorum), statuses)
+		Expect(ready).To(BeTrue())
+		Expect(summary).To(ContainSubstring("2/3"))
+	})
+
+	It("ignores optional components under Required", func() {
+		ready, _ := aggregateComponentHealth(appDef(appv1.HealthPolicyRequired), statuses)
+		Expect(ready).To(BeTrue())
+
+		statuses["agent"].Health = false
+		defer func() { statuses["agent"].Health = true }()
+		ready, _ = aggregateComponentHealth(appDef(appv1.HealthPolicyRequired), statuses)
+		Expect(ready).To(BeFalse())
+	})
+})
@@ -0,0 +1,237 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/app/metrics.go
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/internal/controller/common/reconcileerr"
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+)
+
+// reconcileOutcomeSuccess is the outcome reason of reconciles that ended without error.
+const reconcileOutcomeSuccess = "Success"
+
+// Results of applying an object, as reported by the apply operations metric.
+const (
+	applyResultCreated   = "created"
+	applyResultUpdated   = "updated"
+	applyResultUnchanged = "unchanged"
+	applyResultConflict  = "conflict"
+	applyResultError     = "error"
+)
+
+// collectTimeout bounds the cache reads of a scrape.
+const collectTimeout = 5 * time.Second
+
+var (
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "runtime_operator_reconcile_duration_seconds",
+		Help:    "Duration of ApplicationDefinition reconciles, by outcome reason.",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 12),
+	}, []string{"reason"})
+	applyOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "runtime_operator_apply_operations_total",
+		Help: "Number of objects applied, by kind and result.",
+	}, []string{"kind", "result"})
+
+	applicationPhaseDesc = prometheus.NewDesc("runtime_operator_application_phase",
+		"Phase of the application; 1 for its current phase, 0 for the others.",
+		[]string{"namespace", "application", "phase"}, nil)
+	applicationSuspendedDesc = prometheus.NewDesc("runtime_operator_application_suspended",
+		"Whether the application is suspended.",
+		[]string{"namespace", "application"}, nil)
+	componentHealthyDesc = prometheus.NewDesc("runtime_operator_component_healthy",
+		"Whether the component is healthy, as reported in the application status.",
+		[]string{"namespace", "application", "component"}, nil)
+	componentReadyReplicasDesc = prometheus.NewDesc("runtime_operator_component_ready_replicas",
+		"Number of ready replicas of the component's workload.",
+		[]string{"namespace", "application", "component"}, nil)
+	componentDesiredReplicasDesc = prometheus.NewDesc("runtime_operator_component_desired_replicas",
+		"Number of desired replicas of the component's workload.",
+		[]string{"namespace", "application", "component"}, nil)
+)
+
+// applicationPhases are the phases reported by the application phase metric.
+var applicationPhases = []appv1.ApplicationPhase{
+	appv1.ApplicationPhasePending,
+	appv1.ApplicationPhaseCreating,
+	appv1.ApplicationPhaseUpdateing,
+	appv1.ApplicationPhaseRunning,
+	appv1.ApplicationPhaseDegraded,
+	appv1.ApplicationPhaseSuspended,
+	appv1.ApplicationPhaseDeleting,
+	appv1.ApplicationPhaseFailed,
+}
+
+func init() {
+	metrics.Registry.MustRegister(reconcileDuration, applyOperations)
+}
+
+// observeReconcile records the duration of a reconcile that started at start and ended with err, if not nil.
+func observeReconcile(start time.Time, err error) {
+	reason := reconcileOutcomeSuccess
+	if err != nil {
+		reason = reconcileerr.ReasonOf(err)
+	}
+	reconcileDuration.WithLabelValues(reason).Observe(time.Since(start).Seconds())
+}
+
+// countApply records the outcome of applying an object of the given kind.
+func countApply(kind string, operation controllerutil.OperationResult, conflicted bool, err error) {
+	result := applyResultUnchanged
+	switch {
+	case conflicted:
+		result = applyResultConflict
+	case err != nil:
+		result = applyResultError
+	case operation == controllerutil.OperationResultCreated:
+		result = applyResultCreated
+	case operation == controllerutil.OperationResultUpdated:
+		result = applyResultUpdated
+	}
+	applyOperations.WithLabelValues(kind, result).Inc()
+}
+
+// ApplicationCollector reports the state of the applications and their components from the status
+// the controllers wrote and the workloads they manage. It reads from the manager's cache on every
+// scrape, so deleted applications and components disappear from the metrics with them.
+type ApplicationCollector struct {
+	reader client.Reader
+}
+
+var _ prometheus.Collector = &ApplicationCollector{}
+
+// NewApplicationCollector returns a collector reading applications and workloads with reader,
+// usually the manager's client.
+func NewApplicationCollector(reader client.Reader) *ApplicationCollector {
+	return &ApplicationCollector{reader: reader}
+}
+
+// Describe implements prometheus.Collector.
+func (c *ApplicationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- applicationPhaseDesc
+	ch <- applicationSuspendedDesc
+	ch <- componentHealthyDesc
+	ch <- componentReadyReplicasDesc
+	ch <- componentDesiredReplicasDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *ApplicationCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	apps := &appv1.ApplicationDefinitionList{}
+	if err := c.reader.List(ctx, apps, client.InNamespace(common.Namespace)); err != nil {
+		log.Log.WithName("metrics").V(1).Info("Failed to list applications for metrics", "error", err.Error())
+		return
+	}
+	for i := range apps.Items {
+		c.collectApplication(ctx, ch, &apps.Items[i])
+	}
+}
+
+// collectApplication reports the metrics of one application and its components.
+func (c *ApplicationCollector) collectApplication(ctx context.Context, ch chan<- prometheus.Metric, appDef *appv1.ApplicationDefinition) {
+	for _, phase := range applicationPhases {
+		ch <- prometheus.MustNewConstMetric(applicationPhaseDesc, prometheus.GaugeValue,
+			boolValue(appDef.Status.Phase == phase), appDef.Namespace, appDef.Name, string(phase))
+	}
+	suspended := appDef.Spec.Suspend != nil && *appDef.Spec.Suspend
+	ch <- prometheus.MustNewConstMetric(applicationSuspendedDesc, prometheus.GaugeValue,
+		boolValue(suspended), appDef.Namespace, appDef.Name)
+
+	for i := range appDef.Status.Components {
+		compStatus := &appDef.Status.Components[i]
+		ch <- prometheus.MustNewConstMetric(componentHealthyDesc, prometheus.GaugeValue,
+			boolValue(compStatus.Health), appDef.Namespace, appDef.Name, compStatus.Name)
+
+		ready, desired, found := c.workloadReplicas(ctx, appDef, compStatus)
+		if !found {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(componentReadyReplicasDesc, prometheus.GaugeValue,
+			float64(ready), appDef.Namespace, appDef.Name, compStatus.Name)
+		ch <- prometheus.MustNewConstMetric(componentDesiredReplicasDesc, prometheus.GaugeValue,
+			float64(desired), appDef.Namespace, appDef.Name, compStatus.Name)
+	}
+}
+
+// workloadReplicas returns the ready and desired replicas of the component's primary workload.
+// found is false when the workload is not known yet, not found, or not a Deployment or StatefulSet.
+func (c *ApplicationCollector) workloadReplicas(ctx context.Context, appDef *appv1.ApplicationDefinition,
+	compStatus *appv1.ComponentStatusReference) (ready, desired int32, found bool) {
+	if compStatus.ResourceName == "" || compStatus.APIVersion != appsv1.SchemeGroupVersion.String() {
+		return 0, 0, false
+	}
+	namespace := compStatus.Namespace
+	if namespace == "" {
+		namespace = appDef.Namespace
+	}
+	key := client.ObjectKey{Namespace: namespace, Name: compStatus.ResourceName}
+
+	switch compStatus.Kind {
+	case "StatefulSet":
+		sts := &appsv1.StatefulSet{}
+		if err := c.reader.Get(ctx, key, sts); err != nil {
+			return 0, 0, false
+		}
+		return sts.Status.ReadyReplicas, replicasOrDefault(sts.Spec.Replicas), true
+	case "Deployment":
+		deployment := &appsv1.Deployment{}
+		if err := c.reader.Get(ctx, key, deployment); err != nil {
+			return 0, 0, false
+		}
+		return deployment.Status.ReadyReplicas, replicasOrDefault(deployment.Spec.Replicas), true
+	default:
+		return 0, 0, false
+	}
+}
+
+// replicasOrDefault returns the replicas of a workload spec, which default to 1 when unset.
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// boolValue returns 1 for true and 0 for false.
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,118 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/internal/controller/common/reconcileerr"
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+)
+
+var _ = Describe("Metrics", func() {
+	It("reports application phase, suspension, component health and replicas", func() {
+		s := runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(s))
+		utilruntime.Must(appv1.AddToScheme(s))
+		appDef := &appv1.ApplicationDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "search", Namespace: common.Namespace},
+			Spec:       appv1.ApplicationDefinitionSpec{Suspend: ptr.To(false)},
+			Status: appv1.ApplicationDefinitionStatus{
+				Phase: appv1.ApplicationPhaseDegraded,
+				Components: []appv1.ComponentStatusReference{
+					{Name: "gateway", Kind: "StatefulSet", APIVersion: "apps/v1", ResourceName: "search-gateway", Health: false},
+					{Name: "console", Health: true}, // Workload not identified yet
+				},
+			},
+		}
+		sts := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "search-gateway", Namespace: common.Namespace},
+			Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To(int32(3))},
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: 2},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(appDef, sts).Build()
+
+		expected := `
+# HELP runtime_operator_application_suspended Whether the application is suspended.
+# TYPE runtime_operator_application_suspended gauge
+runtime_operator_application_suspended{application="search",namespace="NS"} 0
+# HELP runtime_operator_component_desired_replicas Number of desired replicas of the component's workload.
+# TYPE runtime_operator_component_desired_replicas gauge
+runtime_operator_component_desired_replicas{application="search",component="gateway",namespace="NS"} 3
+# HELP runtime_operator_component_healthy Whether the component is healthy, as reported in the application status.
+# TYPE runtime_operator_component_healthy gauge
+runtime_operator_component_healthy{application="search",component="console",namespace="NS"} 1
+runtime_operator_component_healthy{application="search",component="gateway",namespace="NS"} 0
+# HELP runtime_operator_component_ready_replicas Number of ready replicas of the component's workload.
+# TYPE runtime_operator_component_ready_replicas gauge
+runtime_operator_component_ready_replicas{application="search",component="gateway",namespace="NS"} 2
+`
+		collector := NewApplicationCollector(c)
+		Expect(testutil.CollectAndCompare(collector, strings.NewReader(strings.ReplaceAll(expected, "NS", common.Namespace)),
+			"runtime_operator_application_suspended", "runtime_operator_component_healthy",
+			"runtime_operator_component_ready_replicas", "runtime_operator_component_desired_replicas")).To(Succeed())
+
+		By("reporting 1 for the current phase only")
+		Expect(testutil.CollectAndCount(collector, "runtime_operator_application_phase")).To(Equal(len(applicationPhases)))
+		Expect(testutil.CollectAndCompare(collector, strings.NewReader(strings.ReplaceAll(`
+# HELP runtime_operator_application_phase Phase of the application; 1 for its current phase, 0 for the others.
+# TYPE runtime_operator_application_phase gauge
+runtime_operator_application_phase{application="search",namespace="NS",phase="Creating"} 0
+runtime_operator_application_phase{application="search",namespace="NS",phase="Degraded"} 1
+runtime_operator_application_phase{application="search",namespace="NS",phase="Deleting"} 0
+runtime_operator_application_phase{application="search",namespace="NS",phase="Failed"} 0
+runtime_operator_application_phase{application="search",namespace="NS",phase="Pending"} 0
+runtime_operator_application_phase{application="search",namespace="NS",phase="Running"} 0
+runtime_operator_application_phase{application="search",namespace="NS",phase="Suspended"} 0
+runtime_operator_application_phase{application="search",namespace="NS",phase="Updating"} 0
+`, "NS", common.Namespace)), "runtime_operator_application_phase")).To(Succeed())
+	})
+
+	It("counts apply results and reconcile outcomes", func() {
+		conflicts := testutil.ToFloat64(applyOperations.WithLabelValues("Service", applyResultConflict))
+		created := testutil.ToFloat64(applyOperations.WithLabelValues("Service", applyResultCreated))
+		countApply("Service", controllerutil.OperationResultNone, true, errors.New("conflict"))
+		countApply("Service", controllerutil.OperationResultCreated, false, nil)
+		Expect(testutil.ToFloat64(applyOperations.WithLabelValues("Service", applyResultConflict))).To(Equal(conflicts + 1))
+		Expect(testutil.ToFloat64(applyOperations.WithLabelValues("Service", applyResultCreated))).To(Equal(created + 1))
+
+		observeReconcile(time.Now(), reconcileerr.Build("BuilderFailed", errors.New("boom")))
+		Expect(testutil.CollectAndCount(reconcileDuration, "runtime_operator_reconcile_duration_seconds")).To(BeNumerically(">=", 1))
+	})
+})
@@ -0,0 +1,233 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/app/notification_channel.go
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/pkg/webrecorder"
+)
+
+// --- Constants for the notification channel outboxes ---
+const (
+	// NotificationOutboxLabel marks the outbox ConfigMaps of notification channels. Its value is the channel name.
+	NotificationOutboxLabel = "infini.cloud/notification-outbox"
+	// NotificationOutboxSuffix is appended to the channel's name to name its outbox ConfigMap.
+	NotificationOutboxSuffix = "-notification-outbox"
+
+	// notifyTimeout bounds looking up the channels of an event and queuing it.
+	notifyTimeout = 10 * time.Second
+)
+
+// NewNotificationOutbox returns the outbox of notification channels, writing ConfigMaps with c and reading
+// them with reader. Its events are delivered by a webrecorder.Dispatcher reporting with ReportNotificationDelivery.
+func NewNotificationOutbox(c client.Client, reader client.Reader) *webrecorder.Outbox {
+	return webrecorder.NewOutboxFor(c, reader, NotificationOutboxLabel, NotificationOutboxSuffix)
+}
+
+// Notifier routes the events of applications to the NotificationChannels selected by their
+// notificationChannelSelector, whether or not a change ID is set. Events are filtered and rate
+// limited per channel, then queued in the channel's outbox.
+type Notifier struct {
+	client   client.Client
+	outbox   *webrecorder.Outbox
+	recorder record.EventRecorder
+
+	mu sync.Mutex
+	// limiters holds the rate limiter of each channel, for the generation it was built from.
+	limiters map[types.UID]*channelLimiter
+}
+
+// channelLimiter is the rate limiter of a channel generation.
+type channelLimiter struct {
+	generation int64
+	limiter    *rate.Limiter
+}
+
+// NewNotifier returns a notifier queuing events in outbox, see NewNotificationOutbox.
+// recorder, if set, records on the channels the events dropped by their rate limit.
+func NewNotifier(c client.Client, outbox *webrecorder.Outbox, recorder record.EventRecorder) *Notifier {
+	return &Notifier{client: c, outbox: outbox, recorder: recorder, limiters: map[types.UID]*channelLimiter{}}
+}
+
+// Notify queues an event about app, described by annotations, for the channels it selects. summary is
+// only set on the terminal event of a change. With dedupe, an event already queued for a channel within
+// the event TTL is skipped.
+func (n *Notifier) Notify(app *appv1.ApplicationDefinition, annotations map[string]string, change *webrecorder.ResourceChange, summary *webrecorder.ChangeSummary, dedupe bool, eventType, reason, message string) {
+	if n == nil || app == nil || app.Spec.NotificationChannelSelector == nil {
+		return
+	}
+	logger := log.Log.WithName("notifier").WithValues("application", client.ObjectKeyFromObject(app))
+	selector, err := metav1.LabelSelectorAsSelector(app.Spec.NotificationChannelSelector)
+	if err != nil {
+		logger.Error(err, "Ignoring invalid notification channel selector")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	channels := &appv1.NotificationChannelList{}
+	if err := n.client.List(ctx, channels, client.InNamespace(app.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		logger.Error(err, "Failed to list notification channels")
+		return
+	}
+	if len(channels.Items) == 0 {
+		return
+	}
+
+	changeID := app.Annotations[appv1.AnnotationChangeID]
+	event := webrecorder.NewEvent(app, annotations, change, changeID, app.Annotations[appv1.AnnotationClusterID], eventType, reason, message)
+	event.Summary = summary
+	var key string
+	if dedupe {
+		// Channels receive the events of several applications, keep them apart
+		key = webrecorder.EventKey(string(app.UID)+"/"+changeID, event.Phase, event.Step, event.Status, reason, message)
+	}
+	for i := range channels.Items {
+		channel := &channels.Items[i]
+		if !channelAccepts(&channel.Spec.Filter, event) {
+			continue
+		}
+		if !n.allow(channel) {
+			logger.V(1).Info("Dropping event over the channel's rate limit", "channel", channel.Name, "reason", reason)
+			if n.recorder != nil {
+				n.recorder.Eventf(channel, corev1.EventTypeWarning, "RateLimited",
+					"Dropped event %s of application %s: over %d events per minute", reason, app.Name, channel.Spec.RateLimit.EventsPerMinute)
+			}
+			continue
+		}
+		if _, err := n.outbox.Enqueue(ctx, channel, channelEndpoint(channel), key, event); err != nil {
+			logger.Error(err, "Failed to queue event for notification channel", "channel", channel.Name)
+		}
+	}
+}
+
+// allow reports whether the channel's rate limit lets one more event through.
+func (n *Notifier) allow(channel *appv1.NotificationChannel) bool {
+	limit := channel.Spec.RateLimit
+	if limit == nil {
+		return true
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	entry, ok := n.limiters[channel.UID]
+	if !ok || entry.generation != channel.Generation {
+		burst := int(limit.Burst)
+		if burst <= 0 {
+			burst = int(limit.EventsPerMinute)
+		}
+		entry = &channelLimiter{
+			generation: channel.Generation,
+			limiter:    rate.NewLimiter(rate.Limit(float64(limit.EventsPerMinute)/60), burst),
+		}
+		n.limiters[channel.UID] = entry
+	}
+	return entry.limiter.Allow()
+}
+
+// channelAccepts reports whether the event passes the channel's filter.
+func channelAccepts(filter *appv1.NotificationFilter, event *webrecorder.WebhookEvent) bool {
+	matches := func(values []string, value string) bool {
+		return len(values) == 0 || slices.Contains(values, value)
+	}
+	return matches(filter.Phases, event.Phase) && matches(filter.Statuses, event.Status) && matches(filter.Levels, event.Level)
+}
+
+// channelEndpoint returns where and how the channel's events are sent. Unset fields use the operator's defaults.
+func channelEndpoint(channel *appv1.NotificationChannel) webrecorder.Endpoint {
+	endpoint := webrecorder.Endpoint{URL: channel.Spec.URL, Format: webrecorder.Format(channel.Spec.Format)}
+	if ref := channel.Spec.SigningSecret; ref != nil {
+		key := ref.Key
+		if key == "" {
+			key = appv1.DefaultWebhookSecretKey
+		}
+		endpoint.SigningSecret = &webrecorder.SigningSecret{Namespace: channel.Namespace, Name: ref.Name, Key: key}
+	}
+	return endpoint
+}
+
+// ReportNotificationDelivery returns a webrecorder.ReportFunc that mirrors the outbox of a
+// NotificationChannel into its status, with a Delivering condition.
+func ReportNotificationDelivery(c client.Client) webrecorder.ReportFunc {
+	return func(ctx context.Context, report webrecorder.DeliveryReport) error {
+		channel := &appv1.NotificationChannel{}
+		if err := c.Get(ctx, report.Owner, channel); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		desired := notificationChannelStatus(channel, report)
+		if equality.Semantic.DeepEqual(channel.Status, desired) {
+			return nil
+		}
+		patch := client.MergeFrom(channel.DeepCopy())
+		channel.Status = desired
+		return client.IgnoreNotFound(c.Status().Patch(ctx, channel, patch))
+	}
+}
+
+// notificationChannelStatus converts a delivery report into the channel's status.
+func notificationChannelStatus(channel *appv1.NotificationChannel, report webrecorder.DeliveryReport) appv1.NotificationChannelStatus {
+	status := appv1.NotificationChannelStatus{
+		ObservedGeneration: channel.Generation,
+		Conditions:         slices.Clone(channel.Status.Conditions),
+	}
+	if delivery := webhookDeliveryStatus(report); delivery != nil {
+		status.Pending, status.LastError, status.DeadLetters = delivery.Pending, delivery.LastError, delivery.DeadLetters
+	}
+
+	condition := metav1.Condition{
+		Type:               string(appv1.ConditionDelivering),
+		Status:             metav1.ConditionTrue,
+		Reason:             "Delivered",
+		Message:            "All events were delivered",
+		ObservedGeneration: channel.Generation,
+	}
+	switch {
+	case report.LastError != "":
+		condition.Status, condition.Reason = metav1.ConditionFalse, "DeliveryFailing"
+		condition.Message = fmt.Sprintf("%d events pending: %s", report.Pending, report.LastError)
+	case report.Pending > 0:
+		condition.Reason, condition.Message = "Pending", fmt.Sprintf("%d events pending", report.Pending)
+	case len(report.DeadLetters) > 0:
+		condition.Reason = "DeadLettered"
+		condition.Message = fmt.Sprintf("%d events were given up on after the retry limit", len(report.DeadLetters))
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+	return status
+}
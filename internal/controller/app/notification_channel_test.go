@@ -0,0 +1,186 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/pkg/webrecorder"
+)
+
+var _ = Describe("Notification channels", func() {
+	var (
+		ctx      context.Context
+		c        client.Client
+		recorder *record.FakeRecorder
+		notifier *Notifier
+		appDef   *appv1.ApplicationDefinition
+	)
+
+	newChannel := func(name, team string, spec appv1.NotificationChannelSpec) *appv1.NotificationChannel {
+		spec.URL = "https://hooks.example.com/" + name
+		return &appv1.NotificationChannel{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps", UID: types.UID("uid-" + name), Labels: map[string]string{"team": team}},
+			Spec:       spec,
+		}
+	}
+
+	// queued returns the events in the outbox of the named channel.
+	queued := func(channel string) []webrecorder.OutboxEntry {
+		cm := &corev1.ConfigMap{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: "apps", Name: channel + NotificationOutboxSuffix}, cm); err != nil {
+			return nil
+		}
+		Expect(cm.Labels).To(HaveKeyWithValue(NotificationOutboxLabel, channel))
+		var entries []webrecorder.OutboxEntry
+		if data := cm.Data["entries.json"]; data != "" {
+			Expect(json.Unmarshal([]byte(data), &entries)).To(Succeed())
+		}
+		return entries
+	}
+
+	notify := func(dedupe bool, eventType, status, message string) {
+		notifier.Notify(appDef, webhookAnnotations(healthPhase, status, healthStep), nil, nil, dedupe, eventType, "ApplicationDegraded", message)
+	}
+
+	setup := func(channels ...*appv1.NotificationChannel) {
+		s := runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(s))
+		utilruntime.Must(appv1.AddToScheme(s))
+		builder := fake.NewClientBuilder().WithScheme(s)
+		for _, channel := range channels {
+			builder = builder.WithObjects(channel).WithStatusSubresource(channel)
+		}
+		c = builder.Build()
+		recorder = record.NewFakeRecorder(10)
+		notifier = NewNotifier(c, NewNotificationOutbox(c, c), recorder)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		appDef = &appv1.ApplicationDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "search", Namespace: "apps", UID: "uid-search"},
+			Spec: appv1.ApplicationDefinitionSpec{
+				NotificationChannelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "ops"}},
+			},
+		}
+	})
+
+	It("queues events without a change ID for the selected channels whose filter they pass", func() {
+		setup(
+			newChannel("ops-warnings", "ops", appv1.NotificationChannelSpec{
+				Format: appv1.NotificationFormatCloudEvents,
+				Filter: appv1.NotificationFilter{Levels: []string{corev1.EventTypeWarning}},
+			}),
+			newChannel("ops-all", "ops", appv1.NotificationChannelSpec{
+				SigningSecret: &appv1.NotificationSecretReference{Name: "signing"},
+			}),
+			newChannel("dev", "dev", appv1.NotificationChannelSpec{}),
+		)
+
+		notify(false, corev1.EventTypeWarning, webrecorder.StatusFailure, "Application is degraded")
+		notify(false, corev1.EventTypeNormal, webrecorder.StatusSuccess, "Application recovered")
+
+		warnings := queued("ops-warnings")
+		Expect(warnings).To(HaveLen(1))
+		Expect(warnings[0].URL).To(Equal("https://hooks.example.com/ops-warnings"))
+		Expect(warnings[0].Format).To(Equal(webrecorder.FormatCloudEvents))
+		Expect(warnings[0].Event.Application).To(Equal("search"))
+		Expect(warnings[0].Event.ChangeID).To(BeEmpty())
+		Expect(warnings[0].Event.Status).To(Equal(webrecorder.StatusFailure))
+
+		all := queued("ops-all")
+		Expect(all).To(HaveLen(2))
+		Expect(all[0].SigningSecret).To(Equal(&webrecorder.SigningSecret{Namespace: "apps", Name: "signing", Key: appv1.DefaultWebhookSecretKey}))
+
+		Expect(queued("dev")).To(BeEmpty())
+	})
+
+	It("sends nothing when the application selects no channel", func() {
+		setup(newChannel("ops-all", "ops", appv1.NotificationChannelSpec{}))
+		appDef.Spec.NotificationChannelSelector = nil
+
+		notify(false, corev1.EventTypeWarning, webrecorder.StatusFailure, "Application is degraded")
+		Expect(queued("ops-all")).To(BeEmpty())
+	})
+
+	It("skips deduplicated repeats but not undeduplicated ones", func() {
+		setup(newChannel("ops-all", "ops", appv1.NotificationChannelSpec{}))
+
+		notify(true, corev1.EventTypeNormal, webrecorder.StatusInProgress, "Reconciliation in progress")
+		notify(true, corev1.EventTypeNormal, webrecorder.StatusInProgress, "Reconciliation in progress")
+		Expect(queued("ops-all")).To(HaveLen(1))
+
+		notify(false, corev1.EventTypeWarning, webrecorder.StatusFailure, "Application is degraded")
+		notify(false, corev1.EventTypeWarning, webrecorder.StatusFailure, "Application is degraded")
+		Expect(queued("ops-all")).To(HaveLen(3))
+	})
+
+	It("drops events over the channel's rate limit and records it on the channel", func() {
+		setup(newChannel("ops-all", "ops", appv1.NotificationChannelSpec{
+			RateLimit: &appv1.NotificationRateLimit{EventsPerMinute: 1},
+		}))
+
+		notify(false, corev1.EventTypeWarning, webrecorder.StatusFailure, "Application is degraded")
+		notify(false, corev1.EventTypeWarning, webrecorder.StatusFailure, "Application is degraded")
+		Expect(queued("ops-all")).To(HaveLen(1))
+		Expect(recorder.Events).To(Receive(ContainSubstring("RateLimited")))
+	})
+
+	It("reports the delivery on the channel's status", func() {
+		channel := newChannel("ops-all", "ops", appv1.NotificationChannelSpec{})
+		channel.Generation = 2
+		setup(channel)
+		report := ReportNotificationDelivery(c)
+		key := client.ObjectKeyFromObject(channel)
+
+		Expect(report(ctx, webrecorder.DeliveryReport{Owner: key, Pending: 3, LastError: "connection refused"})).To(Succeed())
+		Expect(c.Get(ctx, key, channel)).To(Succeed())
+		Expect(channel.Status.ObservedGeneration).To(Equal(int64(2)))
+		Expect(channel.Status.Pending).To(Equal(int32(3)))
+		Expect(channel.Status.LastError).To(Equal("connection refused"))
+		Expect(meta.IsStatusConditionFalse(channel.Status.Conditions, string(appv1.ConditionDelivering))).To(BeTrue())
+
+		Expect(report(ctx, webrecorder.DeliveryReport{Owner: key})).To(Succeed())
+		Expect(c.Get(ctx, key, channel)).To(Succeed())
+		Expect(channel.Status.Pending).To(BeZero())
+		Expect(channel.Status.LastError).To(BeEmpty())
+		Expect(meta.IsStatusConditionTrue(channel.Status.Conditions, string(appv1.ConditionDelivering))).To(BeTrue())
+	})
+})
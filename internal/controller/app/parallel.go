@@ -0,0 +1,109 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/app/parallel.go
+package app
+
+import (
+	"context"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/infinilabs/runtime-operator/api/config/v1alpha1"
+)
+
+// defaultMaxConcurrency bounds the number of components built, and objects applied, at the same time
+// when the reconciler is not configured with an explicit limit.
+const defaultMaxConcurrency = configv1alpha1.DefaultMaxConcurrency
+
+// Apply waves. Objects in the same wave are applied in parallel; a wave only starts once the
+// previous one finished, so configuration and identities exist before the workloads consuming them.
+const (
+	applyWaveConfig    = iota // ServiceAccounts, ConfigMaps, Secrets, PVCs and RBAC
+	applyWaveNetwork          // Services, PDBs, Ingresses and any other supporting object
+	applyWaveWorkloads        // Deployments, StatefulSets, DaemonSets, Jobs
+	applyWaveCount
+)
+
+// maxConcurrency returns the configured worker limit, falling back to the default.
+func (r *ApplicationDefinitionReconciler) maxConcurrency() int {
+	if r.MaxConcurrency > 0 {
+		return r.MaxConcurrency
+	}
+	return defaultMaxConcurrency
+}
+
+// runBounded calls fn for every index in [0, n) using at most limit goroutines and waits for all of them.
+// fn must only write to per-index state; callers merge results afterwards in index order.
+func runBounded(ctx context.Context, limit, n int, fn func(ctx context.Context, i int)) {
+	if n == 0 {
+		return
+	}
+	if limit <= 0 || limit > n {
+		limit = n
+	}
+	if limit == 1 {
+		for i := 0; i < n; i++ {
+			fn(ctx, i)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, limit)
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// applyWave returns the wave an object is applied in, based on its kind.
+func applyWave(obj client.Object) int {
+	switch obj.GetObjectKind().GroupVersionKind().Kind {
+	case "ServiceAccount", "ConfigMap", "Secret", "PersistentVolumeClaim",
+		"Role", "RoleBinding", "ClusterRole", "ClusterRoleBinding":
+		return applyWaveConfig
+	case "Deployment", "StatefulSet", "DaemonSet", "Job", "CronJob":
+		return applyWaveWorkloads
+	default:
+		return applyWaveNetwork
+	}
+}
+
+// groupIntoApplyWaves splits objects into apply waves, keeping their original order inside each wave.
+// The returned indices refer to positions in objects.
+func groupIntoApplyWaves(objects []client.Object) [applyWaveCount][]int {
+	var waves [applyWaveCount][]int
+	for i, obj := range objects {
+		wave := applyWave(obj)
+		waves[wave] = append(waves[wave], i)
+	}
+	return waves
+}
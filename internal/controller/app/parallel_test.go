@@ -0,0 +1,74 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"context"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Parallel apply helpers", func() {
+	typed := func(obj client.Object, kind string) client.Object {
+		obj.GetObjectKind().SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind(kind))
+		return obj
+	}
+
+	It("applies config before services and services before workloads", func() {
+		objects := []client.Object{
+			typed(&appsv1.StatefulSet{}, "StatefulSet"),
+			typed(&corev1.Service{}, "Service"),
+			typed(&corev1.ConfigMap{}, "ConfigMap"),
+			typed(&corev1.ServiceAccount{}, "ServiceAccount"),
+			typed(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "headless"}}, "Service"),
+		}
+		waves := groupIntoApplyWaves(objects)
+		Expect(waves[applyWaveConfig]).To(Equal([]int{2, 3}))
+		Expect(waves[applyWaveNetwork]).To(Equal([]int{1, 4}))
+		Expect(waves[applyWaveWorkloads]).To(Equal([]int{0}))
+	})
+
+	It("never runs more workers than the limit", func() {
+		var running, peak, calls int32
+		runBounded(context.Background(), 2, 10, func(_ context.Context, _ int) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			atomic.AddInt32(&calls, 1)
+			atomic.AddInt32(&running, -1)
+		})
+		Expect(calls).To(BeEquivalentTo(10))
+		Expect(peak).To(BeNumerically("<=", 2))
+	})
+})
@@ -0,0 +1,409 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/app/plan.go
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/internal/controller/common/kubeutil"
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+	"github.com/infinilabs/runtime-operator/pkg/webrecorder"
+)
+
+const (
+	planConfigMapSuffix = "-plan"
+	planDataKey         = "plan.yaml"
+	planHashKey         = "hash"
+
+	planPhase = "Plan"
+	planStep  = "DryRun"
+)
+
+// planAction is what the apply would do with an object.
+type planAction string
+
+const (
+	planActionCreate    planAction = "Create"
+	planActionUpdate    planAction = "Update"
+	planActionUnchanged planAction = "Unchanged"
+	// planActionOrphaned marks objects owned by the application that are no longer desired.
+	// The apply leaves them in place; they are removed with the application.
+	planActionOrphaned planAction = "Orphaned"
+)
+
+// planEntry describes the planned change of a single object.
+type planEntry struct {
+	Resource  string     `json:"resource"`
+	Component string     `json:"component,omitempty"`
+	Action    planAction `json:"action"`
+	// Digest is the content digest of the desired object, so approving a plan approves its values
+	// and not only the paths they change.
+	Digest       string   `json:"digest,omitempty"`
+	ChangedPaths []string `json:"changedPaths,omitempty"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// dryRunEnabled reports whether the application asks for changes to be planned before they are applied.
+func dryRunEnabled(appDef *appv1.ApplicationDefinition) bool {
+	return strings.EqualFold(appDef.Annotations[appv1.AnnotationDryRun], "true")
+}
+
+// handleDryRun computes the change plan of the built objects and stores it in status and in the plan ConfigMap.
+// It returns true when the apply may continue: the plan has no changes or its hash was approved.
+func (r *ApplicationDefinitionReconciler) handleDryRun(ctx context.Context, state *reconcileState) (bool, error) {
+	logger := log.FromContext(ctx)
+	appDef := state.appDef
+
+	entries, err := r.computePlan(ctx, state)
+	if err != nil {
+		return false, fmt.Errorf("failed to compute plan: %w", err)
+	}
+	hash, err := planHash(entries)
+	if err != nil {
+		return false, err
+	}
+	configMapName := appDef.Name + planConfigMapSuffix
+	if err := r.writePlanConfigMap(ctx, appDef, configMapName, entries, hash); err != nil {
+		return false, err
+	}
+
+	previous := appDef.Status.Plan
+	plan := summarizePlan(entries, hash)
+	plan.ConfigMapName = configMapName
+	if previous != nil && previous.Hash == hash {
+		plan.GeneratedAt = previous.GeneratedAt
+	} else {
+		now := metav1.Now()
+		plan.GeneratedAt = &now
+	}
+	hasChanges := plan.Creates > 0 || plan.Updates > 0 || plan.Errors > 0
+	approved := hasChanges && appDef.Annotations[appv1.AnnotationApprovedPlanHash] == hash
+	plan.Approved = approved
+	appDef.Status.Plan = plan
+
+	switch {
+	case !hasChanges:
+		logger.V(1).Info("Dry-run plan has no changes", "hash", hash)
+		return true, nil
+	case approved:
+		logger.Info("Dry-run plan approved, applying", "hash", hash)
+		return true, nil
+	}
+
+	if previous == nil || previous.Hash != hash {
+		logger.Info("Dry-run plan pending approval", "hash", hash, "creates", plan.Creates, "updates", plan.Updates, "errors", plan.Errors)
+		r.recordEventf(appDef, planPhase, webrecorder.StatusInProgress, planStep, corev1.EventTypeNormal, "PlanPending",
+			"Plan %s pending approval: %d to create, %d to update, %d rejected; see ConfigMap %s",
+			hash, plan.Creates, plan.Updates, plan.Errors, configMapName)
+	}
+	return false, nil
+}
+
+// clearPlan removes the plan of an application that left dry-run mode.
+func (r *ApplicationDefinitionReconciler) clearPlan(ctx context.Context, state *reconcileState) error {
+	plan := state.appDef.Status.Plan
+	if plan == nil {
+		return nil
+	}
+	if plan.ConfigMapName != "" {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: plan.ConfigMapName, Namespace: state.appDef.Namespace}}
+		if err := r.Client.Delete(ctx, cm); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete plan ConfigMap %s: %w", plan.ConfigMapName, err)
+		}
+	}
+	state.appDef.Status.Plan = nil
+	return nil
+}
+
+// computePlan performs a server-side dry-run apply of every desired object and compares the result
+// with the live object, then adds the owned objects that are no longer desired.
+func (r *ApplicationDefinitionReconciler) computePlan(ctx context.Context, state *reconcileState) ([]planEntry, error) {
+	entries := make([]planEntry, len(state.desiredObjects))
+	errs := make([]error, len(state.desiredObjects))
+	runBounded(ctx, r.maxConcurrency(), len(entries), func(ctx context.Context, i int) {
+		entries[i], errs[i] = r.planObject(ctx, state.appDef, state.desiredObjects[i])
+	})
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	orphans, err := r.findOrphanedObjects(ctx, state.appDef, entries)
+	if err != nil {
+		return nil, err
+	}
+	return append(entries, orphans...), nil
+}
+
+// planObject plans a single object. The desired object itself is left untouched.
+func (r *ApplicationDefinitionReconciler) planObject(ctx context.Context, appDef *appv1.ApplicationDefinition, obj client.Object) (planEntry, error) {
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+	if err != nil {
+		return planEntry{}, fmt.Errorf("failed to get GVK of %s: %w", obj.GetName(), err)
+	}
+	entry := planEntry{
+		Resource:  fmt.Sprintf("%s/%s", gvk.Kind, obj.GetName()),
+		Component: obj.GetLabels()[compInstanceLabel],
+		Action:    planActionUnchanged,
+	}
+
+	desiredMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return planEntry{}, fmt.Errorf("failed to convert desired %s: %w", entry.Resource, err)
+	}
+	if entry.Digest, err = contentDigest(desiredMap); err != nil {
+		return planEntry{}, fmt.Errorf("failed to digest desired %s: %w", entry.Resource, err)
+	}
+
+	liveObj, err := r.Scheme.New(gvk)
+	if err != nil {
+		return planEntry{}, fmt.Errorf("failed to create live object for %s: %w", entry.Resource, err)
+	}
+	live, ok := liveObj.(client.Object)
+	if !ok {
+		return planEntry{}, fmt.Errorf("%s is not a client object", entry.Resource)
+	}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(obj), live); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return planEntry{}, fmt.Errorf("failed to get live %s: %w", entry.Resource, err)
+		}
+		live = nil
+		entry.Action = planActionCreate
+	}
+
+	// Apply a copy, the dry-run response is written into the object
+	dryRun, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return planEntry{}, fmt.Errorf("%s cannot be copied", entry.Resource)
+	}
+	if err := controllerutil.SetControllerReference(appDef, dryRun, r.Scheme); err != nil {
+		return planEntry{}, fmt.Errorf("failed to set owner reference on %s: %w", entry.Resource, err)
+	}
+	result := kubeutil.ApplyObject(ctx, r.Client, dryRun, common.OperatorName,
+		kubeutil.WithDryRun(), kubeutil.WithForceOwnership(r.ForceOwnership))
+	if result.Error != nil {
+		entry.Error = result.Error.Error()
+		if live != nil {
+			entry.Action = planActionUpdate
+		}
+		return entry, nil
+	}
+	if live == nil {
+		return entry, nil
+	}
+
+	afterMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(dryRun)
+	if err != nil {
+		return planEntry{}, fmt.Errorf("failed to convert dry-run %s: %w", entry.Resource, err)
+	}
+	liveMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(live)
+	if err != nil {
+		return planEntry{}, fmt.Errorf("failed to convert live %s: %w", entry.Resource, err)
+	}
+	// Compare the server's view of the applied object with the live one, restricted to the desired fields,
+	// so values normalized or defaulted by the API server do not show up as changes.
+	projected, _ := projectOnto(desiredMap, afterMap).(map[string]interface{})
+	entry.ChangedPaths = diffDesiredFields(projected, liveMap)
+	if len(entry.ChangedPaths) > 0 {
+		entry.Action = planActionUpdate
+	}
+	return entry, nil
+}
+
+// projectOnto returns the parts of actual that are present in shape.
+func projectOnto(shape, actual interface{}) interface{} {
+	switch s := shape.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return actual
+		}
+		out := make(map[string]interface{}, len(s))
+		for key, value := range s {
+			if actualValue, found := a[key]; found {
+				out[key] = projectOnto(value, actualValue)
+			}
+		}
+		return out
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok || len(a) != len(s) {
+			return actual
+		}
+		out := make([]interface{}, len(s))
+		for i := range s {
+			out[i] = projectOnto(s[i], a[i])
+		}
+		return out
+	default:
+		return actual
+	}
+}
+
+// findOrphanedObjects lists objects controlled by the application that are not part of the plan's desired objects.
+func (r *ApplicationDefinitionReconciler) findOrphanedObjects(ctx context.Context, appDef *appv1.ApplicationDefinition, planned []planEntry) ([]planEntry, error) {
+	desired := make(map[string]bool, len(planned))
+	for _, entry := range planned {
+		desired[entry.Resource] = true
+	}
+
+	lists := []client.ObjectList{
+		&appsv1.DeploymentList{},
+		&appsv1.StatefulSetList{},
+		&corev1.ServiceList{},
+		&corev1.PersistentVolumeClaimList{},
+		&corev1.ConfigMapList{},
+		&corev1.SecretList{},
+		&corev1.ServiceAccountList{},
+		&policyv1.PodDisruptionBudgetList{},
+	}
+	var orphans []planEntry
+	for _, list := range lists {
+		if err := r.Client.List(ctx, list, client.InNamespace(appDef.Namespace), client.MatchingLabels{appNameLabel: appDef.Name}); err != nil {
+			return nil, fmt.Errorf("failed to list %T: %w", list, err)
+		}
+		err := meta.EachListItem(list, func(item runtime.Object) error {
+			obj, ok := item.(client.Object)
+			if !ok || !metav1.IsControlledBy(obj, appDef) {
+				return nil
+			}
+			gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+			if err != nil {
+				return err
+			}
+			resource := fmt.Sprintf("%s/%s", gvk.Kind, obj.GetName())
+			if !desired[resource] {
+				orphans = append(orphans, planEntry{
+					Resource:  resource,
+					Component: obj.GetLabels()[compInstanceLabel],
+					Action:    planActionOrphaned,
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return orphans, nil
+}
+
+// planHash returns a short, stable hash of the plan entries, including the digests of their desired objects.
+func planHash(entries []planEntry) (string, error) {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal plan: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16], nil
+}
+
+// contentDigest returns a short, stable digest of an object's content; map keys are marshalled sorted.
+func contentDigest(content map[string]interface{}) (string, error) {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16], nil
+}
+
+// summarizePlan counts the plan entries per action.
+func summarizePlan(entries []planEntry, hash string) *appv1.PlanStatus {
+	plan := &appv1.PlanStatus{Hash: hash}
+	for _, entry := range entries {
+		if entry.Error != "" {
+			plan.Errors++
+			continue
+		}
+		switch entry.Action {
+		case planActionCreate:
+			plan.Creates++
+		case planActionUpdate:
+			plan.Updates++
+		case planActionOrphaned:
+			plan.Orphans++
+		}
+	}
+	return plan
+}
+
+// writePlanConfigMap stores the per-object plan in a ConfigMap owned by the application.
+// The ConfigMap is left alone when it already holds the plan with the same hash.
+func (r *ApplicationDefinitionReconciler) writePlanConfigMap(ctx context.Context, appDef *appv1.ApplicationDefinition, name string, entries []planEntry, hash string) error {
+	current := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: appDef.Namespace, Name: name}, current); err == nil {
+		if current.Data[planHashKey] == hash {
+			return nil
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get plan ConfigMap %s: %w", name, err)
+	}
+
+	data, err := yaml.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal plan: %w", err)
+	}
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: appDef.Namespace,
+			// Deliberately not labelled with the application name, the plan is not one of its resources
+			Labels: map[string]string{common.ManagedByLabel: common.OperatorName},
+		},
+		Data: map[string]string{
+			planDataKey: string(data),
+			planHashKey: hash,
+		},
+	}
+	if err := controllerutil.SetControllerReference(appDef, cm, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set owner reference on plan ConfigMap: %w", err)
+	}
+	if result := kubeutil.ApplyObject(ctx, r.Client, cm, common.OperatorName, kubeutil.WithForceOwnership(true)); result.Error != nil {
+		return fmt.Errorf("failed to write plan ConfigMap %s: %w", name, result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+)
+
+var _ = Describe("Dry-run plan", func() {
+	It("ignores values normalized by the API server", func() {
+		desired := map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas":  int64(3),
+				"resources": map[string]interface{}{"cpu": "0.5"},
+			},
+		}
+		dryRun := map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas":        int64(3),
+				"resources":       map[string]interface{}{"cpu": "500m"},
+				"revisionHistory": int64(10),
+			},
+		}
+		live := map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas":        int64(2),
+				"resources":       map[string]interface{}{"cpu": "500m"},
+				"revisionHistory": int64(10),
+			},
+		}
+		projected := projectOnto(desired, dryRun).(map[string]interface{})
+		Expect(projected["spec"]).NotTo(HaveKey("revisionHistory"))
+		Expect(diffDesiredFields(projected, live)).To(Equal([]string{".spec.replicas"}))
+	})
+
+	It("summarizes entries and hashes them stably", func() {
+		entries := []planEntry{
+			{Resource: "ConfigMap/web-config", Action: planActionUpdate, ChangedPaths: []string{".data.app.yml"}},
+			{Resource: "StatefulSet/web", Action: planActionUnchanged},
+			{Resource: "Service/web", Action: planActionCreate},
+			{Resource: "Secret/web-old", Action: planActionOrphaned},
+			{Resource: "Service/web-headless", Action: planActionUpdate, Error: "spec.clusterIP: field is immutable"},
+		}
+		plan := summarizePlan(entries, "h")
+		Expect(plan.Creates).To(BeEquivalentTo(1))
+		Expect(plan.Updates).To(BeEquivalentTo(1))
+		Expect(plan.Orphans).To(BeEquivalentTo(1))
+		Expect(plan.Errors).To(BeEquivalentTo(1))
+
+		hash1, err := planHash(entries)
+		Expect(err).NotTo(HaveOccurred())
+		hash2, _ := planHash(entries)
+		Expect(hash1).To(Equal(hash2))
+
+		entries[0].ChangedPaths = []string{".data.other.yml"}
+		hash3, _ := planHash(entries)
+		Expect(hash3).NotTo(Equal(hash1))
+	})
+
+	Context("with a cluster", func() {
+		var (
+			r      *ApplicationDefinitionReconciler
+			appDef *appv1.ApplicationDefinition
+		)
+
+		BeforeEach(func() {
+			s := runtime.NewScheme()
+			utilruntime.Must(clientgoscheme.AddToScheme(s))
+			utilruntime.Must(appv1.AddToScheme(s))
+			appDef = &appv1.ApplicationDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "search", Namespace: common.Namespace, UID: "uid-search"},
+			}
+			orphan := &policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Name: "es-old", Namespace: common.Namespace, Labels: map[string]string{appNameLabel: "search"}},
+			}
+			Expect(controllerutil.SetControllerReference(appDef, orphan, s)).To(Succeed())
+			r = &ApplicationDefinitionReconciler{Scheme: s,
+				Client: fake.NewClientBuilder().WithScheme(s).WithObjects(appDef.DeepCopy(), orphan).Build()}
+		})
+
+		statefulSet := func(image string) *appsv1.StatefulSet {
+			sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "es", Namespace: common.Namespace}}
+			sts.Spec.Template.Spec.Containers = []corev1.Container{{Name: "es", Image: image}}
+			return sts
+		}
+
+		It("hashes the desired values, not only the changed paths", func() {
+			approved, err := r.planObject(context.Background(), appDef, statefulSet("es:v3"))
+			Expect(err).NotTo(HaveOccurred())
+			later, err := r.planObject(context.Background(), appDef, statefulSet("es:v4"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(later.Digest).NotTo(BeEmpty())
+			Expect(later.Digest).NotTo(Equal(approved.Digest))
+
+			approvedHash, _ := planHash([]planEntry{approved})
+			laterHash, _ := planHash([]planEntry{later})
+			Expect(laterHash).NotTo(Equal(approvedHash))
+		})
+
+		It("lists owned PodDisruptionBudgets that are no longer desired", func() {
+			orphans, err := r.findOrphanedObjects(context.Background(), appDef, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orphans).To(ConsistOf(planEntry{Resource: "PodDisruptionBudget/es-old", Action: planActionOrphaned}))
+		})
+
+		It("leaves the plan ConfigMap alone when the plan did not change", func() {
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "search" + planConfigMapSuffix, Namespace: common.Namespace},
+				Data:       map[string]string{planHashKey: "h1"},
+			}
+			Expect(r.Client.Create(context.Background(), cm)).To(Succeed())
+
+			Expect(r.writePlanConfigMap(context.Background(), appDef, cm.Name, nil, "h1")).To(Succeed())
+			current := &corev1.ConfigMap{}
+			Expect(r.Client.Get(context.Background(), client.ObjectKeyFromObject(cm), current)).To(Succeed())
+			Expect(current.ResourceVersion).To(Equal(cm.ResourceVersion))
+			Expect(current.Data).NotTo(HaveKey(planDataKey))
+		})
+	})
+})
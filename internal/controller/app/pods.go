@@ -0,0 +1,102 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/app/pods.go
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/internal/controller/common/kubeutil"
+)
+
+// maxPodsInStatus bounds the pod entries kept per component, to keep the status object small.
+const maxPodsInStatus = 20
+
+// refreshPodStatuses lists the pods of the application and records per-pod entries and a diagnosis on
+// every component status. Unhealthy components get the diagnosis appended to their message.
+func refreshPodStatuses(ctx context.Context, c client.Client, appDef *appv1.ApplicationDefinition,
+	statuses map[string]*appv1.ComponentStatusReference) error {
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(appDef.Namespace), client.MatchingLabels{appNameLabel: appDef.Name}); err != nil {
+		return fmt.Errorf("failed to list pods of application %s: %w", appDef.Name, err)
+	}
+	byComponent := make(map[string][]corev1.Pod)
+	for _, pod := range pods.Items {
+		comp := pod.Labels[compInstanceLabel]
+		byComponent[comp] = append(byComponent[comp], pod)
+	}
+
+	for name, compStatus := range statuses {
+		compPods := byComponent[name]
+		sort.Slice(compPods, func(i, j int) bool { return compPods[i].Name < compPods[j].Name })
+		compStatus.Pods = nil
+		compStatus.Diagnosis = ""
+
+		var diagnosed []string
+		for i := range compPods {
+			entry := podStatusEntry(&compPods[i])
+			if entry.Diagnosis != "" {
+				diagnosed = append(diagnosed, fmt.Sprintf("pod %s %s", entry.Name, entry.Diagnosis))
+			}
+			if len(compStatus.Pods) < maxPodsInStatus {
+				compStatus.Pods = append(compStatus.Pods, entry)
+			}
+		}
+		if len(diagnosed) == 0 {
+			continue
+		}
+		compStatus.Diagnosis = diagnosed[0]
+		if len(diagnosed) > 1 {
+			compStatus.Diagnosis += fmt.Sprintf(" (and %d more pods)", len(diagnosed)-1)
+		}
+		if !compStatus.Health && !strings.Contains(compStatus.Message, compStatus.Diagnosis) {
+			compStatus.Message = fmt.Sprintf("%s: %s", compStatus.Message, compStatus.Diagnosis)
+		}
+	}
+	return nil
+}
+
+// podStatusEntry summarizes a pod for the component status.
+func podStatusEntry(pod *corev1.Pod) appv1.ComponentPodStatus {
+	entry := appv1.ComponentPodStatus{
+		Name:                  pod.Name,
+		Node:                  pod.Spec.NodeName,
+		Phase:                 string(pod.Status.Phase),
+		Ready:                 kubeutil.PodReady(pod),
+		RestartCount:          kubeutil.PodRestartCount(pod),
+		LastTerminationReason: kubeutil.LastTerminationReason(pod),
+		Diagnosis:             kubeutil.DiagnosePod(pod),
+	}
+	if len(pod.Spec.Containers) > 0 {
+		entry.Image = pod.Spec.Containers[0].Image
+	}
+	return entry
+}
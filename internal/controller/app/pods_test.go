@@ -0,0 +1,85 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+)
+
+var _ = Describe("Pod status detail", func() {
+	pod := func(name string, ready bool, waitingReason string) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default",
+				Labels: map[string]string{appNameLabel: "gw", compInstanceLabel: "gateway"}},
+			Spec: corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{{Name: "gateway", Image: "infinilabs/gateway:1.0"}}},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name: "gateway", Ready: ready, RestartCount: 4,
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 2}},
+				}},
+			},
+		}
+		if ready {
+			p.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		} else {
+			p.Status.ContainerStatuses[0].State.Waiting = &corev1.ContainerStateWaiting{Reason: waitingReason}
+		}
+		return p
+	}
+
+	It("records pods and a diagnosis on the component", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			pod("gw-1", false, "CrashLoopBackOff"),
+			pod("gw-0", true, ""),
+			pod("gw-2", false, "CrashLoopBackOff"),
+		).Build()
+		appDef := &appv1.ApplicationDefinition{ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "default"}}
+		compStatus := &appv1.ComponentStatusReference{Name: "gateway", Message: "Waiting for readiness: 1/3"}
+		statuses := map[string]*appv1.ComponentStatusReference{"gateway": compStatus}
+
+		Expect(refreshPodStatuses(context.Background(), c, appDef, statuses)).To(Succeed())
+		Expect(compStatus.Pods).To(HaveLen(3))
+		Expect(compStatus.Pods[0]).To(Equal(appv1.ComponentPodStatus{
+			Name: "gw-0", Node: "node-1", Phase: "Running", Ready: true, RestartCount: 4,
+			LastTerminationReason: "Error", Image: "infinilabs/gateway:1.0",
+		}))
+		Expect(compStatus.Pods[1].Diagnosis).To(Equal("CrashLoopBackOff (container gateway, exit code 2)"))
+		Expect(compStatus.Diagnosis).To(Equal("pod gw-1 CrashLoopBackOff (container gateway, exit code 2) (and 1 more pods)"))
+		Expect(compStatus.Message).To(Equal("Waiting for readiness: 1/3: " + compStatus.Diagnosis))
+
+		By("not repeating the diagnosis in the message on the next evaluation")
+		Expect(refreshPodStatuses(context.Background(), c, appDef, statuses)).To(Succeed())
+		Expect(compStatus.Message).To(Equal("Waiting for readiness: 1/3: " + compStatus.Diagnosis))
+	})
+})
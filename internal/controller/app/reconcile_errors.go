@@ -0,0 +1,121 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/app/reconcile_errors.go
+package app
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/internal/controller/common/reconcileerr"
+	"github.com/infinilabs/runtime-operator/pkg/strategy"
+	"github.com/infinilabs/runtime-operator/pkg/webrecorder"
+)
+
+// reasonFieldManagerConflict is the reason of applies rejected because other field managers own the fields.
+const reasonFieldManagerConflict = "FieldManagerConflict"
+
+// setErrorPhase sets the phase and Ready condition for a reconcile that failed with err.
+// Errors that cannot be fixed by retrying fail the application; retryable ones keep it progressing.
+func setErrorPhase(appDef *appv1.ApplicationDefinition, err error) {
+	if reconcileerr.IsRetryable(err) {
+		appDef.Status.Phase = progressingPhase(appDef.Status.Phase)
+	} else {
+		appDef.Status.Phase = appv1.ApplicationPhaseFailed
+	}
+	setCondition(appDef, metav1.Condition{Type: string(appv1.ConditionReady), Status: metav1.ConditionFalse,
+		Reason: reconcileerr.ReasonOf(err), Message: err.Error()})
+}
+
+// progressingPhase returns the phase of an application that is not ready yet: Degraded once it has
+// been running, Creating until it first becomes ready, Updating otherwise.
+func progressingPhase(current appv1.ApplicationPhase) appv1.ApplicationPhase {
+	switch current {
+	case appv1.ApplicationPhaseRunning, appv1.ApplicationPhaseDegraded:
+		return appv1.ApplicationPhaseDegraded
+	case appv1.ApplicationPhaseCreating:
+		return appv1.ApplicationPhaseCreating
+	default:
+		return appv1.ApplicationPhaseUpdateing
+	}
+}
+
+// resultForError hands the first error of a reconcile to controller-runtime according to its type.
+// Optimistic lock conflicts are retried after the short conflict interval, errors that cannot be fixed by
+// retrying are terminal (the next spec change triggers a new reconcile) and other errors use the
+// controller's exponential backoff. Field manager conflicts are backed off as well: they last until the
+// other manager lets go of the fields.
+func (r *ApplicationDefinitionReconciler) resultForError(err error) (ctrl.Result, error) {
+	switch {
+	case apierrors.IsConflict(err) && reconcileerr.ReasonOf(err) != reasonFieldManagerConflict:
+		return ctrl.Result{RequeueAfter: r.conflictRequeueInterval()}, nil
+	case !reconcileerr.IsRetryable(err):
+		return ctrl.Result{}, reconcile.TerminalError(err)
+	default:
+		return ctrl.Result{}, err
+	}
+}
+
+// webhookStatusForError is the webhook status of an event reporting err: retryable errors leave the
+// change in progress, the others fail it.
+func webhookStatusForError(err error) string {
+	if reconcileerr.IsRetryable(err) {
+		return webrecorder.StatusInProgress
+	}
+	return webrecorder.StatusFailure
+}
+
+// classifyBuildError classifies an error returned by a builder strategy. Builders reading objects or
+// discovering APIs they depend on can hit the API server or the network while it is unavailable; those
+// errors are retried. Any other error fails the application until its spec changes.
+func classifyBuildError(err error) error {
+	if _, ok := reconcileerr.As(err); ok {
+		return err
+	}
+	if isTransientError(err) {
+		return reconcileerr.Dependency("DependencyUnavailable", err)
+	}
+	return reconcileerr.Build("BuilderFailed", err)
+}
+
+// isTransientError reports whether err comes from talking to the API server rather than from the spec:
+// API errors, network errors, timeouts, failed API discovery and errors the builder marked with
+// strategy.ErrDependencyUnavailable.
+func isTransientError(err error) bool {
+	var apiStatus apierrors.APIStatus
+	var netErr net.Error
+	var resourceDiscovery *apiutil.ErrResourceDiscoveryFailed
+	var groupDiscovery *discovery.ErrGroupDiscoveryFailed
+	return errors.Is(err, strategy.ErrDependencyUnavailable) || errors.As(err, &apiStatus) || errors.As(err, &netErr) || errors.As(err, &resourceDiscovery) ||
+		errors.As(err, &groupDiscovery) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,136 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/internal/controller/common/reconcileerr"
+	"github.com/infinilabs/runtime-operator/pkg/strategy"
+	"github.com/infinilabs/runtime-operator/pkg/webrecorder"
+)
+
+var _ = Describe("Typed reconcile errors", func() {
+	r := &ApplicationDefinitionReconciler{ConflictRequeueInterval: 2 * time.Second}
+
+	appIn := func(phase appv1.ApplicationPhase) *appv1.ApplicationDefinition {
+		appDef := &appv1.ApplicationDefinition{}
+		appDef.Status.Phase = phase
+		return appDef
+	}
+
+	It("fails the application on errors that retrying cannot fix", func() {
+		err := reconcileerr.Validation("ConfigUnmarshalFailed", errors.New("bad properties"))
+		appDef := appIn(appv1.ApplicationPhaseRunning)
+		setErrorPhase(appDef, err)
+
+		Expect(appDef.Status.Phase).To(Equal(appv1.ApplicationPhaseFailed))
+		ready := meta.FindStatusCondition(appDef.Status.Conditions, string(appv1.ConditionReady))
+		Expect(ready).NotTo(BeNil())
+		Expect(ready.Reason).To(Equal("ConfigUnmarshalFailed"))
+		Expect(webhookStatusForError(err)).To(Equal(webrecorder.StatusFailure))
+
+		_, resultErr := r.resultForError(err)
+		Expect(errors.Is(resultErr, reconcile.TerminalError(nil))).To(BeTrue())
+	})
+
+	It("keeps the application progressing on retryable errors", func() {
+		err := reconcileerr.Apply("ResourceApplyFailed", apierrors.NewServiceUnavailable("etcd leader changed"))
+		for from, to := range map[appv1.ApplicationPhase]appv1.ApplicationPhase{
+			appv1.ApplicationPhaseRunning:  appv1.ApplicationPhaseDegraded,
+			appv1.ApplicationPhaseCreating: appv1.ApplicationPhaseCreating,
+			appv1.ApplicationPhaseFailed:   appv1.ApplicationPhaseUpdateing,
+		} {
+			appDef := appIn(from)
+			setErrorPhase(appDef, err)
+			Expect(appDef.Status.Phase).To(Equal(to), "from %s", from)
+		}
+		Expect(webhookStatusForError(err)).To(Equal(webrecorder.StatusInProgress))
+
+		_, resultErr := r.resultForError(err)
+		Expect(resultErr).To(Equal(error(err)))
+	})
+
+	It("requeues optimistic lock conflicts after the conflict interval", func() {
+		conflict := apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "statefulsets"}, "web", errors.New("modified"))
+		result, resultErr := r.resultForError(reconcileerr.Apply("OptimisticLockConflict", conflict))
+		Expect(resultErr).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(2 * time.Second))
+	})
+
+	It("backs off field manager conflicts instead of failing the application", func() {
+		conflict := apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "statefulsets"}, "web", errors.New("conflict with \"kubectl-edit\""))
+		err := reconcileerr.New(reconcileerr.KindApply, reasonFieldManagerConflict, true, conflict)
+		appDef := appIn(appv1.ApplicationPhaseRunning)
+		setErrorPhase(appDef, err)
+		Expect(appDef.Status.Phase).To(Equal(appv1.ApplicationPhaseDegraded))
+
+		result, resultErr := r.resultForError(err)
+		Expect(result.RequeueAfter).To(BeZero())
+		Expect(resultErr).To(Equal(error(err)))
+		Expect(errors.Is(resultErr, reconcile.TerminalError(nil))).To(BeFalse())
+	})
+
+	It("retries builder errors from talking to the API server", func() {
+		transient := map[string]error{
+			"API error":       apierrors.NewServiceUnavailable("apiserver restarting"),
+			"network error":   &url.Error{Op: "Get", URL: "https://10.0.0.1/api", Err: errors.New("connection refused")},
+			"discovery error": &discovery.ErrGroupDiscoveryFailed{Groups: map[schema.GroupVersion]error{{Group: "monitoring.coreos.com", Version: "v1"}: errors.New("timeout")}},
+			"timeout":         context.DeadlineExceeded,
+			"builder marked":  fmt.Errorf("%w: monitoring check failed", strategy.ErrDependencyUnavailable),
+		}
+		for name, cause := range transient {
+			err := classifyBuildError(fmt.Errorf("builder strategy failed for component web: %w", cause))
+			Expect(reconcileerr.KindOf(err)).To(Equal(reconcileerr.KindDependency), name)
+			Expect(reconcileerr.IsRetryable(err)).To(BeTrue(), name)
+		}
+
+		err := classifyBuildError(errors.New("port metrics is not defined"))
+		Expect(reconcileerr.ReasonOf(err)).To(Equal("BuilderFailed"))
+		Expect(reconcileerr.IsRetryable(err)).To(BeFalse())
+
+		classified := reconcileerr.Validation("InvalidPort", errors.New("bad port"))
+		Expect(classifyBuildError(classified)).To(Equal(error(classified)))
+	})
+
+	It("reports unclassified errors as ReconcileFailed", func() {
+		appDef := appIn(appv1.ApplicationPhaseUpdateing)
+		setErrorPhase(appDef, errors.New("connection refused"))
+		Expect(appDef.Status.Phase).To(Equal(appv1.ApplicationPhaseUpdateing))
+		Expect(meta.FindStatusCondition(appDef.Status.Conditions, string(appv1.ConditionReady)).Reason).To(Equal(reconcileerr.ReasonUnknown))
+	})
+})
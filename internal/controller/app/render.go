@@ -0,0 +1,69 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/app/render.go
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/internal/controller/common/kubeutil"
+)
+
+// newReconcileState returns an empty reconcile state for an application.
+func newReconcileState(appDef *appv1.ApplicationDefinition) *reconcileState {
+	return &reconcileState{
+		appDef:              appDef,
+		desiredObjects:      []client.Object{},
+		componentStatuses:   make(map[string]*appv1.ComponentStatusReference),
+		applyResults:        make(map[string]kubeutil.ApplyResult),
+		unmarshalledConfigs: make(map[string]interface{}),
+		pendingRestarts:     make(map[string]string),
+	}
+}
+
+// RenderObjects builds the objects of an application exactly as Reconcile would apply them,
+// with standard labels and controller references, but without applying anything.
+// It backs the offline renderer, which runs the reconciler against a client without a cluster.
+func (r *ApplicationDefinitionReconciler) RenderObjects(ctx context.Context, appDef *appv1.ApplicationDefinition) ([]client.Object, error) {
+	state := newReconcileState(appDef)
+	state.originalStatus = appDef.Status.DeepCopy()
+
+	if err := r.initializeComponentStatuses(state); err != nil {
+		return nil, err
+	}
+	if err := r.processComponentsAndBuildObjects(ctx, state); err != nil {
+		return nil, err
+	}
+	for _, obj := range state.desiredObjects {
+		if err := controllerutil.SetControllerReference(appDef, obj, r.Scheme); err != nil {
+			return nil, fmt.Errorf("failed to set owner reference on %s: %w", obj.GetName(), err)
+		}
+	}
+	return state.desiredObjects, nil
+}
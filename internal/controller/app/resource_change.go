@@ -0,0 +1,135 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/app/resource_change.go
+package app
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/infinilabs/runtime-operator/pkg/webrecorder"
+)
+
+// allocation is what an object allocates: replicas, and CPU, memory and disk requested per pod.
+type allocation struct {
+	replicas *int32
+	cpu      resource.Quantity
+	memory   resource.Quantity
+	disk     resource.Quantity
+}
+
+// allocationOf returns the allocation of workloads and volume claims; false for other objects.
+func allocationOf(obj client.Object) (allocation, bool) {
+	var a allocation
+	switch o := obj.(type) {
+	case *appsv1.StatefulSet:
+		a.replicas = o.Spec.Replicas
+		a.addPod(&o.Spec.Template.Spec)
+		for i := range o.Spec.VolumeClaimTemplates {
+			a.disk.Add(o.Spec.VolumeClaimTemplates[i].Spec.Resources.Requests[corev1.ResourceStorage])
+		}
+	case *appsv1.Deployment:
+		a.replicas = o.Spec.Replicas
+		a.addPod(&o.Spec.Template.Spec)
+	case *appsv1.DaemonSet:
+		a.addPod(&o.Spec.Template.Spec)
+	case *corev1.PersistentVolumeClaim:
+		a.disk.Add(o.Spec.Resources.Requests[corev1.ResourceStorage])
+	default:
+		return a, false
+	}
+	return a, true
+}
+
+// addPod adds the CPU and memory requested by the containers of a pod.
+func (a *allocation) addPod(pod *corev1.PodSpec) {
+	for i := range pod.Containers {
+		requests := pod.Containers[i].Resources.Requests
+		a.cpu.Add(requests[corev1.ResourceCPU])
+		a.memory.Add(requests[corev1.ResourceMemory])
+	}
+}
+
+// resourceChange describes how applying an object changed what it allocates, for the webhook event
+// billing relies on. before is the live object prior to the apply, nil when the object was created;
+// after is the applied object. Only the changed dimensions are set, and nil is returned when nothing
+// changed or the object allocates nothing.
+func resourceChange(before, after client.Object) *webrecorder.ResourceChange {
+	afterAlloc, ok := allocationOf(after)
+	if !ok {
+		return nil
+	}
+	var beforeAlloc allocation
+	if before != nil {
+		beforeAlloc, _ = allocationOf(before)
+	}
+
+	change := &webrecorder.ResourceChange{}
+	changed := false
+	if beforeAlloc.cpu.Cmp(afterAlloc.cpu) != 0 {
+		change.CPUBefore, change.CPUAfter = quantityString(beforeAlloc.cpu), quantityString(afterAlloc.cpu)
+		changed = true
+	}
+	if beforeAlloc.memory.Cmp(afterAlloc.memory) != 0 {
+		change.MemoryBefore, change.MemoryAfter = quantityString(beforeAlloc.memory), quantityString(afterAlloc.memory)
+		changed = true
+	}
+	if beforeAlloc.disk.Cmp(afterAlloc.disk) != 0 {
+		change.DiskBefore, change.DiskAfter = quantityString(beforeAlloc.disk), quantityString(afterAlloc.disk)
+		changed = true
+	}
+	if !int32PtrEqual(beforeAlloc.replicas, afterAlloc.replicas) {
+		change.ReplicasBefore, change.ReplicasAfter = copyInt32Ptr(beforeAlloc.replicas), copyInt32Ptr(afterAlloc.replicas)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return change
+}
+
+// quantityString formats a quantity, empty when it is zero so the field is omitted.
+func quantityString(q resource.Quantity) string {
+	if q.IsZero() {
+		return ""
+	}
+	return q.String()
+}
+
+func int32PtrEqual(a, b *int32) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func copyInt32Ptr(p *int32) *int32 {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
@@ -0,0 +1,100 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/infinilabs/runtime-operator/pkg/webrecorder"
+)
+
+func statefulSetWith(replicas int32, cpu, memory, disk string) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "search", Namespace: "default"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name: "main",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(memory),
+				}},
+			}}}},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{Name: "data"},
+				Spec: corev1.PersistentVolumeClaimSpec{Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(disk)},
+				}},
+			}},
+		},
+	}
+}
+
+var _ = Describe("resourceChange", func() {
+	It("reports only the dimensions that changed", func() {
+		change := resourceChange(statefulSetWith(3, "1", "2Gi", "10Gi"), statefulSetWith(5, "1", "4Gi", "10Gi"))
+		Expect(change).To(Equal(&webrecorder.ResourceChange{
+			MemoryBefore:   "2Gi",
+			MemoryAfter:    "4Gi",
+			ReplicasBefore: ptrInt32(3),
+			ReplicasAfter:  ptrInt32(5),
+		}))
+	})
+
+	It("reports every allocation of a created object as after values", func() {
+		change := resourceChange(nil, statefulSetWith(1, "500m", "1Gi", "20Gi"))
+		Expect(change).To(Equal(&webrecorder.ResourceChange{
+			CPUAfter:      "500m",
+			MemoryAfter:   "1Gi",
+			DiskAfter:     "20Gi",
+			ReplicasAfter: ptrInt32(1),
+		}))
+	})
+
+	It("compares quantities by value", func() {
+		Expect(resourceChange(statefulSetWith(3, "1", "1024Mi", "10Gi"), statefulSetWith(3, "1000m", "1Gi", "10Gi"))).To(BeNil())
+	})
+
+	It("reports resized volume claims", func() {
+		claim := func(size string) *corev1.PersistentVolumeClaim {
+			return &corev1.PersistentVolumeClaim{Spec: corev1.PersistentVolumeClaimSpec{Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+			}}}
+		}
+		Expect(resourceChange(claim("10Gi"), claim("50Gi"))).To(Equal(&webrecorder.ResourceChange{DiskBefore: "10Gi", DiskAfter: "50Gi"}))
+	})
+
+	It("ignores objects that allocate nothing", func() {
+		Expect(resourceChange(nil, &corev1.ConfigMap{})).To(BeNil())
+	})
+})
+
+func ptrInt32(v int32) *int32 {
+	return &v
+}
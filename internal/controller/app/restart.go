@@ -0,0 +1,211 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/app/restart.go
+package app
+
+import (
+	"context"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/pkg/webrecorder"
+)
+
+const (
+	// restartedAtAnnotation is stamped on workload pod templates. Changing its value makes the
+	// workload controller perform a regular rolling update, honouring the update strategy.
+	restartedAtAnnotation = "infini.cloud/restarted-at"
+
+	restartPhase = "Restart"
+	restartStep  = "RestartComponent"
+)
+
+// effectiveRestartToken returns the restart request that applies to a component:
+// the later of the application-wide and the component-level value.
+func effectiveRestartToken(appDef *appv1.ApplicationDefinition, comp *appv1.ApplicationComponent) string {
+	appToken := appDef.Spec.RestartRequestedAt
+	compToken := comp.RestartRequestedAt
+	if appToken == "" {
+		return compToken
+	}
+	if compToken == "" {
+		return appToken
+	}
+
+	appTime, appErr := time.Parse(time.RFC3339, appToken)
+	compTime, compErr := time.Parse(time.RFC3339, compToken)
+	if appErr == nil && compErr == nil {
+		if compTime.After(appTime) {
+			return compToken
+		}
+		return appToken
+	}
+	// Not timestamps, fall back to a lexical comparison so the choice is at least stable
+	if compToken > appToken {
+		return compToken
+	}
+	return appToken
+}
+
+// handleRestart stamps the restart annotation on the pod templates of Deployments and StatefulSets.
+// A component whose requested token differs from the one recorded in status is marked as pending;
+// the token is only recorded once the rollout has completed (see completeRestarts).
+func (r *ApplicationDefinitionReconciler) handleRestart(ctx context.Context, state *reconcileState) error {
+	logger := log.FromContext(ctx)
+	appDef := state.appDef
+
+	// Resolve the requested token per component
+	requested := make(map[string]string, len(appDef.Spec.Components))
+	for i := range appDef.Spec.Components {
+		comp := &appDef.Spec.Components[i]
+		requested[comp.Name] = effectiveRestartToken(appDef, comp)
+	}
+
+	for _, obj := range state.desiredObjects {
+		var template *corev1.PodTemplateSpec
+		var live client.Object
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			template = &o.Spec.Template
+			live = &appsv1.Deployment{}
+		case *appsv1.StatefulSet:
+			template = &o.Spec.Template
+			live = &appsv1.StatefulSet{}
+		default:
+			continue // Only workloads with a pod template are restarted
+		}
+
+		compName := obj.GetLabels()[compInstanceLabel]
+		if compName == "" {
+			continue
+		}
+
+		token := requested[compName]
+		honoured := appDef.Status.RestartedAt[compName]
+		if token == "" || token == honoured {
+			// No new request. Keep the last honoured token on the template, otherwise
+			// clearing the request would itself roll the pods.
+			if honoured != "" {
+				setPodTemplateAnnotation(template, restartedAtAnnotation, honoured)
+			}
+			continue
+		}
+
+		setPodTemplateAnnotation(template, restartedAtAnnotation, token)
+		state.pendingRestarts[compName] = token
+
+		// Only announce the restart once, when the live workload does not carry the token yet
+		err := r.Client.Get(ctx, client.ObjectKeyFromObject(obj), live)
+		if err != nil && !apierrors.IsNotFound(err) {
+			logger.Error(err, "Failed to get live workload for restart check", "component", compName, "resource", obj.GetName())
+			continue
+		}
+		if err == nil && liveRestartToken(live) == token {
+			continue
+		}
+		logger.Info("Restarting component", "component", compName, "resource", obj.GetName(), "restartRequestedAt", token)
+		r.recordEventf(appDef, restartPhase, webrecorder.StatusInProgress, restartStep,
+			corev1.EventTypeNormal, "RestartStarted", "Rolling restart of component %s requested at %s", compName, token)
+	}
+
+	return nil
+}
+
+// completeRestarts records the honoured restart token for pending components once their
+// workloads run the restarted pod template and the component reports healthy again.
+func (r *ApplicationDefinitionReconciler) completeRestarts(ctx context.Context, state *reconcileState) {
+	logger := log.FromContext(ctx)
+	appDef := state.appDef
+	if len(state.pendingRestarts) == 0 {
+		return
+	}
+
+	for compName, token := range state.pendingRestarts {
+		compStatus := state.componentStatuses[compName]
+		if compStatus == nil || !compStatus.Health {
+			continue // Rollout still in progress
+		}
+		if !r.workloadsCarryRestartToken(ctx, state, compName, token) {
+			continue // Cache has not observed the stamped template yet
+		}
+
+		if appDef.Status.RestartedAt == nil {
+			appDef.Status.RestartedAt = make(map[string]string)
+		}
+		appDef.Status.RestartedAt[compName] = token
+		delete(state.pendingRestarts, compName)
+		logger.Info("Component restart completed", "component", compName, "restartRequestedAt", token)
+		r.recordEventf(appDef, restartPhase, webrecorder.StatusSuccess, restartStep,
+			corev1.EventTypeNormal, "RestartCompleted", "Rolling restart of component %s completed", compName)
+	}
+}
+
+// workloadsCarryRestartToken checks that every live workload of a component has the given token on its pod template.
+func (r *ApplicationDefinitionReconciler) workloadsCarryRestartToken(ctx context.Context, state *reconcileState, compName, token string) bool {
+	for _, obj := range state.desiredObjects {
+		if obj.GetLabels()[compInstanceLabel] != compName {
+			continue
+		}
+		var live client.Object
+		switch obj.(type) {
+		case *appsv1.Deployment:
+			live = &appsv1.Deployment{}
+		case *appsv1.StatefulSet:
+			live = &appsv1.StatefulSet{}
+		default:
+			continue
+		}
+		if err := r.Client.Get(ctx, client.ObjectKeyFromObject(obj), live); err != nil {
+			return false
+		}
+		if liveRestartToken(live) != token {
+			return false
+		}
+	}
+	return true
+}
+
+// liveRestartToken returns the restart annotation of a live workload's pod template.
+func liveRestartToken(obj client.Object) string {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		return o.Spec.Template.Annotations[restartedAtAnnotation]
+	case *appsv1.StatefulSet:
+		return o.Spec.Template.Annotations[restartedAtAnnotation]
+	}
+	return ""
+}
+
+func setPodTemplateAnnotation(template *corev1.PodTemplateSpec, key, value string) {
+	if template.Annotations == nil {
+		template.Annotations = make(map[string]string)
+	}
+	template.Annotations[key] = value
+}
@@ -0,0 +1,58 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+)
+
+var _ = Describe("Rolling restart phase", func() {
+	stateIn := func(phase appv1.ApplicationPhase) *reconcileState {
+		state := newReconcileState(&appv1.ApplicationDefinition{Status: appv1.ApplicationDefinitionStatus{Phase: phase}})
+		state.pendingRestarts["web"] = "2025-01-01T00:00:00Z"
+		return state
+	}
+
+	It("reports a restarting Running application as Updating rather than Degraded", func() {
+		r := &ApplicationDefinitionReconciler{}
+		for _, phase := range []appv1.ApplicationPhase{appv1.ApplicationPhaseRunning, appv1.ApplicationPhaseUpdateing} {
+			state := stateIn(phase)
+			r.determineFinalPhase(state, false)
+			Expect(state.appDef.Status.Phase).To(Equal(appv1.ApplicationPhaseUpdateing), string(phase))
+			ready := meta.FindStatusCondition(state.appDef.Status.Conditions, string(appv1.ConditionReady))
+			Expect(ready.Reason).To(Equal("ComponentsRestarting"), string(phase))
+		}
+	})
+
+	It("still reports unready Running applications without a restart as Degraded", func() {
+		state := stateIn(appv1.ApplicationPhaseRunning)
+		delete(state.pendingRestarts, "web")
+		(&ApplicationDefinitionReconciler{}).determineFinalPhase(state, false)
+		Expect(state.appDef.Status.Phase).To(Equal(appv1.ApplicationPhaseDegraded))
+	})
+})
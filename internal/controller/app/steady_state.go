@@ -0,0 +1,84 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/app/steady_state.go
+package app
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+)
+
+// nothingRequested reports whether a stable application has nothing to do until its next drift scan:
+// no restart, resume or plan is pending, no referenced ConfigMap or Secret changed since its last build
+// and the drift scan is not due. Its components are then not built at all.
+func (r *ApplicationDefinitionReconciler) nothingRequested(appDef *appv1.ApplicationDefinition, now time.Time) bool {
+	if dryRunEnabled(appDef) || appDef.Status.Plan != nil {
+		return false
+	}
+	if len(appDef.Status.SuspendedReplicas) > 0 && (appDef.Spec.Suspend == nil || !*appDef.Spec.Suspend) {
+		return false // Resume pending
+	}
+	for i := range appDef.Spec.Components {
+		comp := &appDef.Spec.Components[i]
+		if token := effectiveRestartToken(appDef, comp); token != "" && token != appDef.Status.RestartedAt[comp.Name] {
+			return false
+		}
+	}
+	key := types.NamespacedName{Namespace: appDef.Namespace, Name: appDef.Name}
+	if _, changed := r.configChanged.Load(key); changed {
+		return false
+	}
+	return r.untilDriftScan(appDef, now) > 0
+}
+
+// untilDriftScan returns how long until the application is due for its next drift scan; zero or less
+// when it is due. Applications not scanned since the operator started are due.
+func (r *ApplicationDefinitionReconciler) untilDriftScan(appDef *appv1.ApplicationDefinition, now time.Time) time.Duration {
+	if effectiveDriftPolicy(appDef) == appv1.DriftPolicyIgnore {
+		return driftScanInterval(appDef)
+	}
+	scannedAt, found := r.driftScannedAt.Load(types.NamespacedName{Namespace: appDef.Namespace, Name: appDef.Name})
+	if !found {
+		return 0
+	}
+	return scannedAt.(time.Time).Add(driftScanInterval(appDef)).Sub(now)
+}
+
+// steadyStateResult schedules the next drift scan of an application skipped by nothingRequested.
+func (r *ApplicationDefinitionReconciler) steadyStateResult(appDef *appv1.ApplicationDefinition, now time.Time) ctrl.Result {
+	if effectiveDriftPolicy(appDef) == appv1.DriftPolicyIgnore {
+		return ctrl.Result{}
+	}
+	return ctrl.Result{RequeueAfter: r.untilDriftScan(appDef, now)}
+}
+
+// forgetSteadyState drops what is remembered about a deleted application.
+func (r *ApplicationDefinitionReconciler) forgetSteadyState(key types.NamespacedName) {
+	r.configChanged.Delete(key)
+	r.driftScannedAt.Delete(key)
+}
@@ -0,0 +1,116 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+)
+
+var _ = Describe("Steady state reconciles", func() {
+	var (
+		r      *ApplicationDefinitionReconciler
+		appDef *appv1.ApplicationDefinition
+		key    types.NamespacedName
+		now    time.Time
+	)
+
+	BeforeEach(func() {
+		r = &ApplicationDefinitionReconciler{}
+		appDef = &appv1.ApplicationDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "search", Namespace: common.Namespace, Generation: 2},
+			Spec: appv1.ApplicationDefinitionSpec{
+				Components: []appv1.ApplicationComponent{{Name: "web", Type: operatorComponentType}},
+			},
+			Status: appv1.ApplicationDefinitionStatus{Phase: appv1.ApplicationPhaseRunning, ObservedGeneration: 2},
+		}
+		key = types.NamespacedName{Namespace: appDef.Namespace, Name: appDef.Name}
+		now = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	})
+
+	It("builds an application whose drift scan is due", func() {
+		Expect(r.nothingRequested(appDef, now)).To(BeFalse())
+
+		r.driftScannedAt.Store(key, now.Add(-time.Minute))
+		Expect(r.nothingRequested(appDef, now)).To(BeTrue())
+		Expect(r.steadyStateResult(appDef, now).RequeueAfter).To(Equal(defaultDriftScanInterval - time.Minute))
+
+		Expect(r.nothingRequested(appDef, now.Add(defaultDriftScanInterval))).To(BeFalse())
+
+		appDef.Spec.DriftPolicy = appv1.DriftPolicyIgnore
+		r.forgetSteadyState(key)
+		Expect(r.nothingRequested(appDef, now)).To(BeTrue())
+		Expect(r.steadyStateResult(appDef, now)).To(Equal(ctrl.Result{}))
+	})
+
+	It("builds an application when something was requested", func() {
+		r.driftScannedAt.Store(key, now)
+
+		r.configChanged.Store(key, struct{}{})
+		Expect(r.nothingRequested(appDef, now)).To(BeFalse())
+		r.configChanged.Delete(key)
+
+		appDef.Spec.Components[0].RestartRequestedAt = "2025-01-01T00:00:00Z"
+		Expect(r.nothingRequested(appDef, now)).To(BeFalse())
+		appDef.Status.RestartedAt = map[string]string{"web": "2025-01-01T00:00:00Z"}
+		Expect(r.nothingRequested(appDef, now)).To(BeTrue())
+
+		appDef.Status.SuspendedReplicas = map[string]int32{"web": 3}
+		Expect(r.nothingRequested(appDef, now)).To(BeFalse())
+		appDef.Status.SuspendedReplicas = nil
+
+		appDef.Annotations = map[string]string{appv1.AnnotationDryRun: "true"}
+		Expect(r.nothingRequested(appDef, now)).To(BeFalse())
+	})
+
+	It("marks the applications referencing a changed ConfigMap", func() {
+		scheme := runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+		utilruntime.Must(appv1.AddToScheme(scheme))
+		appDef.Spec.Components[0].Properties = runtime.RawExtension{Raw: []byte(`{"envFrom":[{"configMapRef":{"name":"shared"}}]}`)}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(appDef).
+			WithIndex(&appv1.ApplicationDefinition{}, configRefIndexKey, indexConfigReferences).Build()
+		r = &ApplicationDefinitionReconciler{Client: c, Scheme: scheme, Recorder: record.NewFakeRecorder(10)}
+		r.driftScannedAt.Store(key, now)
+
+		shared := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: common.Namespace}}
+		Expect(r.findAppsForConfigMap(context.Background(), shared)).To(HaveLen(1))
+		Expect(r.nothingRequested(appDef, now)).To(BeFalse())
+	})
+})
@@ -0,0 +1,112 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/app/tracing.go
+package app
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/types"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/internal/controller/common/reconcileerr"
+	"github.com/infinilabs/runtime-operator/pkg/webrecorder"
+)
+
+// tracerName names the tracer of reconciles.
+const tracerName = "github.com/infinilabs/runtime-operator/internal/controller/app"
+
+// Attributes of reconcile spans.
+const (
+	attrNamespace   = attribute.Key("k8s.namespace.name")
+	attrApplication = attribute.Key("infini.application")
+	attrChangeID    = attribute.Key("infini.change_id")
+	attrClusterID   = attribute.Key("infini.cluster_id")
+	attrComponent   = attribute.Key("infini.component")
+	attrObjectKind  = attribute.Key("infini.object.kind")
+	attrObjectName  = attribute.Key("infini.object.name")
+	attrOperation   = attribute.Key("infini.apply.operation")
+	attrHealthy     = attribute.Key("infini.healthy")
+	attrReason      = attribute.Key("infini.reason")
+)
+
+// startRequestSpan starts a span of the reconcile of the named application, before it is read.
+func startRequestSpan(ctx context.Context, name string, key types.NamespacedName) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(
+		attrNamespace.String(key.Namespace), attrApplication.String(key.Name)))
+}
+
+// startSpan starts a span of the reconcile of appDef, carrying its change and cluster IDs.
+func startSpan(ctx context.Context, name string, appDef *appv1.ApplicationDefinition, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(append(applicationAttributes(appDef), attrs...)...))
+}
+
+// applicationAttributes returns the span attributes identifying appDef and its current change.
+func applicationAttributes(appDef *appv1.ApplicationDefinition) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attrNamespace.String(appDef.Namespace),
+		attrApplication.String(appDef.Name),
+		attrChangeID.String(appDef.Annotations[appv1.AnnotationChangeID]),
+		attrClusterID.String(appDef.Annotations[appv1.AnnotationClusterID]),
+	}
+}
+
+// endSpan ends span, marking it failed with the reason of err, if not nil.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(attrReason.String(reconcileerr.ReasonOf(err)))
+	}
+	span.End()
+}
+
+// trackTraceContext remembers the trace context of ctx for the events recorded about the named application
+// until the returned function is called. Only one reconcile of an application runs at a time.
+func (r *ApplicationDefinitionReconciler) trackTraceContext(ctx context.Context, key types.NamespacedName) func() {
+	traceContext := map[string]string{}
+	webrecorder.InjectTraceContext(ctx, traceContext)
+	if len(traceContext) == 0 {
+		return func() {}
+	}
+	r.traceContexts.Store(key, traceContext)
+	return func() { r.traceContexts.Delete(key) }
+}
+
+// withTraceContext adds the trace context of the reconcile in progress for app, if any, to the annotations
+// of an event about it, so the webhook request delivering the event continues the reconcile's trace.
+func (r *ApplicationDefinitionReconciler) withTraceContext(app *appv1.ApplicationDefinition, annotations map[string]string) map[string]string {
+	value, found := r.traceContexts.Load(types.NamespacedName{Namespace: app.Namespace, Name: app.Name})
+	if !found {
+		return annotations
+	}
+	for key, v := range value.(map[string]string) {
+		annotations[key] = v
+	}
+	return annotations
+}
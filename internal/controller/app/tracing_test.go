@@ -0,0 +1,156 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+	"github.com/infinilabs/runtime-operator/pkg/webrecorder"
+)
+
+var _ = Describe("Tracing", func() {
+	var (
+		spans  *tracetest.SpanRecorder
+		c      client.Client
+		r      *ApplicationDefinitionReconciler
+		appDef *appv1.ApplicationDefinition
+	)
+
+	BeforeEach(func() {
+		spans = tracetest.NewSpanRecorder()
+		previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+		DeferCleanup(func() {
+			otel.SetTracerProvider(previousProvider)
+			otel.SetTextMapPropagator(previousPropagator)
+		})
+
+		s := runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(s))
+		utilruntime.Must(appv1.AddToScheme(s))
+		channel := &appv1.NotificationChannel{
+			ObjectMeta: metav1.ObjectMeta{Name: "changes", Namespace: common.Namespace, UID: "uid-changes", Labels: map[string]string{"team": "ops"}},
+			Spec:       appv1.NotificationChannelSpec{URL: "https://hooks.example.com/changes"},
+		}
+		appDef = &appv1.ApplicationDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "search", Namespace: common.Namespace, UID: "uid-search",
+				Annotations: map[string]string{appv1.AnnotationChangeID: "c1", appv1.AnnotationClusterID: "cluster-1"}},
+			Spec: appv1.ApplicationDefinitionSpec{
+				NotificationChannelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "ops"}},
+			},
+		}
+		c = fake.NewClientBuilder().WithScheme(s).WithObjects(channel, appDef.DeepCopy()).Build()
+		r = &ApplicationDefinitionReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(100),
+			Notifier: NewNotifier(c, NewNotificationOutbox(c, c), nil)}
+	})
+
+	// endedSpan returns the ended span with the given name.
+	endedSpan := func(name string) sdktrace.ReadOnlySpan {
+		for _, span := range spans.Ended() {
+			if span.Name() == name {
+				return span
+			}
+		}
+		return nil
+	}
+
+	// attributes returns the attributes of a span as strings.
+	attributes := func(span sdktrace.ReadOnlySpan) map[attribute.Key]string {
+		values := map[attribute.Key]string{}
+		for _, kv := range span.Attributes() {
+			values[kv.Key] = kv.Value.Emit()
+		}
+		return values
+	}
+
+	It("traces the reconcile of a missing application with its init phase", func() {
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: common.Namespace, Name: "gone"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		reconcileSpan, initSpan := endedSpan("Reconcile"), endedSpan("Init")
+		Expect(reconcileSpan).NotTo(BeNil())
+		Expect(initSpan).NotTo(BeNil())
+		Expect(initSpan.Parent().SpanID()).To(Equal(reconcileSpan.SpanContext().SpanID()))
+		Expect(attributes(reconcileSpan)).To(HaveKeyWithValue(attrApplication, "gone"))
+	})
+
+	It("carries the change and cluster IDs on phase spans", func() {
+		_, err := r.handleFinalizer(context.Background(), newReconcileState(appDef))
+		Expect(err).NotTo(HaveOccurred())
+
+		finalizerSpan := endedSpan("Finalizer")
+		Expect(finalizerSpan).NotTo(BeNil())
+		Expect(attributes(finalizerSpan)).To(And(
+			HaveKeyWithValue(attrChangeID, "c1"),
+			HaveKeyWithValue(attrClusterID, "cluster-1"),
+			HaveKeyWithValue(attrApplication, "search"),
+		))
+	})
+
+	It("propagates the reconcile's trace context with the events recorded during it", func() {
+		queued := func() []webrecorder.OutboxEntry {
+			cm := &corev1.ConfigMap{}
+			Expect(c.Get(context.Background(), client.ObjectKey{Namespace: common.Namespace, Name: "changes" + NotificationOutboxSuffix}, cm)).To(Succeed())
+			var entries []webrecorder.OutboxEntry
+			Expect(json.Unmarshal([]byte(cm.Data["entries.json"]), &entries)).To(Succeed())
+			return entries
+		}
+
+		ctx, span := otel.Tracer("test").Start(context.Background(), "Reconcile")
+		untrack := r.trackTraceContext(ctx, client.ObjectKeyFromObject(appDef))
+		r.recordEventf(appDef, "Reconcile", webrecorder.StatusInProgress, "SyncComponent", corev1.EventTypeNormal, "Traced", "traced")
+		untrack()
+		span.End()
+		r.recordEventf(appDef, "Reconcile", webrecorder.StatusInProgress, "SyncComponent", corev1.EventTypeNormal, "Untraced", "untraced")
+
+		entries := queued()
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0].TraceContext).To(HaveKeyWithValue(webrecorder.TraceParentKey,
+			ContainSubstring(span.SpanContext().TraceID().String())))
+		Expect(entries[0].Event.Payload).NotTo(HaveKey(webrecorder.TraceParentKey))
+		Expect(entries[1].TraceContext).To(BeEmpty())
+	})
+})
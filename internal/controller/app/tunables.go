@@ -0,0 +1,59 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/app/tunables.go
+package app
+
+import (
+	"time"
+
+	configv1alpha1 "github.com/infinilabs/runtime-operator/api/config/v1alpha1"
+)
+
+func (r *ApplicationDefinitionReconciler) requeueInterval() time.Duration {
+	if r.RequeueInterval > 0 {
+		return r.RequeueInterval
+	}
+	return configv1alpha1.DefaultRequeueInterval
+}
+
+func (r *ApplicationDefinitionReconciler) conflictRequeueInterval() time.Duration {
+	if r.ConflictRequeueInterval > 0 {
+		return r.ConflictRequeueInterval
+	}
+	return configv1alpha1.DefaultConflictRequeueInterval
+}
+
+func (r *ApplicationDefinitionReconciler) finalizerRetries() int {
+	if r.FinalizerRetries > 0 {
+		return r.FinalizerRetries
+	}
+	return configv1alpha1.DefaultFinalizerRetries
+}
+
+func (r *ApplicationDefinitionReconciler) statusUpdateRetries() int {
+	if r.StatusUpdateRetries > 0 {
+		return r.StatusUpdateRetries
+	}
+	return configv1alpha1.DefaultStatusUpdateRetries
+}
@@ -0,0 +1,74 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/app/webhook_delivery.go
+package app
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/pkg/webrecorder"
+)
+
+// ReportWebhookDelivery returns a webrecorder.ReportFunc that mirrors the webhook outbox of an
+// ApplicationDefinition into status.webhook, so pending and dead-lettered events are visible.
+func ReportWebhookDelivery(c client.Client) webrecorder.ReportFunc {
+	return func(ctx context.Context, report webrecorder.DeliveryReport) error {
+		appDef := &appv1.ApplicationDefinition{}
+		if err := c.Get(ctx, report.Owner, appDef); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		desired := webhookDeliveryStatus(report)
+		if equality.Semantic.DeepEqual(appDef.Status.Webhook, desired) {
+			return nil
+		}
+		patch := client.MergeFrom(appDef.DeepCopy())
+		appDef.Status.Webhook = desired
+		return client.IgnoreNotFound(c.Status().Patch(ctx, appDef, patch))
+	}
+}
+
+// webhookDeliveryStatus converts a delivery report into the status field, nil once everything was delivered.
+func webhookDeliveryStatus(report webrecorder.DeliveryReport) *appv1.WebhookDeliveryStatus {
+	if report.Pending == 0 && len(report.DeadLetters) == 0 {
+		return nil
+	}
+	status := &appv1.WebhookDeliveryStatus{Pending: int32(report.Pending), LastError: report.LastError}
+	for _, dead := range report.DeadLetters {
+		status.DeadLetters = append(status.DeadLetters, appv1.WebhookDeadLetter{
+			ChangeID:       dead.Event.ChangeID,
+			Phase:          dead.Event.Phase,
+			Step:           dead.Event.Step,
+			Status:         dead.Event.Status,
+			Attempts:       int32(dead.Attempts),
+			LastError:      dead.LastError,
+			DeadLetteredAt: metav1.NewTime(dead.DeadLetteredAt),
+		})
+	}
+	return status
+}
@@ -0,0 +1,111 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/pkg/webrecorder"
+)
+
+var _ = Describe("Webhook delivery status", func() {
+	It("mirrors pending and dead-lettered events into the status and clears it once delivered", func() {
+		s := runtime.NewScheme()
+		utilruntime.Must(appv1.AddToScheme(s))
+		appDef := &appv1.ApplicationDefinition{ObjectMeta: metav1.ObjectMeta{Name: "hooked", Namespace: "default"}}
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(appDef).WithStatusSubresource(appDef).Build()
+		report := ReportWebhookDelivery(c)
+		ctx := context.Background()
+		key := client.ObjectKeyFromObject(appDef)
+
+		deadAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		Expect(report(ctx, webrecorder.DeliveryReport{
+			Owner: key, Pending: 2, LastError: "webhook endpoint returned 503 Service Unavailable",
+			DeadLetters: []webrecorder.DeadLetter{{
+				OutboxEntry: webrecorder.OutboxEntry{
+					Event:     webrecorder.WebhookEvent{ChangeID: "c1", Phase: "Reconcile", Step: "SyncComponent", Status: "success"},
+					Attempts:  8,
+					LastError: "connection refused",
+				},
+				DeadLetteredAt: deadAt,
+			}},
+		})).To(Succeed())
+
+		Expect(c.Get(ctx, key, appDef)).To(Succeed())
+		Expect(appDef.Status.Webhook).NotTo(BeNil())
+		Expect(appDef.Status.Webhook.Pending).To(Equal(int32(2)))
+		Expect(appDef.Status.Webhook.DeadLetters).To(HaveLen(1))
+		Expect(appDef.Status.Webhook.DeadLetters[0].ChangeID).To(Equal("c1"))
+		Expect(appDef.Status.Webhook.DeadLetters[0].Attempts).To(Equal(int32(8)))
+
+		Expect(report(ctx, webrecorder.DeliveryReport{Owner: key})).To(Succeed())
+		Expect(c.Get(ctx, key, appDef)).To(Succeed())
+		Expect(appDef.Status.Webhook).To(BeNil())
+	})
+
+	It("ignores outboxes of deleted applications", func() {
+		s := runtime.NewScheme()
+		utilruntime.Must(appv1.AddToScheme(s))
+		c := fake.NewClientBuilder().WithScheme(s).Build()
+		Expect(ReportWebhookDelivery(c)(context.Background(), webrecorder.DeliveryReport{
+			Owner: client.ObjectKey{Namespace: "default", Name: "gone"}, Pending: 1,
+		})).To(Succeed())
+	})
+})
+
+var _ = Describe("Webhook signing secret", func() {
+	It("references a Secret of the application's namespace, with the default key unless one is given", func() {
+		appDef := &appv1.ApplicationDefinition{ObjectMeta: metav1.ObjectMeta{Name: "hooked", Namespace: "apps"}}
+		Expect(webhookSigningSecret(appDef)).To(BeNil())
+
+		appDef.Annotations = map[string]string{appv1.AnnotationChangeWebhookSecret: "signing"}
+		Expect(webhookSigningSecret(appDef)).To(Equal(&webrecorder.SigningSecret{Namespace: "apps", Name: "signing", Key: appv1.DefaultWebhookSecretKey}))
+
+		appDef.Annotations[appv1.AnnotationChangeWebhookSecret] = "signing/hmac"
+		Expect(webhookSigningSecret(appDef)).To(Equal(&webrecorder.SigningSecret{Namespace: "apps", Name: "signing", Key: "hmac"}))
+	})
+})
+
+var _ = Describe("Webhook format", func() {
+	It("uses the annotated format and leaves unknown or missing ones to the operator default", func() {
+		appDef := &appv1.ApplicationDefinition{ObjectMeta: metav1.ObjectMeta{Name: "hooked", Namespace: "apps"}}
+		Expect(webhookFormat(appDef)).To(BeEmpty())
+
+		appDef.Annotations = map[string]string{appv1.AnnotationChangeWebhookFormat: "cloudevents-binary"}
+		Expect(webhookFormat(appDef)).To(Equal(webrecorder.FormatCloudEventsBinary))
+
+		appDef.Annotations[appv1.AnnotationChangeWebhookFormat] = "xml"
+		Expect(webhookFormat(appDef)).To(BeEmpty())
+	})
+})
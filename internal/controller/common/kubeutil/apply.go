@@ -28,32 +28,82 @@ package kubeutil
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/cisco-open/operator-tools/pkg/reconciler"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil" // For OperationResult
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 // ApplyResult contains the result of an apply operation (SSA).
-// Note: With Server-Side Apply, accurately determining Created vs Updated vs Unchanged
-// often requires comparing the object before and after, which adds complexity.
-// OperationResultNone is often returned for successful SSA calls if no mutations occurred or detection is hard.
 type ApplyResult struct {
-	// Operation indicates the result (Created, Updated, Configured, None, Deleted - less relevant for Apply).
-	// Using OperationResultNone for SSA success unless K8s version/libraries provide more detail.
+	// Operation indicates the result: Created, Updated, or None when the object was unchanged.
 	Operation controllerutil.OperationResult
 	// Error holds any error that occurred during the apply operation.
 	Error error
+	// Conflicts lists the fields owned by other field managers when the apply was rejected with a conflict.
+	Conflicts []FieldConflict
+	// Previous is the live object read before the apply; nil when the object did not exist.
+	Previous client.Object
 }
 
-// ApplyObject idempotently applies the desired state of a Kubernetes object
-// using Server-Side Apply. It creates the object if it doesn't exist, or
-// patches it based on the desired state, managing fields via the specified fieldManager.
+// FieldConflict describes a field that another field manager owns with a different value.
+type FieldConflict struct {
+	// Field is the path of the conflicting field, e.g. ".spec.replicas".
+	Field string
+	// Manager is the field manager currently owning the field.
+	Manager string
+}
+
+// ApplyOptions configures ApplyObject.
+type ApplyOptions struct {
+	// ForceOwnership takes over fields owned by other field managers instead of failing with a conflict.
+	ForceOwnership bool
+	// DryRun performs a server-side dry-run: the request is validated and admitted but not persisted.
+	DryRun bool
+	// UpgradeManagers lists field managers that wrote the object with Create or Update. Their fields
+	// are handed to the apply field manager before the apply, so they do not conflict with it.
+	UpgradeManagers []string
+}
+
+// ApplyOption mutates ApplyOptions.
+type ApplyOption func(*ApplyOptions)
+
+// WithForceOwnership sets whether conflicting fields are taken over from other field managers.
+func WithForceOwnership(force bool) ApplyOption {
+	return func(o *ApplyOptions) {
+		o.ForceOwnership = force
+	}
+}
+
+// WithUpgradeManagers migrates the fields owned by the given Create/Update field managers to the
+// apply field manager, see ApplyOptions.UpgradeManagers.
+func WithUpgradeManagers(managers ...string) ApplyOption {
+	return func(o *ApplyOptions) {
+		o.UpgradeManagers = append(o.UpgradeManagers, managers...)
+	}
+}
+
+// WithDryRun makes ApplyObject perform a server-side dry-run apply.
+func WithDryRun() ApplyOption {
+	return func(o *ApplyOptions) {
+		o.DryRun = true
+	}
+}
+
+// ApplyObject applies the desired state of a Kubernetes object using Server-Side Apply,
+// with fieldManager as the field owner. The object is created if it doesn't exist.
 // The input 'obj' should be a *pointer* to a valid client.Object (e.g., &appsv1.Deployment{}).
 // It should contain the complete desired state *with* Kind, APIVersion, Name, Namespace set.
-func ApplyObject(ctx context.Context, k8sClient client.Client, obj client.Object, fieldManager string) ApplyResult {
+// On success obj holds the state returned by the API server. The operation is derived from
+// the resourceVersion before and after the apply.
+func ApplyObject(ctx context.Context, k8sClient client.Client, obj client.Object, fieldManager string, opts ...ApplyOption) ApplyResult {
 	// Ensure essential fields for Apply are present (Defensive check)
 	gvk := obj.GetObjectKind().GroupVersionKind()
 	objKey := client.ObjectKeyFromObject(obj)
@@ -66,69 +116,139 @@ func ApplyObject(ctx context.Context, k8sClient client.Client, obj client.Object
 		return ApplyResult{Error: err}
 	}
 
+	applyOpts := ApplyOptions{}
+	for _, opt := range opts {
+		opt(&applyOpts)
+	}
+
 	logger := log.FromContext(ctx).WithValues(
 		"kind", gvk.Kind,
 		"version", gvk.Version,
 		"name", objKey.Name,
 		"namespace", objKey.Namespace,
 		"fieldManager", fieldManager,
+		"forceOwnership", applyOpts.ForceOwnership,
+		"dryRun", applyOpts.DryRun,
 	)
 	logger.V(1).Info("Attempting to apply object using Server-Side Apply")
 
-	// Prepare the patch data using client.Apply.
-	// Pass the object itself which contains the desired state.
-	// Prepare the patch data using client.Apply.
-	// Pass the object itself which contains the desired state.
-	patchData := client.Merge // This signifies the patch *content* implies Apply strategy
-
-	// Prepare the patch options.
-	// FieldOwner: Identifies who is managing the fields in this object. Should be unique (e.g., your operator name).
-	// Force: Needed to acquire ownership of fields currently managed by others (e.g., if a Helm chart or manual edit previously set fields).
-	patchOpts := []client.PatchOption{
-		client.FieldOwner(fieldManager),
-		// client.ForceOwnership,
+	// Remember the current resourceVersion to tell Created/Updated/Unchanged apart afterwards
+	existing, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return ApplyResult{Error: fmt.Errorf("object %s of kind %s cannot be copied", objKey, gvk.Kind)}
 	}
-
-	err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj)
-	if err != nil {
-		if client.IgnoreNotFound(err) != nil {
+	beforeVersion := ""
+	var previous client.Object
+	if err := k8sClient.Get(ctx, objKey, existing); err != nil {
+		if !apierrors.IsNotFound(err) {
 			logger.Error(err, "Failed to get resource")
 			return ApplyResult{Error: fmt.Errorf("failed to get resource: %w", err)}
 		}
-		// If the object does not exist, we will create it.
-		if err := k8sClient.Create(ctx, obj); err != nil {
-			logger.Error(err, "Failed to create resource")
-			return ApplyResult{Error: fmt.Errorf("failed to create resource: %w", err)}
+	} else {
+		if len(applyOpts.UpgradeManagers) > 0 && !applyOpts.DryRun {
+			migrated, err := upgradeManagedFields(ctx, k8sClient, existing, applyOpts.UpgradeManagers, fieldManager)
+			if err != nil {
+				logger.Error(err, "Failed to migrate managed fields to the apply field manager")
+				return ApplyResult{Error: fmt.Errorf("failed to migrate managed fields: %w", err), Previous: existing}
+			}
+			if migrated {
+				logger.Info("Migrated managed fields to the apply field manager", "managers", applyOpts.UpgradeManagers)
+			}
 		}
+		beforeVersion = existing.GetResourceVersion()
+		previous = existing
+	}
 
-		logger.V(1).Info("Resource created successfully")
+	// An apply configuration must not carry managedFields; the resourceVersion would act as a precondition.
+	obj.SetManagedFields(nil)
+	obj.SetResourceVersion("")
 
-		return ApplyResult{Operation: controllerutil.OperationResultCreated, Error: nil}
+	patchOpts := []client.PatchOption{client.FieldOwner(fieldManager)}
+	if applyOpts.ForceOwnership {
+		patchOpts = append(patchOpts, client.ForceOwnership)
+	}
+	if applyOpts.DryRun {
+		patchOpts = append(patchOpts, client.DryRunAll)
 	}
 
-	// Call the Patch API method with the object and apply options.
-	// This is the core Server-Side Apply call.
-	err = k8sClient.Patch(ctx, obj, patchData, patchOpts...)
-	if err != nil {
-		// Wrap the error for more context if desired
-		// Example: fmt.Errorf("failed to apply %s %s/%s: %w", gvk.Kind, objKey.Namespace, objKey.Name, err)
-		// Or return original error
-		logger.V(1).Error(err, "Patch call failed")
-		return ApplyResult{Error: err} // Return the API error
+	if err := k8sClient.Patch(ctx, obj, client.Apply, patchOpts...); err != nil {
+		conflicts := FieldConflictsFromError(err)
+		if len(conflicts) > 0 {
+			logger.Info("Server-Side Apply rejected due to field manager conflicts", "conflicts", conflicts)
+		} else {
+			logger.V(1).Error(err, "Apply patch failed")
+		}
+		return ApplyResult{Error: err, Conflicts: conflicts, Previous: previous}
+	}
+
+	operation := OperationFromResourceVersions(beforeVersion, obj.GetResourceVersion())
+	logger.V(1).Info("Apply patch succeeded", "operation", operation)
+	return ApplyResult{Operation: operation, Previous: previous}
+}
+
+// upgradeManagedFields hands the fields owned by the Create/Update managers of obj to fieldManager as
+// applied fields and reports whether there was anything to migrate. obj is updated in place.
+func upgradeManagedFields(ctx context.Context, k8sClient client.Client, obj client.Object, managers []string, fieldManager string) (bool, error) {
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(obj, sets.New(managers...), fieldManager)
+	if err != nil || patch == nil {
+		return false, err
+	}
+	return true, k8sClient.Patch(ctx, obj, client.RawPatch(types.JSONPatchType, patch))
+}
+
+// OperationFromResourceVersions derives the apply operation from the resourceVersion observed
+// before the apply (empty when the object did not exist) and the one returned by the apply.
+func OperationFromResourceVersions(before, after string) controllerutil.OperationResult {
+	switch {
+	case before == "":
+		return controllerutil.OperationResultCreated
+	case before != after:
+		return controllerutil.OperationResultUpdated
+	default:
+		return controllerutil.OperationResultNone
 	}
+}
 
-	logger.V(1).Info("Patch call succeeded")
+// FieldConflictsFromError extracts the field manager conflicts from a Server-Side Apply error.
+// It returns nil for any other error.
+func FieldConflictsFromError(err error) []FieldConflict {
+	if err == nil || !apierrors.IsConflict(err) {
+		return nil
+	}
+	status, ok := err.(apierrors.APIStatus)
+	if !ok || status.Status().Details == nil {
+		return nil
+	}
 
-	// --- Determine Operation Result (Optional and Tricky with SSA) ---
-	// K8s Server-Side Apply API call itself doesn't clearly return Created vs Updated vs Unchanged.
-	// It might set f:managedFields on the object upon return.
-	// To accurately determine: Get object BEFORE patch, GET object AFTER patch, compare spec/metadata/resourceVersion.
-	// This adds API calls and complexity.
-	// A simpler approach is to return OperationResultNone for success, and let health checks verify result.
-	// For this framework, let's indicate success with None.
-	// If the K8s libraries provided better results for SSA in the future, we could leverage that.
+	var conflicts []FieldConflict
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		conflicts = append(conflicts, FieldConflict{
+			Field:   cause.Field,
+			Manager: conflictManager(cause.Message),
+		})
+	}
+	return conflicts
+}
+
+// conflictManager extracts the manager name from a cause message such as
+// `conflict with "kubectl-edit" using apps/v1`. The message is returned as is if it has another shape.
+func conflictManager(message string) string {
+	rest := strings.TrimPrefix(message, "conflict with ")
+	if strings.HasPrefix(rest, `"`) {
+		if end := strings.Index(rest[1:], `"`); end >= 0 {
+			return rest[1 : end+1]
+		}
+	}
+	return rest
+}
 
-	return ApplyResult{Operation: controllerutil.OperationResultNone, Error: nil}
+// IsImmutableFieldError reports whether err rejects a change to an immutable field,
+// which Server-Side Apply cannot resolve without recreating the object.
+func IsImmutableFieldError(err error) bool {
+	return apierrors.IsInvalid(err) && strings.Contains(err.Error(), "field is immutable")
 }
 
 // ApplyObjectV2 idempotently applies the desired state of a Kubernetes object
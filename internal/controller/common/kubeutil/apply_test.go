@@ -0,0 +1,75 @@
+package kubeutil
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestOperationFromResourceVersions(t *testing.T) {
+	cases := []struct {
+		before, after string
+		want          controllerutil.OperationResult
+	}{
+		{"", "10", controllerutil.OperationResultCreated},
+		{"10", "11", controllerutil.OperationResultUpdated},
+		{"10", "10", controllerutil.OperationResultNone},
+	}
+	for _, c := range cases {
+		if got := OperationFromResourceVersions(c.before, c.after); got != c.want {
+			t.Errorf("OperationFromResourceVersions(%q, %q) = %q, want %q", c.before, c.after, got, c.want)
+		}
+	}
+}
+
+func TestFieldConflictsFromError(t *testing.T) {
+	err := &apierrors.StatusError{ErrStatus: metav1.Status{
+		Status: metav1.StatusFailure,
+		Code:   409,
+		Reason: metav1.StatusReasonConflict,
+		Details: &metav1.StatusDetails{
+			Group: "apps",
+			Kind:  "deployments",
+			Name:  "web",
+			Causes: []metav1.StatusCause{
+				{Type: metav1.CauseTypeFieldManagerConflict, Message: `conflict with "kubectl-edit" using apps/v1`, Field: ".spec.replicas"},
+				{Type: metav1.CauseTypeFieldManagerConflict, Message: `conflict with "hpa-controller"`, Field: ".spec.template.spec.containers[name=\"web\"].resources"},
+				{Type: metav1.CauseTypeFieldValueInvalid, Message: "ignored", Field: ".metadata.name"},
+			},
+		},
+	}}
+
+	want := []FieldConflict{
+		{Field: ".spec.replicas", Manager: "kubectl-edit"},
+		{Field: ".spec.template.spec.containers[name=\"web\"].resources", Manager: "hpa-controller"},
+	}
+	if got := FieldConflictsFromError(err); !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldConflictsFromError() = %#v, want %#v", got, want)
+	}
+
+	// An optimistic lock conflict carries no field manager causes
+	lockErr := apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"}, "web", errors.New("object has been modified"))
+	if got := FieldConflictsFromError(lockErr); got != nil {
+		t.Errorf("FieldConflictsFromError(optimistic lock) = %#v, want nil", got)
+	}
+	if got := FieldConflictsFromError(errors.New("boom")); got != nil {
+		t.Errorf("FieldConflictsFromError(plain error) = %#v, want nil", got)
+	}
+}
+
+func TestIsImmutableFieldError(t *testing.T) {
+	gk := schema.GroupKind{Group: "apps", Kind: "StatefulSet"}
+	immutable := apierrors.NewInvalid(gk, "db", nil)
+	immutable.ErrStatus.Message = `StatefulSet.apps "db" is invalid: spec: Forbidden: updates to statefulset spec for fields other than 'replicas' are forbidden; spec.selector: Invalid value: field is immutable`
+	if !IsImmutableFieldError(immutable) {
+		t.Error("expected immutable field error to be detected")
+	}
+	if IsImmutableFieldError(apierrors.NewInvalid(gk, "db", nil)) {
+		t.Error("expected other validation errors not to be treated as immutable")
+	}
+}
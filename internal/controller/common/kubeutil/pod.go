@@ -0,0 +1,156 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/common/kubeutil/pod.go
+package kubeutil
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Container waiting reasons reported as image pull failures.
+var imagePullReasons = map[string]bool{
+	"ImagePullBackOff":  true,
+	"ErrImagePull":      true,
+	"InvalidImageName":  true,
+	"ErrImageNeverPull": true,
+}
+
+// Container waiting reasons that are part of a normal start and not worth a diagnosis.
+var transientWaitingReasons = map[string]bool{
+	"":                  true,
+	"ContainerCreating": true,
+	"PodInitializing":   true,
+}
+
+// DiagnosePod explains why a pod is not running: unschedulable (with the scheduler's reason),
+// CrashLoopBackOff, image pull failures, OOMKilled or another container waiting reason.
+// It returns an empty string when no problem is detected.
+func DiagnosePod(pod *corev1.Pod) string {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable {
+			return fmt.Sprintf("Unschedulable: %s", cond.Message)
+		}
+	}
+
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	multiContainer := len(statuses) > 1
+	for _, cs := range statuses {
+		if cs.Ready {
+			continue
+		}
+		terminated := cs.State.Terminated
+		if terminated == nil {
+			terminated = cs.LastTerminationState.Terminated
+		}
+		if terminated != nil && terminated.Reason == "OOMKilled" {
+			return oomDiagnosis(pod, cs.Name, multiContainer)
+		}
+
+		waiting := cs.State.Waiting
+		if waiting == nil || transientWaitingReasons[waiting.Reason] {
+			continue
+		}
+		switch {
+		case waiting.Reason == "CrashLoopBackOff":
+			if terminated != nil {
+				return fmt.Sprintf("CrashLoopBackOff (container %s, exit code %d)", cs.Name, terminated.ExitCode)
+			}
+			return fmt.Sprintf("CrashLoopBackOff (container %s)", cs.Name)
+		case imagePullReasons[waiting.Reason]:
+			return fmt.Sprintf("%s (image %s)", waiting.Reason, cs.Image)
+		default:
+			if waiting.Message != "" {
+				return fmt.Sprintf("%s (container %s): %s", waiting.Reason, cs.Name, waiting.Message)
+			}
+			return fmt.Sprintf("%s (container %s)", waiting.Reason, cs.Name)
+		}
+	}
+	return ""
+}
+
+// oomDiagnosis describes an OOM kill, with the container's memory limit when it has one.
+func oomDiagnosis(pod *corev1.Pod, containerName string, withContainer bool) string {
+	detail := ""
+	if withContainer {
+		detail = "container " + containerName
+	}
+	if limit, ok := containerMemoryLimit(pod, containerName); ok {
+		if detail != "" {
+			detail += ", "
+		}
+		detail += "limit " + limit
+	}
+	if detail == "" {
+		return "OOMKilled"
+	}
+	return fmt.Sprintf("OOMKilled (%s)", detail)
+}
+
+func containerMemoryLimit(pod *corev1.Pod, containerName string) (string, bool) {
+	containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, c := range containers {
+		if c.Name != containerName {
+			continue
+		}
+		if limit, ok := c.Resources.Limits[corev1.ResourceMemory]; ok {
+			return limit.String(), true
+		}
+	}
+	return "", false
+}
+
+// LastTerminationReason returns the reason of the most recent container termination of the pod.
+func LastTerminationReason(pod *corev1.Pod) string {
+	var reason string
+	var latest *corev1.ContainerStateTerminated
+	for _, cs := range pod.Status.ContainerStatuses {
+		for _, t := range []*corev1.ContainerStateTerminated{cs.State.Terminated, cs.LastTerminationState.Terminated} {
+			if t != nil && (latest == nil || latest.FinishedAt.Before(&t.FinishedAt)) {
+				latest, reason = t, t.Reason
+			}
+		}
+	}
+	return reason
+}
+
+// PodReady reports whether the pod's Ready condition is true.
+func PodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// PodRestartCount sums the restart counts of the pod's containers.
+func PodRestartCount(pod *corev1.Pod) int32 {
+	var restarts int32
+	for _, cs := range pod.Status.ContainerStatuses {
+		restarts += cs.RestartCount
+	}
+	return restarts
+}
@@ -0,0 +1,114 @@
+package kubeutil
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDiagnosePod(t *testing.T) {
+	container := func(name string) corev1.Container {
+		return corev1.Container{Name: name, Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+		}}
+	}
+	tests := map[string]struct {
+		pod  corev1.Pod
+		want string
+	}{
+		"running": {
+			pod: corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "app", Ready: true}}}},
+		},
+		"starting": {
+			pod: corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "app", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+			}}}},
+		},
+		"unschedulable": {
+			pod: corev1.Pod{Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+				Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable,
+				Message: "0/3 nodes are available: 3 Insufficient memory.",
+			}}}},
+			want: "Unschedulable: 0/3 nodes are available: 3 Insufficient memory.",
+		},
+		"crash loop": {
+			pod: corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name:                 "app",
+				State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}},
+			}}}},
+			want: "CrashLoopBackOff (container app, exit code 1)",
+		},
+		"oom killed": {
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{container("app")}},
+				Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+					Name:                 "app",
+					State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}},
+				}}},
+			},
+			want: "OOMKilled (limit 512Mi)",
+		},
+		"oom killed sidecar": {
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{container("app"), container("sidecar")}},
+				Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "app", Ready: true},
+					{Name: "sidecar", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"}}},
+				}},
+			},
+			want: "OOMKilled (container sidecar, limit 512Mi)",
+		},
+		"image pull": {
+			pod: corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "app", Image: "infinilabs/gateway:missing",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+			}}}},
+			want: "ImagePullBackOff (image infinilabs/gateway:missing)",
+		},
+		"config error": {
+			pod: corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "app",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+					Reason: "CreateContainerConfigError", Message: `secret "creds" not found`,
+				}},
+			}}}},
+			want: `CreateContainerConfigError (container app): secret "creds" not found`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := DiagnosePod(&tt.pod); got != tt.want {
+				t.Errorf("DiagnosePod() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPodSummaryHelpers(t *testing.T) {
+	now := time.Now()
+	pod := &corev1.Pod{Status: corev1.PodStatus{
+		Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "app", RestartCount: 2, LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				Reason: "Error", FinishedAt: metav1.NewTime(now.Add(-time.Hour)),
+			}}},
+			{Name: "sidecar", RestartCount: 1, LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				Reason: "OOMKilled", FinishedAt: metav1.NewTime(now),
+			}}},
+		},
+	}}
+	if !PodReady(pod) {
+		t.Error("expected the pod to be ready")
+	}
+	if got := PodRestartCount(pod); got != 3 {
+		t.Errorf("PodRestartCount() = %d, want 3", got)
+	}
+	if got := LastTerminationReason(pod); got != "OOMKilled" {
+		t.Errorf("LastTerminationReason() = %q, want OOMKilled", got)
+	}
+}
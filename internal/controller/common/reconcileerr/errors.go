@@ -0,0 +1,154 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/controller/common/reconcileerr/errors.go
+// Package reconcileerr classifies the errors of an ApplicationDefinition reconcile, so the phase,
+// condition reasons, requeue backoff and webhook status are derived from the error instead of its message.
+package reconcileerr
+
+import (
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Kind is the reconcile stage an error comes from.
+type Kind string
+
+const (
+	// KindBuild marks failures turning a component into Kubernetes objects.
+	KindBuild Kind = "Build"
+	// KindValidation marks specs or properties that cannot be reconciled as written.
+	KindValidation Kind = "Validation"
+	// KindApply marks failures applying objects to the cluster.
+	KindApply Kind = "Apply"
+	// KindHealth marks failures running health checks (not an unhealthy result).
+	KindHealth Kind = "Health"
+	// KindDependency marks objects or APIs the reconcile needs that are unavailable.
+	KindDependency Kind = "Dependency"
+)
+
+// ReasonUnknown is the reason reported for errors that were not classified.
+const ReasonUnknown = "ReconcileFailed"
+
+// Error is a classified reconcile error.
+type Error struct {
+	// Kind is the stage the error comes from.
+	Kind Kind
+	// Reason is a CamelCase code used for condition reasons, events and component status, e.g. "BuilderFailed".
+	Reason string
+	// Retryable is false when retrying cannot succeed until the spec (or the cluster) is changed by someone else.
+	Retryable bool
+	// Err is the underlying error.
+	Err error
+}
+
+// New returns a classified error.
+func New(kind Kind, reason string, retryable bool, err error) *Error {
+	return &Error{Kind: kind, Reason: reason, Retryable: retryable, Err: err}
+}
+
+// Build returns a build error. Builds are deterministic, so it is not retryable.
+func Build(reason string, err error) *Error {
+	return New(KindBuild, reason, false, err)
+}
+
+// Validation returns a validation error, which is not retryable.
+func Validation(reason string, err error) *Error {
+	return New(KindValidation, reason, false, err)
+}
+
+// Apply returns an apply error. It is retryable unless the API server rejected the object as invalid.
+func Apply(reason string, err error) *Error {
+	return New(KindApply, reason, !apierrors.IsInvalid(err) && !apierrors.IsBadRequest(err), err)
+}
+
+// Health returns a health check error, which is retryable.
+func Health(reason string, err error) *Error {
+	return New(KindHealth, reason, true, err)
+}
+
+// Dependency returns a dependency error, which is retryable.
+func Dependency(reason string, err error) *Error {
+	return New(KindDependency, reason, true, err)
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Reason
+	}
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Classify returns err unchanged when it is nil or already classified, and classify(reason, err) otherwise,
+// e.g. Classify(err, Apply, "PlanFailed").
+func Classify(err error, classify func(reason string, err error) *Error, reason string) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := As(err); ok {
+		return err
+	}
+	return classify(reason, err)
+}
+
+// As returns the classified error in err's chain.
+func As(err error) (*Error, bool) {
+	var rerr *Error
+	if errors.As(err, &rerr) {
+		return rerr, true
+	}
+	return nil, false
+}
+
+// KindOf returns the kind of err, or an empty kind when it is not classified.
+func KindOf(err error) Kind {
+	if rerr, ok := As(err); ok {
+		return rerr.Kind
+	}
+	return ""
+}
+
+// ReasonOf returns the reason code of err, or ReasonUnknown when it is not classified.
+func ReasonOf(err error) string {
+	if rerr, ok := As(err); ok && rerr.Reason != "" {
+		return rerr.Reason
+	}
+	return ReasonUnknown
+}
+
+// IsRetryable reports whether retrying err can succeed. Unclassified errors are usually
+// transient API failures and count as retryable.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if rerr, ok := As(err); ok {
+		return rerr.Retryable
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package reconcileerr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestClassification(t *testing.T) {
+	gk := schema.GroupKind{Group: "apps", Kind: "StatefulSet"}
+	invalid := apierrors.NewInvalid(gk, "web", field.ErrorList{field.Required(field.NewPath("spec", "selector"), "")})
+	unavailable := apierrors.NewServiceUnavailable("etcd leader changed")
+
+	tests := []struct {
+		name      string
+		err       error
+		kind      Kind
+		reason    string
+		retryable bool
+	}{
+		{"build", Build("BuilderFailed", errors.New("boom")), KindBuild, "BuilderFailed", false},
+		{"validation", Validation("ConfigUnmarshalFailed", errors.New("bad yaml")), KindValidation, "ConfigUnmarshalFailed", false},
+		{"apply transient", Apply("ResourceApplyFailed", unavailable), KindApply, "ResourceApplyFailed", true},
+		{"apply invalid", Apply("ResourceApplyFailed", invalid), KindApply, "ResourceApplyFailed", false},
+		{"health", Health("ReconcileTaskFailed", errors.New("timeout")), KindHealth, "ReconcileTaskFailed", true},
+		{"dependency", Dependency("ServiceLookupFailed", unavailable), KindDependency, "ServiceLookupFailed", true},
+		{"wrapped", fmt.Errorf("component web: %w", Build("InvalidBuiltObject", errors.New("no name"))), KindBuild, "InvalidBuiltObject", false},
+		{"unclassified", errors.New("connection refused"), "", ReasonUnknown, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KindOf(tt.err); got != tt.kind {
+				t.Errorf("KindOf() = %q, want %q", got, tt.kind)
+			}
+			if got := ReasonOf(tt.err); got != tt.reason {
+				t.Errorf("ReasonOf() = %q, want %q", got, tt.reason)
+			}
+			if got := IsRetryable(tt.err); got != tt.retryable {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.retryable)
+			}
+		})
+	}
+
+	if IsRetryable(nil) {
+		t.Error("IsRetryable(nil) = true, want false")
+	}
+}
+
+func TestErrorUnwrap(t *testing.T) {
+	cause := apierrors.NewServiceUnavailable("down")
+	err := Apply("ResourceApplyFailed", cause)
+	if err.Error() != cause.Error() {
+		t.Errorf("Error() = %q, want %q", err.Error(), cause.Error())
+	}
+	if !apierrors.IsServiceUnavailable(err) || !errors.Is(err, cause) {
+		t.Error("classified error does not unwrap to its cause")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	if Classify(nil, Apply, "PlanFailed") != nil {
+		t.Error("Classify(nil) != nil")
+	}
+	if got := ReasonOf(Classify(errors.New("dry-run failed"), Apply, "PlanFailed")); got != "PlanFailed" {
+		t.Errorf("ReasonOf(Classify(untyped)) = %q, want PlanFailed", got)
+	}
+	typed := Validation("ConfigUnmarshalFailed", errors.New("bad yaml"))
+	if got := Classify(typed, Apply, "PlanFailed"); got != error(typed) {
+		t.Errorf("Classify(typed) = %v, want the error unchanged", got)
+	}
+}
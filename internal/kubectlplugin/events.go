@@ -0,0 +1,128 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/kubectlplugin/events.go
+package kubectlplugin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+)
+
+func newEventsCommand(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "events APPLICATION",
+		Short: "List the events of an application and of the objects labelled as part of it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			events, err := o.applicationEvents(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if len(events) == 0 {
+				fmt.Fprintf(o.Out, "No events found for application %s in namespace %s.\n", args[0], o.Namespace)
+				return nil
+			}
+			w := tabwriter.NewWriter(o.Out, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "LAST SEEN\tTYPE\tREASON\tOBJECT\tMESSAGE")
+			now := time.Now()
+			for _, e := range events {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s/%s\t%s\n", duration.HumanDuration(now.Sub(eventTime(&e))),
+					e.Type, e.Reason, e.InvolvedObject.Kind, e.InvolvedObject.Name, e.Message)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// applicationEvents returns the events of the application itself and of every object labelled with
+// its name, oldest first.
+func (o *Options) applicationEvents(ctx context.Context, name string) ([]corev1.Event, error) {
+	appDef, err := o.getApplication(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	involved := map[string]bool{"ApplicationDefinition/" + appDef.Name: true}
+	lists := map[string]client.ObjectList{
+		"Pod":                   &corev1.PodList{},
+		"Service":               &corev1.ServiceList{},
+		"PersistentVolumeClaim": &corev1.PersistentVolumeClaimList{},
+		"ConfigMap":             &corev1.ConfigMapList{},
+		"StatefulSet":           &appsv1.StatefulSetList{},
+		"Deployment":            &appsv1.DeploymentList{},
+	}
+	for kind, list := range lists {
+		if err := o.Client.List(ctx, list, client.InNamespace(appDef.Namespace),
+			client.MatchingLabels{common.AppNameLabel: appDef.Name}); err != nil {
+			return nil, fmt.Errorf("failed to list %s objects: %w", kind, err)
+		}
+		err := meta.EachListItem(list, func(item runtime.Object) error {
+			if obj, ok := item.(client.Object); ok {
+				involved[kind+"/"+obj.GetName()] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	all := &corev1.EventList{}
+	if err := o.Client.List(ctx, all, client.InNamespace(appDef.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	var events []corev1.Event
+	for _, e := range all.Items {
+		if involved[e.InvolvedObject.Kind+"/"+e.InvolvedObject.Name] {
+			events = append(events, e)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return eventTime(&events[i]).Before(eventTime(&events[j])) })
+	return events, nil
+}
+
+// eventTime returns the most recent time an event was observed.
+func eventTime(e *corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
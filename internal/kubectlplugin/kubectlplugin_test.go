@@ -0,0 +1,177 @@
+package kubectlplugin
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+)
+
+func testApplication() *appv1.ApplicationDefinition {
+	return &appv1.ApplicationDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "search", Namespace: "default"},
+		Spec: appv1.ApplicationDefinitionSpec{
+			Components: []appv1.ApplicationComponent{{Name: "es", Type: "operator"}},
+		},
+		Status: appv1.ApplicationDefinitionStatus{
+			Phase: appv1.ApplicationPhaseRunning,
+			Components: []appv1.ComponentStatusReference{{
+				Name: "es", Kind: "StatefulSet", ResourceName: "es", Health: true, Message: "1/1 replicas ready",
+			}},
+		},
+	}
+}
+
+func testOptions(objs ...client.Object) (*Options, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	c := fake.NewClientBuilder().WithScheme(NewScheme()).WithObjects(objs...).Build()
+	return &Options{Namespace: "default", Out: out, Client: c}, out
+}
+
+func run(t *testing.T, o *Options, args ...string) {
+	t.Helper()
+	cmd := newRootCommand(o)
+	cmd.SetArgs(args)
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("kubectl-infini %s: %v", strings.Join(args, " "), err)
+	}
+}
+
+func TestStatusTree(t *testing.T) {
+	labels := map[string]string{common.AppNameLabel: "search", common.CompInstanceLabel: "es"}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "es-0", Namespace: "default", Labels: labels},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "es"}},
+			Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-es-0"},
+			}}},
+		},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "es", Ready: true, RestartCount: 2}},
+		},
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data-es-0", Namespace: "default"},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Phase:    corev1.ClaimBound,
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+		},
+	}
+	o, out := testOptions(testApplication(), pod, pvc)
+
+	tree, err := o.statusTree(context.Background(), "search")
+	if err != nil {
+		t.Fatalf("statusTree: %v", err)
+	}
+	tree.print(out)
+
+	want := `ApplicationDefinition/search  phase=Running
+└── Component/es  [healthy] 1/1 replicas ready
+    └── StatefulSet/es
+        └── Pod/es-0  Running ready=1/1 restarts=2
+            └── PersistentVolumeClaim/data-es-0  Bound 1Gi
+`
+	if out.String() != want {
+		t.Errorf("unexpected tree:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestSuspendResumeAndRestart(t *testing.T) {
+	o, _ := testOptions(testApplication())
+	ctx := context.Background()
+
+	run(t, o, "suspend", "search")
+	appDef, err := o.getApplication(ctx, "search")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if appDef.Spec.Suspend == nil || !*appDef.Spec.Suspend {
+		t.Fatalf("expected spec.suspend=true, got %v", appDef.Spec.Suspend)
+	}
+
+	run(t, o, "resume", "search")
+	if appDef, err = o.getApplication(ctx, "search"); err != nil {
+		t.Fatal(err)
+	}
+	if appDef.Spec.Suspend == nil || *appDef.Spec.Suspend {
+		t.Fatalf("expected spec.suspend=false, got %v", appDef.Spec.Suspend)
+	}
+
+	run(t, o, "restart", "search", "-c", "es")
+	if appDef, err = o.getApplication(ctx, "search"); err != nil {
+		t.Fatal(err)
+	}
+	if appDef.Spec.Components[0].RestartRequestedAt == "" || appDef.Spec.RestartRequestedAt != "" {
+		t.Fatalf("expected only the component restart to be requested, got app=%q component=%q",
+			appDef.Spec.RestartRequestedAt, appDef.Spec.Components[0].RestartRequestedAt)
+	}
+}
+
+func TestApplicationEvents(t *testing.T) {
+	now := time.Now()
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "es-0", Namespace: "default", Labels: map[string]string{common.AppNameLabel: "search"},
+	}}
+	event := func(name, kind, object string, at time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: kind, Name: object},
+			LastTimestamp:  metav1.NewTime(at),
+			Reason:         name,
+		}
+	}
+	o, _ := testOptions(testApplication(), pod,
+		event("pulled", "Pod", "es-0", now.Add(-time.Minute)),
+		event("synced", "ApplicationDefinition", "search", now.Add(-2*time.Minute)),
+		event("unrelated", "Pod", "other-0", now),
+	)
+
+	events, err := o.applicationEvents(context.Background(), "search")
+	if err != nil {
+		t.Fatalf("applicationEvents: %v", err)
+	}
+	var reasons []string
+	for _, e := range events {
+		reasons = append(reasons, e.Reason)
+	}
+	if got := strings.Join(reasons, ","); got != "synced,pulled" {
+		t.Errorf("expected events synced,pulled in order, got %s", got)
+	}
+}
+
+func TestReplayWebhooks(t *testing.T) {
+	outbox := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "search-webhook-outbox", Namespace: "default",
+			Labels: map[string]string{"infini.cloud/webhook-outbox": "search"}},
+		Data: map[string]string{
+			"sequence": "4",
+			"dead-letters.json": `[{"seq":4,"key":"k4","url":"http://hooks.example.com","attempts":8,` +
+				`"event":{"change_id":"c1","step":"SyncComponent","status":"success"},"lastError":"503","deadLetteredAt":"2025-01-01T00:00:00Z"}]`,
+		},
+	}
+	o, out := testOptions(testApplication(), outbox)
+
+	run(t, o, "replay-webhooks", "search")
+	if !strings.Contains(out.String(), "1 webhook events queued") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+	cm := &corev1.ConfigMap{}
+	if err := o.Client.Get(context.Background(), client.ObjectKeyFromObject(outbox), cm); err != nil {
+		t.Fatal(err)
+	}
+	if _, dead := cm.Data["dead-letters.json"]; dead || !strings.Contains(cm.Data["entries.json"], `"seq":5`) {
+		t.Errorf("dead letter was not queued again: %v", cm.Data)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/kubectlplugin/operations.go
+package kubectlplugin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/infinilabs/runtime-operator/pkg/webrecorder"
+)
+
+func newSuspendCommand(o *Options, suspend bool) *cobra.Command {
+	use, short := "suspend", "Suspend an application, scaling its workloads to zero"
+	if !suspend {
+		use, short = "resume", "Resume a suspended application, restoring its replicas"
+	}
+	return &cobra.Command{
+		Use:   use + " APPLICATION",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			appDef, err := o.getApplication(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			patch := client.MergeFrom(appDef.DeepCopy())
+			appDef.Spec.Suspend = &suspend
+			if err := o.Client.Patch(cmd.Context(), appDef, patch); err != nil {
+				return fmt.Errorf("failed to %s application %s: %w", use, appDef.Name, err)
+			}
+			fmt.Fprintf(o.Out, "applicationdefinition/%s %sd\n", appDef.Name, use)
+			return nil
+		},
+	}
+}
+
+func newRestartCommand(o *Options) *cobra.Command {
+	var component string
+	cmd := &cobra.Command{
+		Use:   "restart APPLICATION",
+		Short: "Trigger a rolling restart of an application or one of its components",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			appDef, err := o.getApplication(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			token := time.Now().UTC().Format(time.RFC3339)
+			patch := client.MergeFrom(appDef.DeepCopy())
+			target := "applicationdefinition/" + appDef.Name
+			if component == "" {
+				appDef.Spec.RestartRequestedAt = token
+			} else {
+				found := false
+				for i := range appDef.Spec.Components {
+					if appDef.Spec.Components[i].Name == component {
+						appDef.Spec.Components[i].RestartRequestedAt = token
+						found = true
+					}
+				}
+				if !found {
+					return fmt.Errorf("application %s has no component %q", appDef.Name, component)
+				}
+				target += " component " + component
+			}
+			if err := o.Client.Patch(cmd.Context(), appDef, patch); err != nil {
+				return fmt.Errorf("failed to restart %s: %w", target, err)
+			}
+			fmt.Fprintf(o.Out, "%s restart requested at %s\n", target, token)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&component, "component", "c", "", "Restart only this component.")
+	return cmd
+}
+
+func newReplayWebhooksCommand(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "replay-webhooks APPLICATION",
+		Short: "Queue the dead-lettered change webhook events of an application for delivery again",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			appDef, err := o.getApplication(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			outbox := webrecorder.NewOutbox(o.Client, o.Client)
+			replayed, err := outbox.ReplayDeadLetters(cmd.Context(), client.ObjectKeyFromObject(appDef))
+			if err != nil {
+				return fmt.Errorf("failed to replay webhook events of application %s: %w", appDef.Name, err)
+			}
+			fmt.Fprintf(o.Out, "applicationdefinition/%s %d webhook events queued for delivery\n", appDef.Name, replayed)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/kubectlplugin/root.go
+// Package kubectlplugin implements the kubectl-infini plugin for day-to-day operations on ApplicationDefinitions.
+package kubectlplugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+)
+
+// Options holds the flags shared by all commands and the resolved client.
+type Options struct {
+	Kubeconfig string
+	Context    string
+	Namespace  string
+
+	Out    io.Writer
+	Client client.Client // Set by Complete, or injected in tests
+}
+
+// NewRootCommand returns the kubectl-infini command.
+func NewRootCommand(out io.Writer) *cobra.Command {
+	return newRootCommand(&Options{Out: out})
+}
+
+func newRootCommand(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "kubectl-infini",
+		Short:         "Inspect and operate INFINI ApplicationDefinitions",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			return o.Complete()
+		},
+	}
+	cmd.PersistentFlags().StringVar(&o.Kubeconfig, "kubeconfig", "", "Path to the kubeconfig file.")
+	cmd.PersistentFlags().StringVar(&o.Context, "context", "", "The kubeconfig context to use.")
+	cmd.PersistentFlags().StringVarP(&o.Namespace, "namespace", "n", o.Namespace, "Namespace of the application, defaults to the context namespace.")
+
+	cmd.AddCommand(
+		newStatusCommand(o),
+		newSuspendCommand(o, true),
+		newSuspendCommand(o, false),
+		newRestartCommand(o),
+		newEventsCommand(o),
+		newReplayWebhooksCommand(o),
+	)
+	cmd.SetOut(o.Out)
+	return cmd
+}
+
+// Complete resolves the namespace and builds the client from the kubeconfig.
+func (o *Options) Complete() error {
+	if o.Client != nil {
+		return nil
+	}
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = o.Kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules,
+		&clientcmd.ConfigOverrides{CurrentContext: o.Context})
+
+	if o.Namespace == "" {
+		ns, _, err := clientConfig.Namespace()
+		if err != nil {
+			return fmt.Errorf("failed to resolve namespace: %w", err)
+		}
+		o.Namespace = ns
+	}
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	c, err := client.New(restConfig, client.Options{Scheme: NewScheme()})
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+	o.Client = c
+	return nil
+}
+
+// NewScheme returns a scheme with the core Kubernetes types and the ApplicationDefinition API.
+func NewScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(appv1.AddToScheme(scheme))
+	return scheme
+}
+
+// getApplication fetches an ApplicationDefinition in the selected namespace.
+func (o *Options) getApplication(ctx context.Context, name string) (*appv1.ApplicationDefinition, error) {
+	appDef := &appv1.ApplicationDefinition{}
+	if err := o.Client.Get(ctx, client.ObjectKey{Namespace: o.Namespace, Name: name}, appDef); err != nil {
+		return nil, fmt.Errorf("failed to get application %s/%s: %w", o.Namespace, name, err)
+	}
+	return appDef, nil
+}
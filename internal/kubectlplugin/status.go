@@ -0,0 +1,222 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/kubectlplugin/status.go
+package kubectlplugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+)
+
+func newStatusCommand(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status APPLICATION",
+		Short: "Show the component, workload, pod and volume tree of an application",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tree, err := o.statusTree(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			tree.print(o.Out)
+			return nil
+		},
+	}
+}
+
+// treeNode is a line of the status tree with its children.
+type treeNode struct {
+	text     string
+	children []*treeNode
+}
+
+func (n *treeNode) add(format string, args ...interface{}) *treeNode {
+	child := &treeNode{text: fmt.Sprintf(format, args...)}
+	n.children = append(n.children, child)
+	return child
+}
+
+func (n *treeNode) print(w io.Writer) {
+	fmt.Fprintln(w, n.text)
+	n.printChildren(w, "")
+}
+
+func (n *treeNode) printChildren(w io.Writer, prefix string) {
+	for i, child := range n.children {
+		branch, indent := "├── ", "│   "
+		if i == len(n.children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		fmt.Fprintf(w, "%s%s%s\n", prefix, branch, child.text)
+		child.printChildren(w, prefix+indent)
+	}
+}
+
+// statusTree builds the tree: application → components → workload → pods → PVCs.
+func (o *Options) statusTree(ctx context.Context, name string) (*treeNode, error) {
+	appDef, err := o.getApplication(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	pods := &corev1.PodList{}
+	if err := o.Client.List(ctx, pods, client.InNamespace(appDef.Namespace),
+		client.MatchingLabels{common.AppNameLabel: appDef.Name}); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := o.Client.List(ctx, pvcs, client.InNamespace(appDef.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list persistent volume claims: %w", err)
+	}
+	return buildStatusTree(appDef, pods.Items, pvcs.Items), nil
+}
+
+// buildStatusTree arranges the application status, its pods and their claims as a tree.
+func buildStatusTree(appDef *appv1.ApplicationDefinition, pods []corev1.Pod, pvcs []corev1.PersistentVolumeClaim) *treeNode {
+	root := &treeNode{text: fmt.Sprintf("ApplicationDefinition/%s  phase=%s", appDef.Name, valueOr(string(appDef.Status.Phase), "Unknown"))}
+	if ready := meta.FindStatusCondition(appDef.Status.Conditions, string(appv1.ConditionReady)); ready != nil {
+		root.text += fmt.Sprintf("  ready=%s (%s: %s)", ready.Status, ready.Reason, ready.Message)
+	}
+	if drifted := meta.FindStatusCondition(appDef.Status.Conditions, string(appv1.ConditionDrifted)); drifted != nil && drifted.Status == "True" {
+		root.text += fmt.Sprintf("  drifted: %s", drifted.Message)
+	}
+	if wh := appDef.Status.Webhook; wh != nil {
+		webhookNode := root.add("Webhook  pending=%d dead-lettered=%d", wh.Pending, len(wh.DeadLetters))
+		if wh.LastError != "" {
+			webhookNode.text += "  last error: " + wh.LastError
+		}
+		for _, dead := range wh.DeadLetters {
+			webhookNode.add("DeadLetter %s %s/%s/%s after %d attempts: %s", dead.ChangeID, dead.Phase, dead.Step, dead.Status, dead.Attempts, dead.LastError)
+		}
+	}
+
+	pvcByName := make(map[string]corev1.PersistentVolumeClaim, len(pvcs))
+	for _, pvc := range pvcs {
+		pvcByName[pvc.Name] = pvc
+	}
+	podsByComponent := make(map[string][]corev1.Pod)
+	for _, pod := range pods {
+		comp := pod.Labels[common.CompInstanceLabel]
+		podsByComponent[comp] = append(podsByComponent[comp], pod)
+	}
+
+	statuses := make(map[string]appv1.ComponentStatusReference, len(appDef.Status.Components))
+	for _, s := range appDef.Status.Components {
+		statuses[s.Name] = s
+	}
+
+	for _, comp := range appDef.Spec.Components {
+		status, known := statuses[comp.Name]
+		health := "unknown"
+		if known {
+			health = "unhealthy"
+			if status.Health {
+				health = "healthy"
+			}
+		}
+		compNode := root.add("Component/%s  [%s] %s", comp.Name, health, status.Message)
+		if status.Reason != "" {
+			compNode.add("Reason: %s", status.Reason)
+		}
+		if status.Diagnosis != "" {
+			compNode.add("Diagnosis: %s", status.Diagnosis)
+		}
+		for _, cond := range status.Conditions {
+			compNode.add("Condition %s=%s (%s: %s)", cond.Type, cond.Status, cond.Reason, cond.Message)
+		}
+		for _, conflict := range status.Conflicts {
+			compNode.add("Conflict %s %s owned by %s", conflict.Resource, conflict.Field, conflict.Manager)
+		}
+
+		parent := compNode
+		if status.Kind != "" && status.ResourceName != "" {
+			parent = compNode.add("%s/%s", status.Kind, status.ResourceName)
+		}
+		compPods := podsByComponent[comp.Name]
+		sort.Slice(compPods, func(i, j int) bool { return compPods[i].Name < compPods[j].Name })
+		for _, pod := range compPods {
+			podNode := parent.add("Pod/%s  %s", pod.Name, describePod(&pod))
+			for _, volume := range pod.Spec.Volumes {
+				if volume.PersistentVolumeClaim == nil {
+					continue
+				}
+				claimName := volume.PersistentVolumeClaim.ClaimName
+				pvc, found := pvcByName[claimName]
+				if !found {
+					podNode.add("PersistentVolumeClaim/%s  missing", claimName)
+					continue
+				}
+				podNode.add("PersistentVolumeClaim/%s  %s", claimName, describePVC(&pvc))
+			}
+		}
+	}
+	return root
+}
+
+// describePod summarizes a pod's phase, readiness, restarts and waiting reasons.
+func describePod(pod *corev1.Pod) string {
+	ready, total, restarts := 0, len(pod.Spec.Containers), int32(0)
+	var waiting []string
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Ready {
+			ready++
+		}
+		restarts += cs.RestartCount
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+			waiting = append(waiting, fmt.Sprintf("%s: %s", cs.Name, cs.State.Waiting.Reason))
+		}
+	}
+	text := fmt.Sprintf("%s ready=%d/%d restarts=%d", valueOr(string(pod.Status.Phase), "Unknown"), ready, total, restarts)
+	if len(waiting) > 0 {
+		text += " (" + strings.Join(waiting, ", ") + ")"
+	}
+	return text
+}
+
+// describePVC summarizes a claim's phase and capacity.
+func describePVC(pvc *corev1.PersistentVolumeClaim) string {
+	text := valueOr(string(pvc.Status.Phase), "Unknown")
+	if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+		text += " " + capacity.String()
+	}
+	return text
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,273 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// internal/render/render.go
+// Package render builds the manifests of ApplicationDefinitions offline, without a cluster.
+// It runs the same build path as the controller against a fake client.
+package render
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	appcontroller "github.com/infinilabs/runtime-operator/internal/controller/app"
+)
+
+// Format is the output format of rendered manifests.
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+// Manifest is a single rendered object.
+type Manifest struct {
+	// FileName is the name the manifest is written to, and compared against, in a directory.
+	FileName string
+	// Object is the rendered object, without status and server-populated null fields.
+	Object map[string]interface{}
+}
+
+// Renderer renders ApplicationDefinitions with the registered builder strategies.
+type Renderer struct {
+	scheme     *runtime.Scheme
+	reconciler *appcontroller.ApplicationDefinitionReconciler
+}
+
+// NewRenderer returns a renderer backed by a fake client. Objects referenced by the applications,
+// such as external ConfigMaps or Secrets, can be passed as existing objects; missing ones render as
+// they would against a cluster where they do not exist.
+func NewRenderer(scheme *runtime.Scheme, existing ...client.Object) *Renderer {
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing...).Build()
+	return &Renderer{
+		scheme: scheme,
+		reconciler: &appcontroller.ApplicationDefinitionReconciler{
+			Client:   fakeClient,
+			Scheme:   scheme,
+			Recorder: &record.FakeRecorder{}, // No Events channel, events are dropped
+		},
+	}
+}
+
+// DecodeApplications reads ApplicationDefinitions from a multi-document YAML or JSON stream.
+// Objects without a namespace are placed in defaultNamespace.
+func DecodeApplications(r io.Reader, defaultNamespace string) ([]*appv1.ApplicationDefinition, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	var apps []*appv1.ApplicationDefinition
+	for doc := 1; ; doc++ {
+		raw := map[string]interface{}{}
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return apps, nil
+			}
+			return nil, fmt.Errorf("failed to decode document %d: %w", doc, err)
+		}
+		if len(raw) == 0 {
+			continue // Empty document
+		}
+		if kind, _ := raw["kind"].(string); kind != "ApplicationDefinition" {
+			return nil, fmt.Errorf("document %d is a %q, expected ApplicationDefinition", doc, kind)
+		}
+
+		appDef := &appv1.ApplicationDefinition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, appDef); err != nil {
+			return nil, fmt.Errorf("failed to convert document %d: %w", doc, err)
+		}
+		if appDef.Namespace == "" {
+			appDef.Namespace = defaultNamespace
+		}
+		apps = append(apps, appDef)
+	}
+}
+
+// Render builds the manifests of an application, in the order the builders return them.
+func (r *Renderer) Render(ctx context.Context, appDef *appv1.ApplicationDefinition) ([]Manifest, error) {
+	appDef = appDef.DeepCopy()
+	// Rendering must never report to the change webhook
+	delete(appDef.Annotations, appv1.AnnotationChangeWebhookURL)
+
+	objects, err := r.reconciler.RenderObjects(ctx, appDef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", appDef.Name, err)
+	}
+
+	manifests := make([]Manifest, 0, len(objects))
+	for _, obj := range objects {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %w", obj.GetName(), err)
+		}
+		delete(content, "status")
+		pruneNulls(content)
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		manifests = append(manifests, Manifest{
+			FileName: strings.ToLower(fmt.Sprintf("%s_%s_%s.yaml", appDef.Name, kind, obj.GetName())),
+			Object:   content,
+		})
+	}
+	return manifests, nil
+}
+
+// pruneNulls removes null values such as creationTimestamp: null. Empty maps are kept, they can be
+// meaningful (e.g. emptyDir: {}).
+func pruneNulls(m map[string]interface{}) {
+	for key, value := range m {
+		switch v := value.(type) {
+		case nil:
+			delete(m, key)
+		case map[string]interface{}:
+			pruneNulls(v)
+		case []interface{}:
+			for _, item := range v {
+				if itemMap, ok := item.(map[string]interface{}); ok {
+					pruneNulls(itemMap)
+				}
+			}
+		}
+	}
+}
+
+// Encode writes manifests as a multi-document YAML stream or as a JSON v1 List.
+func Encode(w io.Writer, manifests []Manifest, format Format) error {
+	switch format {
+	case FormatJSON:
+		items := make([]map[string]interface{}, 0, len(manifests))
+		for _, m := range manifests {
+			items = append(items, m.Object)
+		}
+		list := map[string]interface{}{"apiVersion": "v1", "kind": "List", "items": items}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(list)
+	case FormatYAML, "":
+		for i, m := range manifests {
+			data, err := yaml.Marshal(m.Object)
+			if err != nil {
+				return fmt.Errorf("failed to marshal %s: %w", m.FileName, err)
+			}
+			if i > 0 {
+				if _, err := io.WriteString(w, "---\n"); err != nil {
+					return err
+				}
+			}
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q, expected yaml or json", format)
+	}
+}
+
+// WriteDir writes every manifest to its own YAML file in dir.
+func WriteDir(dir string, manifests []Manifest) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, m := range manifests {
+		data, err := yaml.Marshal(m.Object)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", m.FileName, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, m.FileName), data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Diff compares manifests with the YAML files previously written to dir by WriteDir.
+// It returns a unified diff, empty when nothing changed. Files in dir that are no longer
+// rendered are reported as removed.
+func Diff(dir string, manifests []Manifest) (string, error) {
+	var out bytes.Buffer
+	rendered := make(map[string]bool, len(manifests))
+	for _, m := range manifests {
+		rendered[m.FileName] = true
+		data, err := yaml.Marshal(m.Object)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal %s: %w", m.FileName, err)
+		}
+		previous, err := os.ReadFile(filepath.Join(dir, m.FileName))
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		if err := writeUnifiedDiff(&out, m.FileName, string(previous), string(data)); err != nil {
+			return "", err
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	var removed []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".yaml") && !rendered[entry.Name()] {
+			removed = append(removed, entry.Name())
+		}
+	}
+	sort.Strings(removed)
+	for _, name := range removed {
+		previous, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return "", err
+		}
+		if err := writeUnifiedDiff(&out, name, string(previous), ""); err != nil {
+			return "", err
+		}
+	}
+	return out.String(), nil
+}
+
+func writeUnifiedDiff(w io.Writer, name, before, after string) error {
+	if before == after {
+		return nil
+	}
+	return difflib.WriteUnifiedDiff(w, difflib.UnifiedDiff{
+		A:        difflib.SplitLines(before),
+		B:        difflib.SplitLines(after),
+		FromFile: "a/" + name,
+		ToFile:   "b/" + name,
+		Context:  3,
+	})
+}
@@ -0,0 +1,119 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	_ "github.com/infinilabs/runtime-operator/pkg/builders/runtime"
+)
+
+const testApp = `
+apiVersion: infini.cloud/v1
+kind: ApplicationDefinition
+metadata:
+  name: demo
+spec:
+  components:
+    - name: web
+      apiVersion: apps/v1
+      kind: StatefulSet
+      type: operator
+      properties:
+        image: {repository: nginx, tag: "1.21"}
+        replicas: 2
+        storage: {enabled: true, size: 1Gi, mountPath: /data}
+        ports: [{containerPort: 80, name: http}]
+`
+
+func renderTestApp(t *testing.T, manifest string) []Manifest {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(appv1.AddToScheme(scheme))
+
+	apps, err := DecodeApplications(strings.NewReader(manifest), "tenant")
+	if err != nil {
+		t.Fatalf("DecodeApplications() error = %v", err)
+	}
+	if len(apps) != 1 || apps[0].Namespace != "tenant" {
+		t.Fatalf("DecodeApplications() = %d apps, want 1 in the default namespace", len(apps))
+	}
+	manifests, err := NewRenderer(scheme).Render(context.Background(), apps[0])
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	return manifests
+}
+
+func TestRenderSetsLabelsAndOwner(t *testing.T) {
+	manifests := renderTestApp(t, testApp)
+	if len(manifests) == 0 {
+		t.Fatal("Render() returned no manifests")
+	}
+
+	var foundWorkload bool
+	for _, m := range manifests {
+		metadata := m.Object["metadata"].(map[string]interface{})
+		labels := metadata["labels"].(map[string]interface{})
+		if labels["infini.cloud/application-name"] != "demo" || labels["infini.cloud/component-instance"] != "web" {
+			t.Errorf("%s: missing standard labels, got %v", m.FileName, labels)
+		}
+		owners, _ := metadata["ownerReferences"].([]interface{})
+		if len(owners) != 1 || owners[0].(map[string]interface{})["kind"] != "ApplicationDefinition" {
+			t.Errorf("%s: expected the ApplicationDefinition as controller owner, got %v", m.FileName, owners)
+		}
+		if _, ok := m.Object["status"]; ok {
+			t.Errorf("%s: status should not be rendered", m.FileName)
+		}
+		if m.Object["kind"] == "StatefulSet" {
+			foundWorkload = true
+		}
+	}
+	if !foundWorkload {
+		t.Error("expected a StatefulSet among the rendered manifests")
+	}
+
+	var out bytes.Buffer
+	if err := Encode(&out, manifests, FormatYAML); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if got := strings.Count(out.String(), "\n---\n"); got != len(manifests)-1 {
+		t.Errorf("Encode() produced %d separators for %d manifests", got, len(manifests))
+	}
+}
+
+func TestDiffAgainstDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteDir(dir, renderTestApp(t, testApp)); err != nil {
+		t.Fatalf("WriteDir() error = %v", err)
+	}
+
+	diff, err := Diff(dir, renderTestApp(t, testApp))
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if diff != "" {
+		t.Fatalf("Diff() of an unchanged render = %q, want empty", diff)
+	}
+
+	diff, err = Diff(dir, renderTestApp(t, strings.Replace(testApp, "replicas: 2", "replicas: 4", 1)))
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if !strings.Contains(diff, "-  replicas: 2") || !strings.Contains(diff, "+  replicas: 4") {
+		t.Errorf("Diff() did not report the replica change:\n%s", diff)
+	}
+}
+
+func TestDecodeApplicationsRejectsOtherKinds(t *testing.T) {
+	if _, err := DecodeApplications(strings.NewReader("apiVersion: v1\nkind: ConfigMap\n"), "default"); err == nil {
+		t.Error("expected an error for a non-ApplicationDefinition document")
+	}
+}
@@ -32,4 +32,18 @@ const (
 	ManagedByLabel         = "app.kubernetes.io/managed-by"    // Standard Kubernetes label
 	OperatorName           = "runtime-operator"                // Name of this operator
 	InClusterNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+	ServiceRoleLabel       = "infini.cloud/service-role" // Label telling a component's Services apart
+)
+
+// Values of ServiceRoleLabel.
+const (
+	// ServiceRoleHeadless marks the headless Service of a component, selected by its ServiceMonitor.
+	ServiceRoleHeadless = "headless"
+)
+
+// Pod template annotations managed by the operator.
+const (
+	// ConfigChecksumAnnotation holds a checksum over all configuration consumed by a workload's pods.
+	// It only changes when that configuration changes, which triggers exactly one rolling restart.
+	ConfigChecksumAnnotation = "checksum/config"
 )
@@ -0,0 +1,79 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// pkg/apis/common/references.go
+package common
+
+import "sort"
+
+// ReferencedConfigMaps returns the sorted, de-duplicated names of existing ConfigMaps
+// the runtime consumes through ConfigMounts and EnvFrom.
+func (c *RuntimeConfig) ReferencedConfigMaps() []string {
+	if c == nil {
+		return nil
+	}
+	names := map[string]struct{}{}
+	for _, m := range c.ConfigMounts {
+		if m.Name != "" {
+			names[m.Name] = struct{}{}
+		}
+	}
+	for _, src := range c.EnvFrom {
+		if src.ConfigMapRef != nil && src.ConfigMapRef.Name != "" {
+			names[src.ConfigMapRef.Name] = struct{}{}
+		}
+	}
+	return sortedKeys(names)
+}
+
+// ReferencedSecrets returns the sorted, de-duplicated names of existing Secrets
+// the runtime consumes through SecretMounts and EnvFrom.
+func (c *RuntimeConfig) ReferencedSecrets() []string {
+	if c == nil {
+		return nil
+	}
+	names := map[string]struct{}{}
+	for _, m := range c.SecretMounts {
+		if m.SecretName != "" {
+			names[m.SecretName] = struct{}{}
+		}
+	}
+	for _, src := range c.EnvFrom {
+		if src.SecretRef != nil && src.SecretRef.Name != "" {
+			names[src.SecretRef.Name] = struct{}{}
+		}
+	}
+	return sortedKeys(names)
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
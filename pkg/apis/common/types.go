@@ -150,6 +150,102 @@ type StorageSpec struct {
 	DataSubpath *string `json:"dataSubpath,omitempty"`
 }
 
+// AppHealthTarget selects what an application health check is evaluated against.
+// +kubebuilder:validation:Enum=Pods;Service
+type AppHealthTarget string
+
+const (
+	// AppHealthTargetPods checks every running pod of the component individually.
+	AppHealthTargetPods AppHealthTarget = "Pods"
+	// AppHealthTargetService checks once through the component's client (or headless) Service.
+	AppHealthTargetService AppHealthTarget = "Service"
+)
+
+// AppHealthSpec defines an HTTP application-level health check.
+type AppHealthSpec struct {
+	// Path is the HTTP path to query (e.g. "/_cluster/health"). Defaults to "/".
+	// +optional
+	Path string `json:"path,omitempty"`
+	// Port is the name of one of the component's ports.
+	// +kubebuilder:validation:Required
+	Port string `json:"port"`
+	// Scheme is HTTP or HTTPS. Defaults to HTTP.
+	// +kubebuilder:validation:Enum=HTTP;HTTPS
+	// +optional
+	Scheme corev1.URIScheme `json:"scheme,omitempty"`
+	// ExpectedStatusCode is the HTTP status code a healthy application returns. Defaults to 200.
+	// +optional
+	ExpectedStatusCode *int32 `json:"expectedStatusCode,omitempty"`
+	// Assertions are evaluated against the JSON response body; all must hold.
+	// +optional
+	Assertions []JSONPathAssertion `json:"assertions,omitempty"`
+	// Target selects whether each pod or the Service is checked. Defaults to Pods.
+	// +optional
+	Target AppHealthTarget `json:"target,omitempty"`
+	// TLS configures certificate verification for HTTPS checks.
+	// +optional
+	TLS *AppHealthTLSSpec `json:"tls,omitempty"`
+	// BasicAuthSecretRef references a Secret with "username" and "password" keys used for basic auth.
+	// +optional
+	BasicAuthSecretRef *corev1.LocalObjectReference `json:"basicAuthSecretRef,omitempty"`
+	// TimeoutSeconds is the timeout of a single HTTP request. Defaults to 5.
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// JSONPathAssertion asserts on a value in the JSON response body.
+type JSONPathAssertion struct {
+	// JSONPath is a Kubernetes JSONPath expression, e.g. "{.status}".
+	// +kubebuilder:validation:Required
+	JSONPath string `json:"jsonPath"`
+	// Values lists the accepted values (any of). If empty, the path only has to exist.
+	// +optional
+	Values []string `json:"values,omitempty"`
+}
+
+// AppHealthTLSSpec configures TLS for HTTPS application health checks.
+type AppHealthTLSSpec struct {
+	// CASecretRef selects a Secret key holding the PEM CA bundle used to verify the server.
+	// +optional
+	CASecretRef *corev1.SecretKeySelector `json:"caSecretRef,omitempty"`
+	// ServerName overrides the name used to verify the server certificate.
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+	// InsecureSkipVerify disables certificate verification. Use only for testing.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// MonitorKind selects the Prometheus Operator resource generated for a component's metrics endpoint.
+// +kubebuilder:validation:Enum=ServiceMonitor;PodMonitor
+type MonitorKind string
+
+const (
+	// MonitorKindServiceMonitor scrapes the pods through the component's headless Service.
+	MonitorKindServiceMonitor MonitorKind = "ServiceMonitor"
+	// MonitorKindPodMonitor scrapes the pods directly.
+	MonitorKindPodMonitor MonitorKind = "PodMonitor"
+)
+
+// MonitoringSpec describes the metrics endpoint of a component. When the Prometheus Operator CRDs
+// (monitoring.coreos.com) are installed, an owned ServiceMonitor or PodMonitor is generated for it;
+// otherwise the component's Service gets the conventional prometheus.io/* scrape annotations.
+type MonitoringSpec struct {
+	// Port is the name of one of the component's ports serving the metrics.
+	// +kubebuilder:validation:Required
+	Port string `json:"port"`
+	// Path is the HTTP path of the metrics. Defaults to "/metrics".
+	// +optional
+	Path string `json:"path,omitempty"`
+	// Interval is the scrape interval as a Prometheus duration, e.g. "30s". Defaults to the
+	// Prometheus scrape interval.
+	// +optional
+	Interval string `json:"interval,omitempty"`
+	// Kind selects the generated resource. Defaults to ServiceMonitor.
+	// +optional
+	Kind MonitorKind `json:"kind,omitempty"`
+}
+
 // ConfigMountSpec defines how to mount a ConfigMap as a volume.
 type ConfigMountSpec struct {
 	// +kubebuilder:validation:Required
@@ -277,6 +373,15 @@ type RuntimeConfig struct {
 	// +optional
 	Probes *ProbesConfig `json:"probes,omitempty"`
 
+	// AppHealth defines an application-level HTTP health check, evaluated after the workload is ready.
+	// The result is reported as the component's AppHealthy condition.
+	// +optional
+	AppHealth *AppHealthSpec `json:"appHealth,omitempty"`
+
+	// Monitoring describes the metrics endpoint scraped by Prometheus.
+	// +optional
+	Monitoring *MonitoringSpec `json:"monitoring,omitempty"`
+
 	// ContainerSecurityContext defines security settings specific to the main container.
 	// +optional
 	ContainerSecurityContext *ContainerSecurityContextSpec `json:"containerSecurityContext,omitempty"` // Likely *corev1.SecurityContext
@@ -0,0 +1,330 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// pkg/apphealth/apphealth.go
+// Package apphealth evaluates declarative HTTP application health checks (RuntimeConfig.AppHealth).
+package apphealth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+)
+
+const (
+	defaultPath           = "/"
+	defaultStatusCode     = 200
+	defaultTimeoutSeconds = 5
+	// maxBodyBytes bounds the response body read for assertions
+	maxBodyBytes = 1 << 20
+	// maxTransports bounds the cached transports; the cache is reset when it is full
+	maxTransports = 64
+)
+
+// transportKey identifies the TLS settings of a cached transport.
+type transportKey struct {
+	tls                bool
+	serverName         string
+	insecureSkipVerify bool
+	caSum              [sha256.Size]byte
+}
+
+// transports caches one transport per distinct TLS configuration, so periodic checks reuse
+// their keep-alive connections instead of leaving a new idle pool behind on every check.
+var transports = struct {
+	sync.Mutex
+	byKey map[transportKey]*http.Transport
+}{byKey: map[transportKey]*http.Transport{}}
+
+// BasicAuth holds resolved basic auth credentials.
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// Target describes where a component's health check is sent.
+type Target struct {
+	// ServiceName is the Service used for AppHealthTargetService.
+	ServiceName string
+	// SelectorLabels select the component's pods for AppHealthTargetPods.
+	SelectorLabels map[string]string
+}
+
+// Evaluate performs a single HTTP health check against url and evaluates the spec's
+// expected status code and JSONPath assertions. The returned message explains the outcome.
+// An error is only returned when the request could not be performed at all.
+func Evaluate(ctx context.Context, httpClient *http.Client, url string, spec *common.AppHealthSpec, auth *BasicAuth) (bool, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, fmt.Sprintf("invalid health check request for %s", url), err
+	}
+	req.Header.Set("Accept", "application/json")
+	if auth != nil {
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Sprintf("health check request to %s failed: %v", url, err), err
+	}
+	defer resp.Body.Close()
+
+	expected := defaultStatusCode
+	if spec.ExpectedStatusCode != nil {
+		expected = int(*spec.ExpectedStatusCode)
+	}
+	if resp.StatusCode != expected {
+		return false, fmt.Sprintf("%s returned status %d, expected %d", url, resp.StatusCode, expected), nil
+	}
+
+	if len(spec.Assertions) == 0 {
+		return true, fmt.Sprintf("%s returned status %d", url, resp.StatusCode), nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	if err != nil {
+		return false, fmt.Sprintf("failed to read response body from %s: %v", url, err), err
+	}
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return false, fmt.Sprintf("response from %s is not valid JSON: %v", url, err), nil
+	}
+
+	for _, assertion := range spec.Assertions {
+		ok, msg := evaluateAssertion(data, assertion)
+		if !ok {
+			return false, fmt.Sprintf("%s: %s", url, msg), nil
+		}
+	}
+	return true, fmt.Sprintf("%s returned status %d and passed %d assertion(s)", url, resp.StatusCode, len(spec.Assertions)), nil
+}
+
+// evaluateAssertion checks one JSONPath assertion against the decoded body.
+func evaluateAssertion(data interface{}, assertion common.JSONPathAssertion) (bool, string) {
+	expr := strings.TrimSpace(assertion.JSONPath)
+	if !strings.HasPrefix(expr, "{") {
+		expr = "{" + expr + "}"
+	}
+	jp := jsonpath.New("assertion")
+	if err := jp.Parse(expr); err != nil {
+		return false, fmt.Sprintf("invalid JSONPath %q: %v", assertion.JSONPath, err)
+	}
+	results, err := jp.FindResults(data)
+	if err != nil {
+		return false, fmt.Sprintf("JSONPath %q not found in response", assertion.JSONPath)
+	}
+
+	var actual []string
+	for _, set := range results {
+		for _, v := range set {
+			actual = append(actual, fmt.Sprint(v.Interface()))
+		}
+	}
+	if len(actual) == 0 {
+		return false, fmt.Sprintf("JSONPath %q not found in response", assertion.JSONPath)
+	}
+	if len(assertion.Values) == 0 {
+		return true, ""
+	}
+	for _, got := range actual {
+		for _, want := range assertion.Values {
+			if got == want {
+				return true, ""
+			}
+		}
+	}
+	return false, fmt.Sprintf("JSONPath %q is %v, expected one of %v", assertion.JSONPath, actual, assertion.Values)
+}
+
+// Check resolves the targets, credentials and TLS settings of a component's AppHealth spec
+// and evaluates it. For the Pods target every running pod must pass; as for the Service target, an
+// error is returned when the request to a pod could not be performed at all.
+func Check(ctx context.Context, k8sClient client.Client, namespace string, cfg *common.RuntimeConfig, target Target) (bool, string, error) {
+	spec := cfg.AppHealth
+	if spec == nil {
+		return true, "No application health check configured", nil
+	}
+
+	port, err := resolvePort(cfg.Ports, spec.Port)
+	if err != nil {
+		return false, err.Error(), err
+	}
+
+	httpClient, err := buildHTTPClient(ctx, k8sClient, namespace, spec)
+	if err != nil {
+		return false, fmt.Sprintf("failed to prepare health check client: %v", err), err
+	}
+	auth, err := resolveBasicAuth(ctx, k8sClient, namespace, spec.BasicAuthSecretRef)
+	if err != nil {
+		return false, fmt.Sprintf("failed to resolve basic auth: %v", err), err
+	}
+
+	scheme := strings.ToLower(string(spec.Scheme))
+	if scheme == "" {
+		scheme = "http"
+	}
+	path := spec.Path
+	if path == "" {
+		path = defaultPath
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	if spec.Target == common.AppHealthTargetService {
+		host := fmt.Sprintf("%s.%s.svc", target.ServiceName, namespace)
+		url := fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, strconv.Itoa(int(port))), path)
+		return Evaluate(ctx, httpClient, url, spec, auth)
+	}
+
+	// Default target: every running pod
+	podList := &corev1.PodList{}
+	if err := k8sClient.List(ctx, podList, client.InNamespace(namespace), client.MatchingLabels(target.SelectorLabels)); err != nil {
+		return false, fmt.Sprintf("failed to list pods: %v", err), err
+	}
+	checked := 0
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		url := fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(port))), path)
+		healthy, msg, err := Evaluate(ctx, httpClient, url, spec, auth)
+		if err != nil {
+			return false, fmt.Sprintf("pod %s: %s", pod.Name, msg), fmt.Errorf("pod %s: %w", pod.Name, err)
+		}
+		if !healthy {
+			return false, fmt.Sprintf("pod %s: %s", pod.Name, msg), nil
+		}
+		checked++
+	}
+	if checked == 0 {
+		return false, "No running pods to check application health against", nil
+	}
+	return true, fmt.Sprintf("Application health check passed on %d pod(s)", checked), nil
+}
+
+// resolvePort returns the container port number of the named port.
+func resolvePort(ports []common.PortSpec, name string) (int32, error) {
+	for _, p := range ports {
+		if p.Name == name {
+			return p.ContainerPort, nil
+		}
+	}
+	return 0, fmt.Errorf("appHealth port %q is not defined in ports", name)
+}
+
+// buildHTTPClient returns a client with the spec's timeout, sharing the cached transport of its TLS settings.
+func buildHTTPClient(ctx context.Context, k8sClient client.Client, namespace string, spec *common.AppHealthSpec) (*http.Client, error) {
+	timeout := time.Duration(defaultTimeoutSeconds) * time.Second
+	if spec.TimeoutSeconds != nil && *spec.TimeoutSeconds > 0 {
+		timeout = time.Duration(*spec.TimeoutSeconds) * time.Second
+	}
+
+	var key transportKey
+	var ca []byte
+	if spec.TLS != nil {
+		key = transportKey{tls: true, serverName: spec.TLS.ServerName, insecureSkipVerify: spec.TLS.InsecureSkipVerify}
+		if spec.TLS.CASecretRef != nil {
+			var err error
+			if ca, err = readSecretKey(ctx, k8sClient, namespace, spec.TLS.CASecretRef.Name, spec.TLS.CASecretRef.Key); err != nil {
+				return nil, err
+			}
+			key.caSum = sha256.Sum256(ca)
+		}
+	}
+
+	transports.Lock()
+	defer transports.Unlock()
+	if transport, ok := transports.byKey[key]; ok {
+		return &http.Client{Timeout: timeout, Transport: transport}, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if spec.TLS != nil {
+		tlsConfig := &tls.Config{
+			ServerName:         spec.TLS.ServerName,
+			InsecureSkipVerify: spec.TLS.InsecureSkipVerify, // #nosec G402 -- explicit opt-in
+		}
+		if ca != nil {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("secret %s key %s does not contain a PEM CA bundle", spec.TLS.CASecretRef.Name, spec.TLS.CASecretRef.Key)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	if len(transports.byKey) >= maxTransports {
+		// CA bundles were rotated many times; drop the stale transports
+		for _, stale := range transports.byKey {
+			stale.CloseIdleConnections()
+		}
+		clear(transports.byKey)
+	}
+	transports.byKey[key] = transport
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
+
+func resolveBasicAuth(ctx context.Context, k8sClient client.Client, namespace string, ref *corev1.LocalObjectReference) (*BasicAuth, error) {
+	if ref == nil || ref.Name == "" {
+		return nil, nil
+	}
+	username, err := readSecretKey(ctx, k8sClient, namespace, ref.Name, "username")
+	if err != nil {
+		return nil, err
+	}
+	password, err := readSecretKey(ctx, k8sClient, namespace, ref.Name, "password")
+	if err != nil {
+		return nil, err
+	}
+	return &BasicAuth{Username: string(username), Password: string(password)}, nil
+}
+
+func readSecretKey(ctx context.Context, k8sClient client.Client, namespace, name, key string) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get secret %s: %w", name, err)
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s has no key %s", name, key)
+	}
+	return value, nil
+}
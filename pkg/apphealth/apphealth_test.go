@@ -0,0 +1,194 @@
+package apphealth
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+)
+
+func TestEvaluate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "admin" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/_cluster/health":
+			_, _ = w.Write([]byte(`{"cluster_name":"es","status":"yellow","number_of_nodes":3}`))
+		case "/down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = w.Write([]byte(`not json`))
+		}
+	}))
+	defer server.Close()
+
+	auth := &BasicAuth{Username: "admin", Password: "secret"}
+	statusOK := int32(http.StatusOK)
+
+	tests := []struct {
+		name    string
+		path    string
+		spec    common.AppHealthSpec
+		auth    *BasicAuth
+		healthy bool
+	}{
+		{
+			name:    "status and any-of assertion pass",
+			path:    "/_cluster/health",
+			spec:    common.AppHealthSpec{Assertions: []common.JSONPathAssertion{{JSONPath: "{.status}", Values: []string{"green", "yellow"}}}},
+			auth:    auth,
+			healthy: true,
+		},
+		{
+			name:    "assertion without braces and numeric value",
+			path:    "/_cluster/health",
+			spec:    common.AppHealthSpec{Assertions: []common.JSONPathAssertion{{JSONPath: ".number_of_nodes", Values: []string{"3"}}}},
+			auth:    auth,
+			healthy: true,
+		},
+		{
+			name:    "assertion value mismatch",
+			path:    "/_cluster/health",
+			spec:    common.AppHealthSpec{Assertions: []common.JSONPathAssertion{{JSONPath: "{.status}", Values: []string{"green"}}}},
+			auth:    auth,
+			healthy: false,
+		},
+		{
+			name:    "existence assertion on missing path",
+			path:    "/_cluster/health",
+			spec:    common.AppHealthSpec{Assertions: []common.JSONPathAssertion{{JSONPath: "{.missing}"}}},
+			auth:    auth,
+			healthy: false,
+		},
+		{
+			name:    "unexpected status code",
+			path:    "/down",
+			spec:    common.AppHealthSpec{ExpectedStatusCode: &statusOK},
+			auth:    auth,
+			healthy: false,
+		},
+		{
+			name:    "missing credentials",
+			path:    "/_cluster/health",
+			spec:    common.AppHealthSpec{},
+			healthy: false,
+		},
+		{
+			name:    "non JSON body with assertions",
+			path:    "/other",
+			spec:    common.AppHealthSpec{Assertions: []common.JSONPathAssertion{{JSONPath: "{.status}"}}},
+			auth:    auth,
+			healthy: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healthy, msg, err := Evaluate(context.Background(), server.Client(), server.URL+tt.path, &tt.spec, tt.auth)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if healthy != tt.healthy {
+				t.Errorf("Evaluate() healthy = %v, want %v (message: %s)", healthy, tt.healthy, msg)
+			}
+		})
+	}
+}
+
+func TestEvaluateConnectionError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	healthy, _, err := Evaluate(context.Background(), http.DefaultClient, url, &common.AppHealthSpec{}, nil)
+	if healthy || err == nil {
+		t.Errorf("Evaluate() against closed server = (%v, %v), want (false, error)", healthy, err)
+	}
+}
+
+func TestCheckPodsTarget(t *testing.T) {
+	healthy := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy || r.URL.Path != "/health" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	host, portStr, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, _ := strconv.Atoi(portStr)
+
+	selector := map[string]string{"app.kubernetes.io/instance": "search"}
+	pod := func(name string, phase corev1.PodPhase, ip string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: selector},
+			Status:     corev1.PodStatus{Phase: phase, PodIP: ip},
+		}
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		pod("search-0", corev1.PodRunning, host),
+		pod("search-1", corev1.PodPending, ""),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "other-0", Namespace: "default"}, Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "192.0.2.1"}},
+	).Build()
+	cfg := &common.RuntimeConfig{
+		Ports:     []common.PortSpec{{Name: "http", ContainerPort: int32(port)}},
+		AppHealth: &common.AppHealthSpec{Port: "http", Path: "health"},
+	}
+	target := Target{ServiceName: "search", SelectorLabels: selector}
+
+	ok, msg, err := Check(context.Background(), k8sClient, "default", cfg, target)
+	if err != nil || !ok || !strings.Contains(msg, "1 pod(s)") {
+		t.Errorf("Check() = %v, %q, %v, want healthy on the running pod only", ok, msg, err)
+	}
+
+	healthy = false
+	ok, msg, err = Check(context.Background(), k8sClient, "default", cfg, target)
+	if err != nil || ok || !strings.Contains(msg, "pod search-0") {
+		t.Errorf("Check() = %v, %q, %v, want unhealthy naming pod search-0", ok, msg, err)
+	}
+
+	unreachable := *cfg
+	unreachable.Ports = []common.PortSpec{{Name: "http", ContainerPort: 1}}
+	if ok, msg, err := Check(context.Background(), k8sClient, "default", &unreachable, target); err == nil || ok || !strings.Contains(msg, "pod search-0") {
+		t.Errorf("Check() = %v, %q, %v, want the request error of pod search-0", ok, msg, err)
+	}
+
+	emptyClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	if ok, msg, _ := Check(context.Background(), emptyClient, "default", cfg, target); ok {
+		t.Errorf("Check() without running pods = healthy, %q", msg)
+	}
+}
+
+func TestBuildHTTPClientReusesTransport(t *testing.T) {
+	ctx := context.Background()
+	plain := &common.AppHealthSpec{Port: "http"}
+	first, err := buildHTTPClient(ctx, nil, "default", plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := buildHTTPClient(ctx, nil, "default", plain)
+	if first.Transport != second.Transport {
+		t.Error("checks with the same TLS settings do not share their transport")
+	}
+	insecure, _ := buildHTTPClient(ctx, nil, "default", &common.AppHealthSpec{Port: "http", TLS: &common.AppHealthTLSSpec{InsecureSkipVerify: true}})
+	if insecure.Transport == first.Transport {
+		t.Error("checks with different TLS settings share their transport")
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// pkg/builders/k8s/checksum.go
+package k8s
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// missingConfigMarker is hashed in place of a referenced object that does not exist (yet),
+// so that its later creation changes the checksum as well.
+const missingConfigMarker = "<missing>"
+
+// ComputeConfigChecksum returns a deterministic checksum over the data of the generated
+// ConfigMaps/Secrets and of the existing ConfigMaps/Secrets referenced by name.
+// Metadata is ignored, so the checksum only changes when configuration content changes.
+// An empty string is returned when there is no configuration to hash.
+func ComputeConfigChecksum(ctx context.Context, k8sClient client.Client, namespace string,
+	generated []client.Object, configMapRefs, secretRefs []string) (string, error) {
+
+	entries := map[string][]byte{} // "<kind>/<name>" -> serialized data
+
+	for _, obj := range generated {
+		switch o := obj.(type) {
+		case *corev1.ConfigMap:
+			data, err := configMapDataBytes(o)
+			if err != nil {
+				return "", fmt.Errorf("failed to serialize ConfigMap %s: %w", o.Name, err)
+			}
+			entries["ConfigMap/"+o.Name] = data
+		case *corev1.Secret:
+			data, err := secretDataBytes(o)
+			if err != nil {
+				return "", fmt.Errorf("failed to serialize Secret %s: %w", o.Name, err)
+			}
+			entries["Secret/"+o.Name] = data
+		}
+	}
+
+	for _, name := range configMapRefs {
+		key := "ConfigMap/" + name
+		if _, exists := entries[key]; exists {
+			continue // Generated by the operator itself, already hashed
+		}
+		cm := &corev1.ConfigMap{}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, cm); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return "", fmt.Errorf("failed to get referenced ConfigMap %s: %w", name, err)
+			}
+			entries[key] = []byte(missingConfigMarker)
+			continue
+		}
+		data, err := configMapDataBytes(cm)
+		if err != nil {
+			return "", fmt.Errorf("failed to serialize ConfigMap %s: %w", name, err)
+		}
+		entries[key] = data
+	}
+
+	for _, name := range secretRefs {
+		key := "Secret/" + name
+		if _, exists := entries[key]; exists {
+			continue
+		}
+		secret := &corev1.Secret{}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return "", fmt.Errorf("failed to get referenced Secret %s: %w", name, err)
+			}
+			entries[key] = []byte(missingConfigMarker)
+			continue
+		}
+		data, err := secretDataBytes(secret)
+		if err != nil {
+			return "", fmt.Errorf("failed to serialize Secret %s: %w", name, err)
+		}
+		entries[key] = data
+	}
+
+	if len(entries) == 0 {
+		return "", nil
+	}
+
+	// Hash entries in a stable order
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte{0})
+		h.Write(entries[k])
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// configMapDataBytes serializes the data of a ConfigMap. json.Marshal sorts map keys.
+func configMapDataBytes(cm *corev1.ConfigMap) ([]byte, error) {
+	return json.Marshal(struct {
+		Data       map[string]string `json:"data,omitempty"`
+		BinaryData map[string][]byte `json:"binaryData,omitempty"`
+	}{cm.Data, cm.BinaryData})
+}
+
+// secretDataBytes serializes the data of a Secret, including not yet converted StringData.
+func secretDataBytes(secret *corev1.Secret) ([]byte, error) {
+	return json.Marshal(struct {
+		Data       map[string][]byte `json:"data,omitempty"`
+		StringData map[string]string `json:"stringData,omitempty"`
+	}{secret.Data, secret.StringData})
+}
@@ -0,0 +1,68 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestComputeConfigChecksum(t *testing.T) {
+	ctx := context.Background()
+	generated := []client.Object{&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "default"},
+		Data:       map[string]string{"app.yml": "a: 1"},
+	}}
+	external := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(external).Build()
+
+	sum1, err := ComputeConfigChecksum(ctx, k8sClient, "default", generated, nil, []string{"creds"})
+	if err != nil {
+		t.Fatalf("ComputeConfigChecksum() error = %v", err)
+	}
+	if sum1 == "" {
+		t.Fatal("ComputeConfigChecksum() returned an empty checksum")
+	}
+
+	// Stable across calls
+	sum2, _ := ComputeConfigChecksum(ctx, k8sClient, "default", generated, nil, []string{"creds"})
+	if sum1 != sum2 {
+		t.Errorf("checksum not stable: %s != %s", sum1, sum2)
+	}
+
+	// Metadata-only changes do not affect the checksum
+	external.Labels = map[string]string{"foo": "bar"}
+	if err := k8sClient.Update(ctx, external); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if sum, _ := ComputeConfigChecksum(ctx, k8sClient, "default", generated, nil, []string{"creds"}); sum != sum1 {
+		t.Errorf("metadata change altered checksum")
+	}
+
+	// Data changes of a referenced Secret do
+	external.Data["password"] = []byte("rotated")
+	if err := k8sClient.Update(ctx, external); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if sum, _ := ComputeConfigChecksum(ctx, k8sClient, "default", generated, nil, []string{"creds"}); sum == sum1 {
+		t.Errorf("secret data change did not alter checksum")
+	}
+
+	// A missing reference is hashed with a marker instead of failing
+	missing, err := ComputeConfigChecksum(ctx, k8sClient, "default", nil, []string{"absent"}, nil)
+	if err != nil || missing == "" {
+		t.Errorf("missing reference: checksum = %q, err = %v", missing, err)
+	}
+
+	// Nothing to hash
+	if sum, _ := ComputeConfigChecksum(ctx, k8sClient, "default", nil, nil, nil); sum != "" {
+		t.Errorf("expected empty checksum without config, got %s", sum)
+	}
+}
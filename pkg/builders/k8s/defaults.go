@@ -0,0 +1,96 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// pkg/builders/k8s/defaults.go
+package k8s
+
+import (
+	"strings"
+
+	configv1alpha1 "github.com/infinilabs/runtime-operator/api/config/v1alpha1"
+)
+
+// dockerHubRegistry is the registry of image references that do not name one.
+const dockerHubRegistry = "docker.io"
+
+// BuilderDefaults holds operator-wide defaults applied when a component leaves the corresponding field unset.
+type BuilderDefaults struct {
+	// InitContainerImage is the image of the init container preparing data directories.
+	InitContainerImage string
+	// StorageClassName is used by volume claims that do not name one; empty keeps the cluster default.
+	StorageClassName string
+	// RegistryMirrors maps a registry host to the mirror that replaces it in image references.
+	RegistryMirrors map[string]string
+}
+
+var defaults = BuilderDefaults{InitContainerImage: configv1alpha1.DefaultInitContainerImage}
+
+// SetBuilderDefaults replaces the operator-wide builder defaults.
+// It must be called before any component is built, typically once at startup.
+func SetBuilderDefaults(d BuilderDefaults) {
+	if d.InitContainerImage == "" {
+		d.InitContainerImage = configv1alpha1.DefaultInitContainerImage
+	}
+	defaults = d
+}
+
+// GetBuilderDefaults returns the operator-wide builder defaults.
+func GetBuilderDefaults() BuilderDefaults {
+	return defaults
+}
+
+// defaultStorageClassName returns the configured default storage class when className is unset.
+func defaultStorageClassName(className *string) *string {
+	if className != nil || defaults.StorageClassName == "" {
+		return className
+	}
+	sc := defaults.StorageClassName
+	return &sc
+}
+
+// MirrorImage rewrites the registry of an image reference according to the configured registry mirrors.
+// References without a registry are treated as Docker Hub images, e.g. "nginx" is "docker.io/library/nginx".
+func MirrorImage(image string) string {
+	if image == "" || len(defaults.RegistryMirrors) == 0 {
+		return image
+	}
+	registry, remainder := splitRegistry(image)
+	mirror, ok := defaults.RegistryMirrors[registry]
+	if !ok {
+		return image
+	}
+	return mirror + "/" + remainder
+}
+
+// splitRegistry splits an image reference into its registry host and the remaining repository path.
+func splitRegistry(image string) (string, string) {
+	first, rest, found := strings.Cut(image, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return first, rest
+	}
+	if !found {
+		// Official Docker Hub images live under the library namespace
+		return dockerHubRegistry, "library/" + image
+	}
+	return dockerHubRegistry, image
+}
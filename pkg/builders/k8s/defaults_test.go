@@ -0,0 +1,66 @@
+package k8s
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+)
+
+func TestMirrorImage(t *testing.T) {
+	previous := GetBuilderDefaults()
+	defer SetBuilderDefaults(previous)
+	SetBuilderDefaults(BuilderDefaults{RegistryMirrors: map[string]string{
+		"docker.io": "mirror.example.com/hub",
+		"ghcr.io":   "ghcr.example.com",
+	}})
+
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"nginx:latest", "mirror.example.com/hub/library/nginx:latest"},
+		{"infinilabs/console:1.0", "mirror.example.com/hub/infinilabs/console:1.0"},
+		{"docker.io/infinilabs/console", "mirror.example.com/hub/infinilabs/console"},
+		{"ghcr.io/org/app@sha256:abc", "ghcr.example.com/org/app@sha256:abc"},
+		{"quay.io/org/app", "quay.io/org/app"},
+		{"localhost:5000/app", "localhost:5000/app"},
+	}
+	for _, tt := range tests {
+		if got := MirrorImage(tt.image); got != tt.want {
+			t.Errorf("MirrorImage(%q) = %q, want %q", tt.image, got, tt.want)
+		}
+	}
+	if got := BuildImageName("nginx", "1.27"); got != "mirror.example.com/hub/library/nginx:1.27" {
+		t.Errorf("BuildImageName did not apply the mirror, got %q", got)
+	}
+}
+
+func TestBuilderDefaultsApplyToUnsetFields(t *testing.T) {
+	previous := GetBuilderDefaults()
+	defer SetBuilderDefaults(previous)
+	SetBuilderDefaults(BuilderDefaults{InitContainerImage: "registry.local/busybox:1.36", StorageClassName: "fast"})
+
+	if c := BuildEnsureDirectoryContainer("init", "", "/data", 1000, 1000); c.Image != "registry.local/busybox:1.36" {
+		t.Errorf("expected the configured init image, got %q", c.Image)
+	}
+
+	size := resource.MustParse("1Gi")
+	templates, err := BuildVolumeClaimTemplates(&common.StorageSpec{Enabled: true, Size: &size, MountPath: "/data"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc := templates[0].Spec.StorageClassName; sc == nil || *sc != "fast" {
+		t.Errorf("expected the default storage class, got %v", sc)
+	}
+
+	explicit := "standard"
+	templates, err = BuildVolumeClaimTemplates(&common.StorageSpec{Enabled: true, Size: &size, MountPath: "/data", StorageClassName: &explicit}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc := templates[0].Spec.StorageClassName; sc == nil || *sc != "standard" {
+		t.Errorf("expected the explicit storage class to win, got %v", sc)
+	}
+}
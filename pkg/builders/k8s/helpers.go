@@ -117,16 +117,16 @@ func BuildObjectMeta(name, namespace string, labels, annotations map[string]stri
 
 // --- K8s Spec Field Helpers ---
 
-// BuildImageName constructs the full container image string.
+// BuildImageName constructs the full container image string, applying the configured registry mirrors.
 func BuildImageName(repository, tag string) string {
 	if repository == "" {
 		return "" // Should likely error upstream if image is required
 	}
 	if tag != "" {
-		return fmt.Sprintf("%s:%s", repository, tag)
+		return MirrorImage(fmt.Sprintf("%s:%s", repository, tag))
 	}
 	// Decide default tag behavior: either implicitly "latest" or require tag upstream
-	return repository // Or return fmt.Sprintf("%s:latest", repository)
+	return MirrorImage(repository) // Or return fmt.Sprintf("%s:latest", repository)
 }
 
 // GetImagePullPolicy returns the policy string or a default.
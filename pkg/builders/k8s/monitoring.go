@@ -0,0 +1,141 @@
+// Copyright (C) INFINI Labs & INFINI LIMITED.
+//
+// The INFINI Runtime Operator is offered under the GNU Affero General Public License v3.0
+// and as commercial software.
+//
+// For commercial licensing, contact us at:
+//   - Website: infinilabs.com
+//   - Email: hello@infini.ltd
+//
+// Open Source licensed under AGPL V3:
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// pkg/builders/k8s/monitoring.go
+package k8s
+
+import (
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+)
+
+// Conventional annotations of Services scraped by Prometheus' kubernetes-service-endpoints job.
+const (
+	PrometheusScrapeAnnotation = "prometheus.io/scrape"
+	PrometheusPortAnnotation   = "prometheus.io/port"
+	PrometheusPathAnnotation   = "prometheus.io/path"
+)
+
+// DefaultMetricsPath is the metrics path used when MonitoringSpec.Path is empty.
+const DefaultMetricsPath = "/metrics"
+
+// ServiceMonitorGVK and PodMonitorGVK are the Prometheus Operator kinds generated for MonitoringSpec.
+var (
+	ServiceMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+	PodMonitorGVK     = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PodMonitor"}
+)
+
+// MonitorGVK returns the kind generated for a monitoring spec, ServiceMonitor unless PodMonitor is selected.
+func MonitorGVK(spec *common.MonitoringSpec) schema.GroupVersionKind {
+	if spec.Kind == common.MonitorKindPodMonitor {
+		return PodMonitorGVK
+	}
+	return ServiceMonitorGVK
+}
+
+// IsKindAvailable reports whether the cluster serves gvk, according to mapper. A kind whose CRD is not
+// installed is not an error.
+func IsKindAvailable(mapper meta.RESTMapper, gvk schema.GroupVersionKind) (bool, error) {
+	if mapper == nil {
+		return false, nil
+	}
+	if _, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to discover %s: %w", gvk.String(), err)
+	}
+	return true, nil
+}
+
+// FindPort returns the port of ports with the given name.
+func FindPort(ports []common.PortSpec, name string) (common.PortSpec, bool) {
+	for _, port := range ports {
+		if port.Name == name {
+			return port, true
+		}
+	}
+	return common.PortSpec{}, false
+}
+
+// BuildMonitor builds the ServiceMonitor or PodMonitor of a monitoring spec as an unstructured object,
+// so the Prometheus Operator types are not needed. A ServiceMonitor scrapes the endpoints of the
+// Services matching selectorLabels, a PodMonitor the pods matching them.
+func BuildMonitor(monitorMeta metav1.ObjectMeta, selectorLabels map[string]string, spec *common.MonitoringSpec) *unstructured.Unstructured {
+	endpoint := map[string]interface{}{
+		"port": spec.Port,
+		"path": metricsPath(spec),
+	}
+	if spec.Interval != "" {
+		endpoint["interval"] = spec.Interval
+	}
+
+	gvk := MonitorGVK(spec)
+	endpointsField := "endpoints"
+	if gvk == PodMonitorGVK {
+		endpointsField = "podMetricsEndpoints"
+	}
+
+	matchLabels := make(map[string]interface{}, len(selectorLabels))
+	for key, value := range selectorLabels {
+		matchLabels[key] = value
+	}
+
+	monitor := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"selector":     map[string]interface{}{"matchLabels": matchLabels},
+			endpointsField: []interface{}{endpoint},
+		},
+	}}
+	monitor.SetGroupVersionKind(gvk)
+	monitor.SetName(monitorMeta.Name)
+	monitor.SetNamespace(monitorMeta.Namespace)
+	monitor.SetLabels(monitorMeta.Labels)
+	monitor.SetAnnotations(monitorMeta.Annotations)
+	return monitor
+}
+
+// BuildScrapeAnnotations returns the prometheus.io/* annotations for scraping port with the spec's path,
+// used on the Service when the Prometheus Operator is not installed.
+func BuildScrapeAnnotations(spec *common.MonitoringSpec, port common.PortSpec) map[string]string {
+	return map[string]string{
+		PrometheusScrapeAnnotation: "true",
+		PrometheusPortAnnotation:   strconv.Itoa(int(port.ContainerPort)),
+		PrometheusPathAnnotation:   metricsPath(spec),
+	}
+}
+
+// metricsPath returns the metrics path of a monitoring spec, DefaultMetricsPath when not set.
+func metricsPath(spec *common.MonitoringSpec) string {
+	if spec.Path == "" {
+		return DefaultMetricsPath
+	}
+	return spec.Path
+}
@@ -0,0 +1,71 @@
+package k8s
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+)
+
+func TestIsKindAvailable(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{ServiceMonitorGVK.GroupVersion()})
+	mapper.Add(ServiceMonitorGVK, meta.RESTScopeNamespace)
+
+	if available, err := IsKindAvailable(mapper, ServiceMonitorGVK); err != nil || !available {
+		t.Errorf("IsKindAvailable(ServiceMonitor) = %v, %v, want true", available, err)
+	}
+	if available, err := IsKindAvailable(mapper, PodMonitorGVK); err != nil || available {
+		t.Errorf("IsKindAvailable(PodMonitor) = %v, %v, want false without error", available, err)
+	}
+	if available, err := IsKindAvailable(nil, ServiceMonitorGVK); err != nil || available {
+		t.Errorf("IsKindAvailable(nil mapper) = %v, %v, want false without error", available, err)
+	}
+}
+
+func TestBuildMonitor(t *testing.T) {
+	monitorMeta := metav1.ObjectMeta{Name: "gateway", Namespace: "default", Labels: map[string]string{"app": "search"}}
+	selector := map[string]string{"app.kubernetes.io/instance": "gateway"}
+
+	monitor := BuildMonitor(monitorMeta, selector, &common.MonitoringSpec{Port: "metrics", Interval: "30s"})
+	if monitor.GroupVersionKind() != ServiceMonitorGVK {
+		t.Errorf("expected a ServiceMonitor by default, got %s", monitor.GroupVersionKind())
+	}
+	if monitor.GetName() != "gateway" || monitor.GetNamespace() != "default" || monitor.GetLabels()["app"] != "search" {
+		t.Errorf("unexpected metadata %s/%s %v", monitor.GetNamespace(), monitor.GetName(), monitor.GetLabels())
+	}
+	if instance, _, _ := unstructured.NestedString(monitor.Object, "spec", "selector", "matchLabels", "app.kubernetes.io/instance"); instance != "gateway" {
+		t.Errorf("unexpected selector instance %q", instance)
+	}
+	endpoints, _, _ := unstructured.NestedSlice(monitor.Object, "spec", "endpoints")
+	if len(endpoints) != 1 {
+		t.Fatalf("expected one endpoint, got %v", endpoints)
+	}
+	endpoint := endpoints[0].(map[string]interface{})
+	if endpoint["port"] != "metrics" || endpoint["path"] != DefaultMetricsPath || endpoint["interval"] != "30s" {
+		t.Errorf("unexpected endpoint %v", endpoint)
+	}
+
+	podMonitor := BuildMonitor(monitorMeta, selector, &common.MonitoringSpec{Port: "metrics", Path: "/_metrics", Kind: common.MonitorKindPodMonitor})
+	if podMonitor.GroupVersionKind() != PodMonitorGVK {
+		t.Errorf("expected a PodMonitor, got %s", podMonitor.GroupVersionKind())
+	}
+	podEndpoints, _, _ := unstructured.NestedSlice(podMonitor.Object, "spec", "podMetricsEndpoints")
+	if len(podEndpoints) != 1 {
+		t.Fatalf("expected one pod metrics endpoint, got %v", podEndpoints)
+	}
+	if podEndpoint := podEndpoints[0].(map[string]interface{}); podEndpoint["path"] != "/_metrics" || podEndpoint["interval"] != nil {
+		t.Errorf("unexpected pod metrics endpoint %v", podEndpoint)
+	}
+}
+
+func TestBuildScrapeAnnotations(t *testing.T) {
+	annotations := BuildScrapeAnnotations(&common.MonitoringSpec{Port: "metrics"}, common.PortSpec{Name: "metrics", ContainerPort: 9090})
+	if annotations[PrometheusScrapeAnnotation] != "true" || annotations[PrometheusPortAnnotation] != "9090" ||
+		annotations[PrometheusPathAnnotation] != DefaultMetricsPath {
+		t.Errorf("unexpected scrape annotations %v", annotations)
+	}
+}
@@ -90,7 +90,7 @@ func BuildPodTemplateSpec(
 
 // BuildEnsureDirectoryContainer builds a standard init container spec to ensure a directory exists with correct permissions.
 // name: name of the init container.
-// image: image to use for the init container (e.g., "busybox:latest").
+// image: image to use for the init container; empty uses the configured default (busybox:latest).
 // path: the path to ensure exists within the container.
 // ownerUID, ownerGID: numeric owner ID/GID for the directory.
 // Returns a corev1.Container spec. Volume mounts for the target path must be added separately by the caller.
@@ -103,8 +103,9 @@ func BuildEnsureDirectoryContainer(name string, image string, path string, owner
 	// Default image if not provided
 	initImage := image
 	if initImage == "" {
-		initImage = "busybox:latest" // Common default
+		initImage = defaults.InitContainerImage // Configured default, busybox:latest unless overridden
 	}
+	initImage = MirrorImage(initImage)
 
 	return corev1.Container{
 		Name:            name, // e.g., "init-data-dir"
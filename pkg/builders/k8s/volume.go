@@ -173,8 +173,8 @@ func BuildVolumeClaimTemplates(storageSpec *common.StorageSpec, commonLabels map
 			// Limits can also be set if needed:
 			// Limits: corev1.ResourceList{ corev1.ResourceStorage: *storageSpec.Size },
 		},
-		StorageClassName: storageSpec.StorageClassName, // Pointer
-		VolumeMode:       nil,                          // Default Filesystem (can be made configurable)
+		StorageClassName: defaultStorageClassName(storageSpec.StorageClassName), // Pointer
+		VolumeMode:       nil,                                                   // Default Filesystem (can be made configurable)
 	}
 	if len(pvcTemplateSpec.AccessModes) == 0 {
 		pvcTemplateSpec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
@@ -212,7 +212,7 @@ func BuildSharedPVCPVC(persistenceConfig *common.PersistenceSpec, instanceName s
 				corev1.ResourceStorage: *persistenceConfig.Size,
 			},
 		},
-		StorageClassName: persistenceConfig.StorageClassName,
+		StorageClassName: defaultStorageClassName(persistenceConfig.StorageClassName),
 	}
 
 	if len(pvcSpec.AccessModes) == 0 {
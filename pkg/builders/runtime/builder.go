@@ -26,7 +26,6 @@ package runtime
 import (
 	"context"
 	"fmt"
-	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -83,6 +82,11 @@ func (b *RuntimeBuilderStrategy) verifyParameters(runtimeConfig *common.RuntimeC
 	if len(runtimeConfig.Ports) == 0 {
 		return fmt.Errorf("runtime config is missing required 'ports' configuration for component '%s'", appComp.Name)
 	}
+	if monitoring := runtimeConfig.Monitoring; monitoring != nil {
+		if _, found := builders.FindPort(runtimeConfig.Ports, monitoring.Port); !found {
+			return fmt.Errorf("runtime monitoring port '%s' is not one of the ports of component '%s'", monitoring.Port, appComp.Name)
+		}
+	}
 	isStatefulSet := b.GetWorkloadGVK().Kind == StatefulSetType
 	if isStatefulSet {
 		if runtimeConfig.Storage == nil || !runtimeConfig.Storage.Enabled {
@@ -161,6 +165,7 @@ func (b *RuntimeBuilderStrategy) BuildObjects(ctx context.Context, k8sClient cli
 	podLabels := builders.MergeMaps(commonLabels, selectorLabels)
 	var podAnnotations = map[string]string{}
 	// --- 5. Build ConfigMaps/Secrets from Config File Data ---
+	var configObjects []client.Object
 	if len(runtimeConfig.ConfigFiles) > 0 {
 		configMapResourceName := resourceName + "-config"
 		logger.V(1).Info("Building ConfigMap object", "name", configMapResourceName)
@@ -168,35 +173,25 @@ func (b *RuntimeBuilderStrategy) BuildObjects(ctx context.Context, k8sClient cli
 		if err != nil {
 			return nil, fmt.Errorf("failed to build ConfigMaps from ConfigFiles for %s: %w", instanceName, err)
 		}
-
-		// Check if we need to restart the pod based on ConfigMap changes
-		var needRestart bool
-		for _, cmObj := range cmObjects {
-			cm, ok := cmObj.(*corev1.ConfigMap)
-			if ok {
-				hashV, err := builders.HashConfigMap(cm) // Hash the ConfigMap data for consistency
-				if err != nil {
-					return nil, fmt.Errorf("failed to hash ConfigMap data for %s: %w", instanceName, err)
-				}
-				if oldCv, ok := appDef.Status.Annotations[cm.GetName()]; !ok || oldCv != hashV {
-					needRestart = true // If hash changed, we need to restart the pod
-					if appDef.Status.Annotations == nil {
-						appDef.Status.Annotations = make(map[string]string) // Initialize if nil
-					}
-					appDef.Status.Annotations[cm.GetName()] = hashV // Update the annotation with new hash
-				}
-			}
-		}
-		if needRestart {
-			logger.V(1).Info("ConfigMap change detected, marking pod for restart")
-			// Add annotation to trigger pod rolling restart
-			podAnnotations["runtime-operator/restartedAt"] = time.Now().Format(time.RFC3339) // Add restart timestamp annotation
-		}
+		configObjects = append(configObjects, cmObjects...)
 		builtObjects = append(builtObjects, cmObjects...)
 		logger.V(1).Info("Successfully built ConfigMap object(s)")
 		// TODO: Handle Secrets similarly if needed
 	}
 
+	// Checksum all configuration consumed by the pods (generated and referenced).
+	// The annotation is stable across reconciles and only changes with the content,
+	// so a config change rolls the pods exactly once.
+	configChecksum, err := builders.ComputeConfigChecksum(ctx, k8sClient, namespace, configObjects,
+		runtimeConfig.ReferencedConfigMaps(), runtimeConfig.ReferencedSecrets())
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute config checksum for %s: %w", instanceName, err)
+	}
+	if configChecksum != "" {
+		podAnnotations[common.ConfigChecksumAnnotation] = configChecksum
+		logger.V(1).Info("Computed config checksum", "checksum", configChecksum)
+	}
+
 	logger.V(1).Info("Building PodTemplateSpec")
 	builtPodTemplateSpec, err := builders.BuildPodTemplateSpec(
 		[]corev1.Container{mainContainer}, initContainers, volumes,
@@ -240,8 +235,36 @@ func (b *RuntimeBuilderStrategy) BuildObjects(ctx context.Context, k8sClient cli
 	builtObjects = append(builtObjects, statefulSet)
 	logger.V(1).Info("Successfully built StatefulSet object", "name", statefulSet.Name)
 
+	// Metrics are scraped through a ServiceMonitor/PodMonitor when the Prometheus Operator is installed,
+	// otherwise through the prometheus.io/* annotations of the component's Service.
+	monitoring := runtimeConfig.Monitoring
+	var monitorAvailable bool
+	if monitoring != nil && k8sClient != nil {
+		monitorAvailable, err = builders.IsKindAvailable(k8sClient.RESTMapper(), builders.MonitorGVK(monitoring))
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to check monitoring support for %s: %w", strategy.ErrDependencyUnavailable, instanceName, err)
+		}
+		logger.V(1).Info("Checked Prometheus Operator support", "kind", builders.MonitorGVK(monitoring).Kind, "available", monitorAvailable)
+	}
+	var scrapeAnnotations map[string]string
+	if monitoring != nil && !monitorAvailable {
+		metricsPort, _ := builders.FindPort(runtimeConfig.Ports, monitoring.Port)
+		scrapeAnnotations = builders.BuildScrapeAnnotations(monitoring, metricsPort)
+	}
+	serviceMonitored := monitorAvailable && builders.MonitorGVK(monitoring) == builders.ServiceMonitorGVK
+
 	// --- 3. Build Headless Service ---
-	headlessServiceMetadata := builders.BuildObjectMeta(headlessServiceName, namespace, commonLabels, nil)
+	headlessServiceLabels := commonLabels
+	if serviceMonitored {
+		// The ServiceMonitor selects the headless Service only, not the client Service of the same pods
+		headlessServiceLabels = builders.MergeMaps(commonLabels, map[string]string{common.ServiceRoleLabel: common.ServiceRoleHeadless})
+	}
+	buildsClientService := runtimeConfig.Service != nil && ShouldBuildClientService(runtimeConfig.Service)
+	var headlessServiceAnnotations map[string]string
+	if !buildsClientService {
+		headlessServiceAnnotations = scrapeAnnotations
+	}
+	headlessServiceMetadata := builders.BuildObjectMeta(headlessServiceName, namespace, headlessServiceLabels, headlessServiceAnnotations)
 	headlessServicePorts := builders.BuildServicePorts(runtimeConfig.Ports)
 	logger.V(1).Info("Building Headless Service object", "name", headlessServiceName)
 	headlessService := builders.BuildHeadlessService(headlessServiceMetadata, selectorLabels, headlessServicePorts)
@@ -249,9 +272,14 @@ func (b *RuntimeBuilderStrategy) BuildObjects(ctx context.Context, k8sClient cli
 	logger.V(1).Info("Successfully built Headless Service object")
 
 	// --- 4. Build Client/Transport Service (Optional) ---
-	if runtimeConfig.Service != nil && ShouldBuildClientService(runtimeConfig.Service) { // Use local helper
+	if buildsClientService {
 		regularServiceName := resourceName
-		regularServiceMetadata := builders.BuildObjectMeta(regularServiceName, namespace, commonLabels, runtimeConfig.Service.Annotations)
+		// Annotations configured for the Service win over the scrape annotations
+		regularServiceAnnotations := runtimeConfig.Service.Annotations
+		if scrapeAnnotations != nil {
+			regularServiceAnnotations = builders.MergeMaps(scrapeAnnotations, runtimeConfig.Service.Annotations)
+		}
+		regularServiceMetadata := builders.BuildObjectMeta(regularServiceName, namespace, commonLabels, regularServiceAnnotations)
 		clientServicePorts := builders.BuildServicePorts(runtimeConfig.Service.Ports)
 
 		var serviceType = corev1.ServiceTypeClusterIP // Default
@@ -309,6 +337,18 @@ func (b *RuntimeBuilderStrategy) BuildObjects(ctx context.Context, k8sClient cli
 		}
 	}
 
+	// --- 8. Build ServiceMonitor/PodMonitor (Prometheus Operator) ---
+	if monitorAvailable {
+		monitorSelector := selectorLabels
+		if serviceMonitored {
+			monitorSelector = builders.MergeMaps(selectorLabels, map[string]string{common.ServiceRoleLabel: common.ServiceRoleHeadless})
+		}
+		monitorMetadata := builders.BuildObjectMeta(resourceName, namespace, commonLabels, nil)
+		monitor := builders.BuildMonitor(monitorMetadata, monitorSelector, monitoring)
+		builtObjects = append(builtObjects, monitor)
+		logger.V(1).Info("Successfully built monitor object", "kind", monitor.GetKind(), "name", monitor.GetName())
+	}
+
 	logger.V(1).Info("Finished building all Kubernetes objects for Runtime", "count", len(builtObjects))
 	return builtObjects, nil // Success!
 }
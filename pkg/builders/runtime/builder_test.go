@@ -0,0 +1,136 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
+	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+	builders "github.com/infinilabs/runtime-operator/pkg/builders/k8s"
+	"github.com/infinilabs/runtime-operator/pkg/strategy"
+)
+
+// failingMapper fails every lookup the way an unreachable discovery endpoint does.
+type failingMapper struct {
+	meta.RESTMapper
+}
+
+func (failingMapper) RESTMapping(gk schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
+	return nil, errors.New("the server is currently unable to handle the request")
+}
+
+func monitoredRuntimeConfig(clientService bool) *common.RuntimeConfig {
+	size := resource.MustParse("1Gi")
+	config := &common.RuntimeConfig{
+		Replicas: ptr.To[int32](1),
+		Image:    &common.ImageSpec{Repository: "infinilabs/gateway", Tag: "1.29.0"},
+		Ports: []common.PortSpec{
+			{Name: "http", ContainerPort: 8000},
+			{Name: "metrics", ContainerPort: 9100},
+		},
+		Storage:    &common.StorageSpec{Enabled: true, Size: &size, MountPath: "/data"},
+		Monitoring: &common.MonitoringSpec{Port: "metrics"},
+	}
+	if clientService {
+		config.Service = &common.ServiceSpecPart{Ports: []common.PortSpec{{Name: "http", ContainerPort: 8000}}}
+	}
+	return config
+}
+
+func buildObjects(t *testing.T, mapper meta.RESTMapper, config *common.RuntimeConfig) ([]client.Object, error) {
+	t.Helper()
+	appDef := &appv1.ApplicationDefinition{ObjectMeta: metav1.ObjectMeta{Name: "search", Namespace: "default"}}
+	appComp := &appv1.ApplicationComponent{Name: "gateway", Type: "operator"}
+	k8sClient := fake.NewClientBuilder().WithRESTMapper(mapper).Build()
+	return (&RuntimeBuilderStrategy{}).BuildObjects(context.Background(), k8sClient, k8sClient.Scheme(), appDef, appDef, appComp, config)
+}
+
+func findService(objects []client.Object, name string) *corev1.Service {
+	for _, obj := range objects {
+		if svc, ok := obj.(*corev1.Service); ok && svc.Name == name {
+			return svc
+		}
+	}
+	return nil
+}
+
+func TestBuildObjectsServiceMonitorSelectsHeadlessService(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{builders.ServiceMonitorGVK.GroupVersion()})
+	mapper.Add(builders.ServiceMonitorGVK, meta.RESTScopeNamespace)
+
+	objects, err := buildObjects(t, mapper, monitoredRuntimeConfig(true))
+	if err != nil {
+		t.Fatalf("BuildObjects() error = %v", err)
+	}
+
+	var monitor *unstructured.Unstructured
+	for _, obj := range objects {
+		if u, ok := obj.(*unstructured.Unstructured); ok && u.GroupVersionKind() == builders.ServiceMonitorGVK {
+			monitor = u
+		}
+	}
+	if monitor == nil {
+		t.Fatal("expected a ServiceMonitor when the Prometheus Operator is installed")
+	}
+	selector, _, _ := unstructured.NestedStringMap(monitor.Object, "spec", "selector", "matchLabels")
+
+	headless := findService(objects, "gateway-headless")
+	clientService := findService(objects, "gateway")
+	if headless == nil || clientService == nil {
+		t.Fatalf("expected the headless and the client Service, got %d objects", len(objects))
+	}
+	for key, value := range selector {
+		if headless.Labels[key] != value {
+			t.Errorf("headless Service label %s = %q, want %q from the ServiceMonitor selector", key, headless.Labels[key], value)
+		}
+	}
+	if _, found := clientService.Labels[common.ServiceRoleLabel]; found {
+		t.Errorf("client Service must not be selected by the ServiceMonitor, labels %v", clientService.Labels)
+	}
+	for _, svc := range []*corev1.Service{headless, clientService} {
+		if _, found := svc.Annotations[builders.PrometheusScrapeAnnotation]; found {
+			t.Errorf("Service %s has scrape annotations although a ServiceMonitor is built", svc.Name)
+		}
+	}
+}
+
+func TestBuildObjectsScrapeAnnotationsWithoutPrometheusOperator(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+
+	objects, err := buildObjects(t, mapper, monitoredRuntimeConfig(true))
+	if err != nil {
+		t.Fatalf("BuildObjects() error = %v", err)
+	}
+	if clientService := findService(objects, "gateway"); clientService == nil || clientService.Annotations[builders.PrometheusPortAnnotation] != "9100" {
+		t.Errorf("expected the scrape annotations on the client Service, got %v", clientService)
+	}
+	if headless := findService(objects, "gateway-headless"); headless == nil || len(headless.Annotations) != 0 {
+		t.Errorf("expected no scrape annotations on the headless Service, got %v", headless)
+	}
+
+	objects, err = buildObjects(t, mapper, monitoredRuntimeConfig(false))
+	if err != nil {
+		t.Fatalf("BuildObjects() error = %v", err)
+	}
+	if headless := findService(objects, "gateway-headless"); headless == nil || headless.Annotations[builders.PrometheusScrapeAnnotation] != "true" {
+		t.Errorf("expected the scrape annotations on the headless Service without a client Service, got %v", headless)
+	}
+}
+
+func TestBuildObjectsRetriesFailedMonitoringDiscovery(t *testing.T) {
+	_, err := buildObjects(t, failingMapper{meta.NewDefaultRESTMapper(nil)}, monitoredRuntimeConfig(true))
+	if !errors.Is(err, strategy.ErrDependencyUnavailable) {
+		t.Errorf("BuildObjects() error = %v, want ErrDependencyUnavailable", err)
+	}
+}
@@ -36,6 +36,7 @@ import (
 	appv1 "github.com/infinilabs/runtime-operator/api/app/v1"
 	"github.com/infinilabs/runtime-operator/internal/controller/common/kubeutil"
 	"github.com/infinilabs/runtime-operator/pkg/apis/common"
+	"github.com/infinilabs/runtime-operator/pkg/apphealth"
 	commonreconcilers "github.com/infinilabs/runtime-operator/pkg/reconcilers/common"
 	"github.com/infinilabs/runtime-operator/pkg/strategy"
 
@@ -52,6 +53,8 @@ type ReconcileStrategy struct{}
 // Register the strategy
 func init() {
 	strategy.RegisterAppReconcileStrategy("runtime", &ReconcileStrategy{})
+	// The controller dispatches all components as "operator", matching the builder registration
+	strategy.RegisterAppReconcileStrategy("operator", &ReconcileStrategy{})
 }
 
 // Reconcile implements AppReconcileStrategy interface.
@@ -116,32 +119,32 @@ func (s *ReconcileStrategy) CheckAppHealth(ctx context.Context, k8sClient client
 		return false, "Invalid or missing Runtime config for health check", fmt.Errorf("invalid config type %T", appSpecificConfig)
 	}
 
-	// 1. Find the primary client service name (convention or config)
+	// 1. Find the service to check: the client service if one is built, otherwise the headless service
 	instanceName := appComp.Name
-	serviceName := k8sbuilders.DeriveResourceName(instanceName) // Assume service name matches instance name
+	serviceName := k8sbuilders.DeriveResourceName(instanceName) // Client service name matches instance name
 	namespace := appDef.Namespace
 
-	// 2. Get the service object
+	// 2. Get the client service object, falling back to the headless service which is always built
 	svc := &corev1.Service{}
 	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: serviceName}, svc); err != nil {
-		if apierrors.IsNotFound(err) { // Use apierrors alias
-			return false, fmt.Sprintf("Client service %s not found", serviceName), nil // Not healthy if service missing
+		if !apierrors.IsNotFound(err) { // Use apierrors alias
+			return false, fmt.Sprintf("Failed to get client service %s: %v", serviceName, err), err
+		}
+		logger.V(1).Info("Client service not found, checking headless service endpoints", "service", serviceName)
+		serviceName = k8sbuilders.DeriveResourceName(instanceName) + "-headless"
+		if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: serviceName}, svc); err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, fmt.Sprintf("Neither client nor headless service found for %s", instanceName), nil // Not healthy if no service
+			}
+			return false, fmt.Sprintf("Failed to get headless service %s: %v", serviceName, err), err
 		}
-		return false, fmt.Sprintf("Failed to get client service %s: %v", serviceName, err), err
-	}
-
-	// 3. Check if it's headless (if so, app health might rely on other checks)
-	if svc.Spec.ClusterIP == corev1.ClusterIPNone {
-		// Maybe return true here, assuming basic K8s readiness passed earlier?
-		// Or perform a check specific to headless discovery.
-		return true, "Headless service found (basic check)", nil
 	}
 
-	// 4. For non-headless, check endpoints
+	// 3. Check endpoints of the selected service (headless services get endpoints as well)
 	endpoints := &corev1.Endpoints{}
 	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: serviceName}, endpoints); err != nil {
 		if apierrors.IsNotFound(err) { // Use apierrors alias
-			return false, "Runtime service endpoints not found", nil // No endpoints means no healthy pods serving
+			return false, fmt.Sprintf("Runtime service %s endpoints not found", serviceName), nil // No endpoints means no healthy pods serving
 		}
 		return false, fmt.Sprintf("Failed to get Runtime endpoints %s: %v", serviceName, err), err
 	}
@@ -164,10 +167,23 @@ func (s *ReconcileStrategy) CheckAppHealth(ctx context.Context, k8sClient client
 	}
 
 	if !hasReadyEndpoints {
-		return false, fmt.Sprintf("No ready endpoints found for Runtime service (%d/%d ready/total)", readyCount, totalCount), nil
+		return false, fmt.Sprintf("No ready endpoints found for Runtime service %s (%d/%d ready/total)", serviceName, readyCount, totalCount), nil
+	}
+
+	endpointsMessage := fmt.Sprintf("Runtime application service %s has ready endpoints (%d/%d ready/total)", serviceName, readyCount, totalCount)
+	if runtimeConfig.AppHealth == nil {
+		return true, endpointsMessage, nil
 	}
 
-	return true, fmt.Sprintf("Runtime application service has ready endpoints (%d/%d ready/total)", readyCount, totalCount), nil
+	// 5. Evaluate the declarative HTTP application health check
+	healthy, message, err := apphealth.Check(ctx, k8sClient, namespace, runtimeConfig, apphealth.Target{
+		ServiceName:    serviceName,
+		SelectorLabels: k8sbuilders.BuildSelectorLabels(instanceName, appComp.Type),
+	})
+	if err != nil {
+		return false, message, err
+	}
+	return healthy, message, nil
 }
 
 // Helper to build map from object slice (duplicate from OS strategy, move to common util?)
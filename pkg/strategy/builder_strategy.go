@@ -26,6 +26,7 @@ package strategy
 
 import (
 	"context" // Needed for methods
+	"errors"
 
 	appv1 "github.com/infinilabs/runtime-operator/api/app/v1" // For ApplicationDefinition, ApplicationComponent
 	"k8s.io/apimachinery/pkg/runtime"                         // For Scheme
@@ -34,6 +35,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client" // Needed if strategy needs client
 )
 
+// ErrDependencyUnavailable marks BuildObjects errors caused by a cluster dependency that could not
+// be reached (e.g. API discovery failing). The controller retries these instead of failing the build.
+var ErrDependencyUnavailable = errors.New("dependency unavailable")
+
 // AppBuilderStrategy defines the contract for application-specific logic
 // needed during the Kubernetes object building phase of reconciliation.
 // Each supported application type (e.g., "opensearch", "gateway") needs a
@@ -60,7 +65,8 @@ type AppBuilderStrategy interface {
 	//     OwnerReferences will be set by the main controller after this method returns.
 	//   - error: The first critical error encountered during config validation or object building.
 	//     Returning an error here will typically cause the reconciliation for the *entire* ApplicationDefinition
-	//     to fail and requeue.
+	//     to fail and requeue. Wrap transient cluster failures with ErrDependencyUnavailable so
+	//     they are retried rather than reported as a build failure.
 	BuildObjects(ctx context.Context, k8sClient client.Client, scheme *runtime.Scheme, owner client.Object, appDef *appv1.ApplicationDefinition, appComp *appv1.ApplicationComponent, appSpecificConfig interface{}) ([]client.Object, error)
 
 	// GetWorkloadGVK returns the expected primary K8s workload GVK (e.g., StatefulSet for Opensearch)
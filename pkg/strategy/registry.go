@@ -26,6 +26,7 @@ package strategy
 
 import (
 	"fmt"
+	"os"
 	// Needed by AppBuilderStrategy interface
 )
 
@@ -53,8 +54,9 @@ func RegisterAppBuilderStrategy(compType string, strategy AppBuilderStrategy) {
 		panic(fmt.Sprintf("Builder strategy already registered for component type: %s", compType))
 	}
 	builderStrategyRegistry[compType] = strategy
-	// Use fmt.Printf for startup logging as controller logger might not be initialized yet.
-	fmt.Printf("INFO: Builder strategy '%T' registered for component type '%s'\n", strategy, compType)
+	// Use fmt for startup logging as controller logger might not be initialized yet.
+	// Written to stderr so tools printing manifests on stdout (cmd/render) stay parseable.
+	fmt.Fprintf(os.Stderr, "INFO: Builder strategy '%T' registered for component type '%s'\n", strategy, compType)
 }
 
 // GetAppBuilderStrategy retrieves the registered builder strategy for a component type.
@@ -79,7 +81,7 @@ func RegisterAppReconcileStrategy(compType string, strategy AppReconcileStrategy
 		panic(fmt.Sprintf("Reconcile strategy already registered for component type: %s", compType))
 	}
 	reconcileStrategyRegistry[compType] = strategy
-	fmt.Printf("INFO: Reconcile strategy '%T' registered for component type '%s'\n", strategy, compType)
+	fmt.Fprintf(os.Stderr, "INFO: Reconcile strategy '%T' registered for component type '%s'\n", strategy, compType)
 }
 
 // GetAppReconcileStrategy retrieves the registered reconcile strategy for a component type.
@@ -92,5 +94,5 @@ func GetAppReconcileStrategy(compType string) (AppReconcileStrategy, bool) {
 
 // Placeholder init - Actual registrations happen in specific strategy packages' init() functions.
 func init() {
-	fmt.Println("INFO: Strategy registry package initialized.")
+	fmt.Fprintln(os.Stderr, "INFO: Strategy registry package initialized.")
 }
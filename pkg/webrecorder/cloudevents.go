@@ -0,0 +1,156 @@
+package webrecorder
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Format is the payload format of webhook requests.
+type Format string
+
+const (
+	// FormatWebhook posts the WebhookEvent as plain JSON.
+	FormatWebhook Format = "webhook"
+	// FormatCloudEvents posts a CloudEvents 1.0 event in structured mode: the whole event, with the
+	// WebhookEvent as data, is the application/cloudevents+json body.
+	FormatCloudEvents Format = "cloudevents"
+	// FormatCloudEventsBinary posts a CloudEvents 1.0 event in binary mode: the attributes are ce-*
+	// headers and the WebhookEvent is the JSON body.
+	FormatCloudEventsBinary Format = "cloudevents-binary"
+)
+
+// --- Constants for CloudEvents ---
+const (
+	// CloudEventsSpecVersion is the CloudEvents version of emitted events.
+	CloudEventsSpecVersion = "1.0"
+	// CloudEventTypePrefix prefixes the type of emitted events, followed by the phase, step and status.
+	CloudEventTypePrefix = "cloud.infini.runtime"
+	// CloudEventSource is the source of emitted events, followed by "/clusters/<cluster ID>" when known.
+	CloudEventSource = "/runtime-operator"
+	// CloudEventChangeIDExtension and CloudEventClusterIDExtension carry the change and cluster IDs.
+	CloudEventChangeIDExtension  = "changeid"
+	CloudEventClusterIDExtension = "clusterid"
+	// CloudEventSequenceExtension carries the sequence number of the event within its change, zero-padded
+	// so that it orders lexicographically as the sequence extension requires.
+	CloudEventSequenceExtension = "sequence"
+
+	cloudEventsContentType = "application/cloudevents+json"
+)
+
+// ParseFormat returns the format named s; empty is FormatWebhook.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case "":
+		return FormatWebhook, nil
+	case FormatWebhook, FormatCloudEvents, FormatCloudEventsBinary:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown webhook format %q, expected %q, %q or %q", s, FormatWebhook, FormatCloudEvents, FormatCloudEventsBinary)
+	}
+}
+
+// cloudEvent is a CloudEvents 1.0 event in its JSON representation.
+type cloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            string          `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype"`
+	ChangeID        string          `json:"changeid,omitempty"`
+	ClusterID       string          `json:"clusterid,omitempty"`
+	Sequence        string          `json:"sequence,omitempty"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// newCloudEvent wraps the marshalled event. The ID is derived from the data, so retries of an event keep it
+// and receivers can deduplicate them.
+func newCloudEvent(data *WebhookEvent, payload []byte) *cloudEvent {
+	sum := sha256.Sum256(payload)
+	source := CloudEventSource
+	if data.ClusterID != "" {
+		source += "/clusters/" + data.ClusterID
+	}
+	eventType := CloudEventTypePrefix
+	for _, part := range []string{data.Phase, data.Step, data.Status} {
+		if part != "" {
+			eventType += "." + part
+		}
+	}
+	subject := data.Application
+	if data.Application != "" && data.Component != "" {
+		subject += "/" + data.Component
+	}
+	var sequence string
+	if data.Sequence > 0 {
+		sequence = fmt.Sprintf("%019d", data.Sequence)
+	}
+	return &cloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              hex.EncodeToString(sum[:16]),
+		Source:          source,
+		Type:            eventType,
+		Subject:         subject,
+		Time:            data.Timestamp,
+		DataContentType: "application/json",
+		ChangeID:        data.ChangeID,
+		ClusterID:       data.ClusterID,
+		Sequence:        sequence,
+		Data:            payload,
+	}
+}
+
+// newEventRequest builds the request posting the event in the format of the endpoint, and returns its body.
+func newEventRequest(ctx context.Context, endpoint Endpoint, data *WebhookEvent) (*http.Request, []byte, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	body, contentType := payload, "application/json"
+	var event *cloudEvent
+	switch endpoint.Format {
+	case FormatCloudEvents:
+		if body, err = json.Marshal(newCloudEvent(data, payload)); err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal cloud event: %w", err)
+		}
+		contentType = cloudEventsContentType
+	case FormatCloudEventsBinary:
+		event = newCloudEvent(data, payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if event != nil {
+		setCloudEventHeaders(req.Header, event)
+	}
+	return req, body, nil
+}
+
+// setCloudEventHeaders sets the ce-* headers of a binary mode event.
+func setCloudEventHeaders(header http.Header, event *cloudEvent) {
+	header.Set("ce-specversion", event.SpecVersion)
+	header.Set("ce-id", event.ID)
+	header.Set("ce-source", event.Source)
+	header.Set("ce-type", event.Type)
+	for name, value := range map[string]string{
+		"ce-subject":                         event.Subject,
+		"ce-time":                            event.Time,
+		"ce-" + CloudEventChangeIDExtension:  event.ChangeID,
+		"ce-" + CloudEventClusterIDExtension: event.ClusterID,
+		"ce-" + CloudEventSequenceExtension:  event.Sequence,
+	} {
+		if value != "" {
+			header.Set(name, value)
+		}
+	}
+}
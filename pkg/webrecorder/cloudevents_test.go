@@ -0,0 +1,138 @@
+package webrecorder
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// capturedRequest is the header and body of a request received by a test server.
+type capturedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func captureRequest(t *testing.T, endpoint Endpoint, event *WebhookEvent) capturedRequest {
+	t.Helper()
+	requests := make(chan capturedRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- capturedRequest{header: r.Header, body: body}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	endpoint.URL = server.URL
+	if err := postEvent(context.Background(), newHTTPClient(), endpoint, event, nil); err != nil {
+		t.Fatalf("postEvent: %v", err)
+	}
+	return <-requests
+}
+
+func testChangeEvent() *WebhookEvent {
+	return &WebhookEvent{
+		ChangeID:    "change-123",
+		ClusterID:   "cluster-test",
+		Phase:       "ApplyResources",
+		Step:        "SyncStatefulSet",
+		Status:      StatusSuccess,
+		Level:       "Normal",
+		Message:     "Updated StatefulSet: search",
+		Timestamp:   "2025-01-01T00:00:00Z",
+		Application: "search-app",
+		Component:   "search",
+		Sequence:    7,
+	}
+}
+
+func TestCloudEventsStructuredMode(t *testing.T) {
+	req := captureRequest(t, Endpoint{Format: FormatCloudEvents}, testChangeEvent())
+
+	if ct := req.header.Get("Content-Type"); ct != "application/cloudevents+json" {
+		t.Errorf("Content-Type = %q, want application/cloudevents+json", ct)
+	}
+	var event map[string]interface{}
+	if err := json.Unmarshal(req.body, &event); err != nil {
+		t.Fatalf("body is not JSON: %v", err)
+	}
+	want := map[string]string{
+		"specversion":     "1.0",
+		"source":          "/runtime-operator/clusters/cluster-test",
+		"type":            "cloud.infini.runtime.ApplyResources.SyncStatefulSet.success",
+		"subject":         "search-app/search",
+		"time":            "2025-01-01T00:00:00Z",
+		"datacontenttype": "application/json",
+		"changeid":        "change-123",
+		"clusterid":       "cluster-test",
+		"sequence":        "0000000000000000007",
+	}
+	for attr, value := range want {
+		if event[attr] != value {
+			t.Errorf("%s = %v, want %q", attr, event[attr], value)
+		}
+	}
+	if id, _ := event["id"].(string); id == "" {
+		t.Error("Expected an event ID")
+	}
+	data, _ := event["data"].(map[string]interface{})
+	if data["message"] != "Updated StatefulSet: search" {
+		t.Errorf("Expected the webhook event as data, got %v", event["data"])
+	}
+}
+
+func TestCloudEventsBinaryMode(t *testing.T) {
+	req := captureRequest(t, Endpoint{Format: FormatCloudEventsBinary}, testChangeEvent())
+
+	want := map[string]string{
+		"Content-Type":   "application/json",
+		"Ce-Specversion": "1.0",
+		"Ce-Type":        "cloud.infini.runtime.ApplyResources.SyncStatefulSet.success",
+		"Ce-Subject":     "search-app/search",
+		"Ce-Changeid":    "change-123",
+		"Ce-Clusterid":   "cluster-test",
+		"Ce-Sequence":    "0000000000000000007",
+	}
+	for header, value := range want {
+		if got := req.header.Get(header); got != value {
+			t.Errorf("%s = %q, want %q", header, got, value)
+		}
+	}
+	var data WebhookEvent
+	if err := json.Unmarshal(req.body, &data); err != nil || data.Message != "Updated StatefulSet: search" {
+		t.Errorf("Expected the webhook event as body, got %s", req.body)
+	}
+
+	// Retries of an event keep its ID, so receivers can deduplicate them
+	again := captureRequest(t, Endpoint{Format: FormatCloudEventsBinary}, testChangeEvent())
+	if req.header.Get("Ce-Id") == "" || again.header.Get("Ce-Id") != req.header.Get("Ce-Id") {
+		t.Errorf("Expected a stable event ID, got %q and %q", req.header.Get("Ce-Id"), again.header.Get("Ce-Id"))
+	}
+}
+
+func TestWebhookFormatIsPlainEvent(t *testing.T) {
+	req := captureRequest(t, Endpoint{}, testChangeEvent())
+	if ct := req.header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	if req.header.Get("Ce-Id") != "" {
+		t.Error("Expected no CloudEvents headers")
+	}
+	var data WebhookEvent
+	if err := json.Unmarshal(req.body, &data); err != nil || data.Application != "search-app" || data.Component != "search" {
+		t.Errorf("Expected the webhook event as body, got %s", req.body)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for input, want := range map[string]Format{"": FormatWebhook, "cloudevents": FormatCloudEvents, "cloudevents-binary": FormatCloudEventsBinary} {
+		if got, err := ParseFormat(input); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
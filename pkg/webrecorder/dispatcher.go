@@ -0,0 +1,175 @@
+package webrecorder
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// dispatchPollInterval is how often outboxes are scanned for events that became due.
+const dispatchPollInterval = 5 * time.Second
+
+// DeliveryReport summarizes an outbox after a delivery pass.
+type DeliveryReport struct {
+	// Owner is the object the events are about.
+	Owner types.NamespacedName
+	// Pending is the number of events waiting to be delivered.
+	Pending int
+	// LastError is the error of the last failed delivery of the oldest pending event.
+	LastError string
+	// DeadLetters are the events given up on, oldest first.
+	DeadLetters []DeadLetter
+}
+
+// ReportFunc publishes a delivery report, e.g. in the status of the owning object.
+type ReportFunc func(ctx context.Context, report DeliveryReport) error
+
+// Dispatcher delivers the events of all outboxes, in order per outbox, with bounded exponential backoff.
+// It runs on the leader only: a new leader picks up the outboxes where the previous one stopped.
+type Dispatcher struct {
+	outbox          *Outbox
+	httpClient      *http.Client
+	maxAttempts     int
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	report          ReportFunc
+	logger          logr.Logger
+	// pending counts the events left in the outboxes published during the current pass.
+	pending int
+}
+
+// NewDispatcher returns a dispatcher for the outbox using the default options. report, if set,
+// is called with the state of every outbox after each delivery pass.
+func NewDispatcher(outbox *Outbox, report ReportFunc) *Dispatcher {
+	return &Dispatcher{
+		outbox:          outbox,
+		httpClient:      newHTTPClient(),
+		maxAttempts:     defaultOptions.RetryMaxAttempts,
+		initialInterval: defaultOptions.RetryInitialInterval,
+		maxInterval:     defaultOptions.RetryMaxInterval,
+		report:          report,
+		logger:          log.Log.WithName("webhook-dispatcher"),
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable; only the leader delivers events.
+func (d *Dispatcher) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable. It delivers events until ctx is cancelled.
+func (d *Dispatcher) Start(ctx context.Context) error {
+	d.logger.Info("Starting webhook outbox dispatcher")
+	ticker := time.NewTicker(dispatchPollInterval)
+	defer ticker.Stop()
+	for {
+		d.dispatchAll(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-d.outbox.notify:
+		}
+	}
+}
+
+// dispatchAll runs a delivery pass over every outbox.
+func (d *Dispatcher) dispatchAll(ctx context.Context) {
+	outboxes := &corev1.ConfigMapList{}
+	if err := d.outbox.client.List(ctx, outboxes, client.HasLabels{d.outbox.label}); err != nil {
+		d.logger.Error(err, "Failed to list webhook outboxes")
+		return
+	}
+	d.pending = 0
+	defer func() { queuedEvents.WithLabelValues(queueOutbox).Set(float64(d.pending)) }()
+	for i := range outboxes.Items {
+		cm := &outboxes.Items[i]
+		owner := types.NamespacedName{Namespace: cm.Namespace, Name: cm.Labels[d.outbox.label]}
+		if err := d.dispatch(ctx, owner, cm); err != nil {
+			d.logger.Error(err, "Failed to deliver webhook events", "outbox", client.ObjectKeyFromObject(cm))
+		}
+	}
+}
+
+// dispatch delivers the due events of one outbox in order. A failed event blocks the events queued
+// after it until it is delivered or dead-lettered. cached is the outbox as listed from the cache,
+// used to skip outboxes without due events; deliveries always work on a fresh read.
+func (d *Dispatcher) dispatch(ctx context.Context, owner types.NamespacedName, cached *corev1.ConfigMap) error {
+	cachedState, err := decodeOutbox(cached)
+	if err != nil {
+		return err
+	}
+	now := d.outbox.now()
+	due := len(cachedState.entries) > 0 && !now.Before(cachedState.entries[0].NextAttempt)
+	cachedState.expire(now, d.outbox.eventTTL)
+	if !due && !cachedState.empty() {
+		return d.publish(ctx, owner, cachedState)
+	}
+
+	for ctx.Err() == nil {
+		cm, state, err := d.outbox.load(ctx, owner)
+		if err != nil {
+			return err
+		}
+		if cm == nil {
+			return d.publish(ctx, owner, &outboxState{})
+		}
+		now := d.outbox.now()
+		state.expire(now, d.outbox.eventTTL)
+		if state.empty() {
+			// Nothing left to deliver or remember; delete unless an event was queued meanwhile
+			rv := cm.ResourceVersion
+			if err := d.outbox.client.Delete(ctx, cm, client.Preconditions{ResourceVersion: &rv}); client.IgnoreNotFound(err) != nil {
+				d.logger.V(1).Info("Webhook outbox changed before its deletion", "outbox", client.ObjectKeyFromObject(cm), "error", err.Error())
+			}
+			return d.publish(ctx, owner, state)
+		}
+		if len(state.entries) == 0 || now.Before(state.entries[0].NextAttempt) {
+			return d.publish(ctx, owner, state)
+		}
+
+		head := state.entries[0]
+		head.Event.TraceContext = head.TraceContext
+		sendErr := postEvent(ctx, d.httpClient, head.Endpoint, &head.Event, d.outbox.reader)
+		if sendErr != nil {
+			d.logger.Info("Webhook delivery failed", "outbox", client.ObjectKeyFromObject(cm), "seq", head.Seq,
+				"attempt", head.Attempts+1, "maxAttempts", d.maxAttempts, "error", sendErr.Error())
+		}
+		err = d.outbox.update(ctx, owner, nil, func(s *outboxState) bool {
+			return s.recordAttempt(head.Seq, sendErr, d.outbox.now(), d.maxAttempts, func(attempt int) time.Duration {
+				return retryInterval(attempt, d.initialInterval, d.maxInterval)
+			})
+		})
+		if err != nil {
+			return err
+		}
+		countDelivery(sendErr)
+		if sendErr != nil {
+			if head.Attempts+1 < d.maxAttempts {
+				deliveryRetries.Inc()
+			} else {
+				droppedEvents.WithLabelValues(dropRetriesExhausted).Inc()
+			}
+		}
+	}
+	return nil
+}
+
+// publish hands the state of an outbox to the report function.
+func (d *Dispatcher) publish(ctx context.Context, owner types.NamespacedName, state *outboxState) error {
+	d.pending += len(state.entries)
+	if d.report == nil {
+		return nil
+	}
+	report := DeliveryReport{Owner: owner, Pending: len(state.entries), DeadLetters: state.deadLetters}
+	if len(state.entries) > 0 {
+		report.LastError = state.entries[0].LastError
+	}
+	return d.report(ctx, report)
+}
@@ -0,0 +1,68 @@
+package webrecorder
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Queues of webhook events waiting for delivery, as reported by the queued events metric.
+const (
+	// queueRetry holds events sent directly that wait for their next attempt.
+	queueRetry = "retry"
+	// queueOutbox holds events persisted in outboxes, as of the last delivery pass.
+	queueOutbox = "outbox"
+)
+
+// Results of webhook delivery attempts and reasons for dropping events, as reported by the metrics.
+const (
+	deliverySucceeded = "success"
+	deliveryFailed    = "failure"
+
+	// dropRetriesExhausted drops events that failed every attempt; outbox events become dead letters.
+	dropRetriesExhausted = "retries_exhausted"
+	// dropShutdown drops events sent directly that were still pending when the operator stopped.
+	dropShutdown = "shutdown"
+	// dropOutboxFull drops the oldest pending events of a full outbox; they become dead letters.
+	dropOutboxFull = "outbox_full"
+)
+
+var (
+	inFlightRequests = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "runtime_operator_webhook_requests_in_flight",
+		Help: "Number of webhook requests currently being sent.",
+	})
+	queuedEvents = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "runtime_operator_webhook_events_queued",
+		Help: "Number of webhook events waiting for delivery, by queue.",
+	}, []string{"queue"})
+	activeRecorders = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "runtime_operator_webhook_recorders",
+		Help: "Number of webhook event recorders held by the recorder registry.",
+	})
+	deliveryAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "runtime_operator_webhook_deliveries_total",
+		Help: "Number of webhook delivery attempts, by result.",
+	}, []string{"result"})
+	deliveryRetries = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "runtime_operator_webhook_retries_total",
+		Help: "Number of webhook deliveries scheduled for another attempt after a failure.",
+	})
+	droppedEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "runtime_operator_webhook_events_dropped_total",
+		Help: "Number of webhook events given up on, by reason.",
+	}, []string{"reason"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(inFlightRequests, queuedEvents, activeRecorders,
+		deliveryAttempts, deliveryRetries, droppedEvents)
+}
+
+// countDelivery records the result of a delivery attempt that ended with err, if not nil.
+func countDelivery(err error) {
+	if err != nil {
+		deliveryAttempts.WithLabelValues(deliveryFailed).Inc()
+		return
+	}
+	deliveryAttempts.WithLabelValues(deliverySucceeded).Inc()
+}
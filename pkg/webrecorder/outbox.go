@@ -0,0 +1,412 @@
+package webrecorder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// --- Constants for the webhook outbox ---
+const (
+	// OutboxLabel marks webhook outbox ConfigMaps. Its value is the name of the object the events are about.
+	OutboxLabel = "infini.cloud/webhook-outbox"
+	// OutboxConfigMapSuffix is appended to the object's name to name its outbox ConfigMap.
+	OutboxConfigMapSuffix = "-webhook-outbox"
+
+	outboxEntriesKey     = "entries.json"
+	outboxDeadLettersKey = "dead-letters.json"
+	outboxDeliveredKey   = "delivered.json"
+	outboxSequenceKey    = "sequence"
+	outboxChangesKey     = "changes.json"
+
+	// maxPendingEntries bounds the pending entries per outbox, keeping its ConfigMap well below the
+	// object size limit while an endpoint is down; the oldest are dead-lettered first.
+	maxPendingEntries = 200
+	// maxDeadLetters bounds the dead letters kept per outbox; the oldest are dropped first.
+	maxDeadLetters = 20
+	// maxChangeSequences bounds the changes whose event sequence numbers are kept per outbox;
+	// the least recently used are dropped first.
+	maxChangeSequences = 20
+)
+
+// OutboxEntry is an event waiting in an outbox.
+type OutboxEntry struct {
+	// Seq orders the entries of an outbox; events are delivered in Seq order.
+	Seq int64 `json:"seq"`
+	// Key deduplicates the event; empty for events that are never deduplicated.
+	Key string `json:"key,omitempty"`
+	// Endpoint is where and how the event is sent.
+	Endpoint
+	// Event is the payload.
+	Event WebhookEvent `json:"event"`
+	// Attempts is how many deliveries failed so far.
+	Attempts int `json:"attempts,omitempty"`
+	// NextAttempt is when the entry is due again after a failed delivery.
+	NextAttempt time.Time `json:"nextAttempt"`
+	// LastError is the error of the last failed delivery.
+	LastError string `json:"lastError,omitempty"`
+	// TraceContext is the trace context the event was recorded in, see WebhookEvent.TraceContext.
+	TraceContext map[string]string `json:"traceContext,omitempty"`
+}
+
+// DeadLetter is an event given up on after the retry limit.
+type DeadLetter struct {
+	OutboxEntry
+	// DeadLetteredAt is when the event was given up on.
+	DeadLetteredAt time.Time `json:"deadLetteredAt"`
+}
+
+// outboxState is the decoded content of an outbox ConfigMap.
+type outboxState struct {
+	entries     []OutboxEntry
+	deadLetters []DeadLetter
+	// delivered remembers the keys of delivered events until the event TTL expires, across restarts.
+	delivered map[string]time.Time
+	sequence  int64
+	// changes holds the last event sequence number of each recent change, see WebhookEvent.Sequence.
+	changes map[string]changeSequence
+}
+
+// changeSequence is the last event sequence number of a change.
+type changeSequence struct {
+	Last int64     `json:"last"`
+	At   time.Time `json:"at"`
+}
+
+// Outbox persists webhook events in one ConfigMap per object, next to the object and owned by it,
+// so that they survive endpoint outages, operator restarts and leader changes. Events are delivered
+// by a Dispatcher. Delivery is at least once: an event may be sent again if the operator stops between
+// sending it and recording the delivery.
+type Outbox struct {
+	client client.Client
+	// reader reads outboxes without the cache, so a stale read never resends a delivered event.
+	reader   client.Reader
+	eventTTL time.Duration
+	// notify wakes the Dispatcher when an event is queued.
+	notify chan struct{}
+	now    func() time.Time
+	// label marks the outbox ConfigMaps and suffix is appended to the owner's name to name them.
+	label  string
+	suffix string
+}
+
+// NewOutbox returns an outbox writing ConfigMaps with c and reading them with reader,
+// typically the manager's API reader.
+func NewOutbox(c client.Client, reader client.Reader) *Outbox {
+	return NewOutboxFor(c, reader, OutboxLabel, OutboxConfigMapSuffix)
+}
+
+// NewOutboxFor is like NewOutbox, but marks the outbox ConfigMaps with label and names them after their
+// owner with suffix, keeping them apart from the outboxes of other kinds of owners. Each such outbox
+// needs its own Dispatcher.
+func NewOutboxFor(c client.Client, reader client.Reader, label, suffix string) *Outbox {
+	return &Outbox{
+		client:   c,
+		reader:   reader,
+		eventTTL: defaultOptions.EventTTL,
+		notify:   make(chan struct{}, 1),
+		now:      time.Now,
+		label:    label,
+		suffix:   suffix,
+	}
+}
+
+// OutboxName returns the name of the outbox ConfigMap of the named object.
+func OutboxName(ownerName string) string {
+	return ownerName + OutboxConfigMapSuffix
+}
+
+// name returns the name of the outbox ConfigMap of the named object.
+func (o *Outbox) name(ownerName string) string {
+	return ownerName + o.suffix
+}
+
+// Enqueue adds an event for endpoint to the outbox of owner. Events with a key are skipped when an event with
+// the same key is pending or was delivered within the event TTL; Enqueue then returns false. When the outbox
+// holds maxPendingEntries events, the oldest is dead-lettered to make room. The signing secret and format
+// the endpoint leaves unset default to the options set with SetDefaultOptions.
+func (o *Outbox) Enqueue(ctx context.Context, owner client.Object, endpoint Endpoint, key string, event *WebhookEvent) (bool, error) {
+	endpoint = endpoint.withDefaults()
+	var queued bool
+	var overflow int
+	err := o.update(ctx, types.NamespacedName{Namespace: owner.GetNamespace(), Name: owner.GetName()}, owner, func(state *outboxState) bool {
+		queued, overflow = false, 0
+		if key != "" && state.has(key) {
+			return false
+		}
+		state.sequence++
+		entry := OutboxEntry{Seq: state.sequence, Key: key, Endpoint: endpoint, Event: *event, TraceContext: event.TraceContext}
+		entry.Event.Sequence = state.nextChangeSequence(event.Application+"/"+event.ChangeID, o.now())
+		state.entries = append(state.entries, entry)
+		overflow = state.deadLetterOverflow(o.now())
+		queued = true
+		return true
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to queue webhook event for %s/%s: %w", owner.GetNamespace(), owner.GetName(), err)
+	}
+	if overflow > 0 {
+		droppedEvents.WithLabelValues(dropOutboxFull).Add(float64(overflow))
+	}
+	if queued {
+		select {
+		case o.notify <- struct{}{}:
+		default:
+		}
+	}
+	return queued, nil
+}
+
+// ReplayDeadLetters queues the dead letters of the named object's outbox for delivery again,
+// after its pending events. It returns how many events were queued.
+func (o *Outbox) ReplayDeadLetters(ctx context.Context, owner types.NamespacedName) (int, error) {
+	var replayed int
+	err := o.update(ctx, owner, nil, func(state *outboxState) bool {
+		replayed = len(state.deadLetters)
+		for _, dead := range state.deadLetters {
+			state.sequence++
+			entry := dead.OutboxEntry
+			entry.Seq, entry.Attempts, entry.NextAttempt, entry.LastError = state.sequence, 0, time.Time{}, ""
+			state.entries = append(state.entries, entry)
+		}
+		state.deadLetters = nil
+		return replayed > 0
+	})
+	return replayed, err
+}
+
+// load reads the named object's outbox, bypassing the cache. It returns nil when there is none.
+func (o *Outbox) load(ctx context.Context, owner types.NamespacedName) (*corev1.ConfigMap, *outboxState, error) {
+	cm := &corev1.ConfigMap{}
+	if err := o.reader.Get(ctx, types.NamespacedName{Namespace: owner.Namespace, Name: o.name(owner.Name)}, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	state, err := decodeOutbox(cm)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cm, state, nil
+}
+
+// update applies mutate to the named object's outbox and writes it back when mutate returns true,
+// retrying on conflicts. The outbox is created, owned by owner, when it does not exist and owner is set.
+func (o *Outbox) update(ctx context.Context, ownerKey types.NamespacedName, owner client.Object, mutate func(*outboxState) bool) error {
+	retriable := func(err error) bool { return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err) }
+	return retry.OnError(retry.DefaultRetry, retriable, func() error {
+		cm, state, err := o.load(ctx, ownerKey)
+		if err != nil {
+			return err
+		}
+		create := cm == nil
+		if create {
+			if owner == nil {
+				return nil // Nothing to update
+			}
+			if cm, err = o.newOutboxConfigMap(owner); err != nil {
+				return err
+			}
+			state = &outboxState{delivered: map[string]time.Time{}, changes: map[string]changeSequence{}}
+		}
+		state.expire(o.now(), o.eventTTL)
+		if !mutate(state) {
+			return nil
+		}
+		if err := state.encode(cm); err != nil {
+			return err
+		}
+		if create {
+			return o.client.Create(ctx, cm)
+		}
+		return o.client.Update(ctx, cm)
+	})
+}
+
+// newOutboxConfigMap returns an empty outbox for owner. The owner reference is not a controller
+// reference, so outbox updates do not trigger reconciles of the owner, but the outbox is garbage
+// collected with it.
+func (o *Outbox) newOutboxConfigMap(owner client.Object) (*corev1.ConfigMap, error) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      o.name(owner.GetName()),
+			Namespace: owner.GetNamespace(),
+			Labels:    map[string]string{o.label: owner.GetName()},
+		},
+	}
+	if err := controllerutil.SetOwnerReference(owner, cm, o.client.Scheme()); err != nil {
+		return nil, fmt.Errorf("failed to set owner reference on webhook outbox: %w", err)
+	}
+	return cm, nil
+}
+
+// decodeOutbox reads the state stored in an outbox ConfigMap.
+func decodeOutbox(cm *corev1.ConfigMap) (*outboxState, error) {
+	state := &outboxState{delivered: map[string]time.Time{}, changes: map[string]changeSequence{}}
+	for key, target := range map[string]interface{}{
+		outboxEntriesKey:     &state.entries,
+		outboxDeadLettersKey: &state.deadLetters,
+		outboxDeliveredKey:   &state.delivered,
+		outboxChangesKey:     &state.changes,
+	} {
+		if data := cm.Data[key]; data != "" {
+			if err := json.Unmarshal([]byte(data), target); err != nil {
+				return nil, fmt.Errorf("invalid %s in webhook outbox %s/%s: %w", key, cm.Namespace, cm.Name, err)
+			}
+		}
+	}
+	if seq := cm.Data[outboxSequenceKey]; seq != "" {
+		n, err := strconv.ParseInt(seq, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in webhook outbox %s/%s: %w", outboxSequenceKey, cm.Namespace, cm.Name, err)
+		}
+		state.sequence = n
+	}
+	sort.SliceStable(state.entries, func(i, j int) bool { return state.entries[i].Seq < state.entries[j].Seq })
+	return state, nil
+}
+
+// encode writes the state into an outbox ConfigMap. Empty collections are left out.
+func (s *outboxState) encode(cm *corev1.ConfigMap) error {
+	cm.Data = map[string]string{outboxSequenceKey: strconv.FormatInt(s.sequence, 10)}
+	values := make(map[string]interface{})
+	if len(s.entries) > 0 {
+		values[outboxEntriesKey] = s.entries
+	}
+	if len(s.deadLetters) > 0 {
+		values[outboxDeadLettersKey] = s.deadLetters
+	}
+	if len(s.delivered) > 0 {
+		values[outboxDeliveredKey] = s.delivered
+	}
+	if len(s.changes) > 0 {
+		values[outboxChangesKey] = s.changes
+	}
+	for key, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode webhook outbox: %w", err)
+		}
+		cm.Data[key] = string(data)
+	}
+	return nil
+}
+
+// has reports whether an event with the key is pending or was delivered recently.
+func (s *outboxState) has(key string) bool {
+	if _, ok := s.delivered[key]; ok {
+		return true
+	}
+	for _, entry := range s.entries {
+		if entry.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// nextChangeSequence returns the next event sequence number of the change, forgetting the least recently
+// used changes beyond maxChangeSequences.
+func (s *outboxState) nextChangeSequence(change string, now time.Time) int64 {
+	next := changeSequence{Last: s.changes[change].Last + 1, At: now}
+	s.changes[change] = next
+	for len(s.changes) > maxChangeSequences {
+		oldest := ""
+		for key, seq := range s.changes {
+			if oldest == "" || seq.At.Before(s.changes[oldest].At) {
+				oldest = key
+			}
+		}
+		delete(s.changes, oldest)
+	}
+	return next.Last
+}
+
+// expire forgets the keys of events delivered, and the sequence numbers of changes without events,
+// for longer than eventTTL. A change queuing events again after that is numbered from 1 again.
+func (s *outboxState) expire(now time.Time, eventTTL time.Duration) {
+	cutoff := now.Add(-eventTTL)
+	for key, at := range s.delivered {
+		if at.Before(cutoff) {
+			delete(s.delivered, key)
+		}
+	}
+	for key, seq := range s.changes {
+		if seq.At.Before(cutoff) {
+			delete(s.changes, key)
+		}
+	}
+}
+
+// empty reports whether the outbox holds nothing worth keeping.
+func (s *outboxState) empty() bool {
+	return len(s.entries) == 0 && len(s.deadLetters) == 0 && len(s.delivered) == 0 && len(s.changes) == 0
+}
+
+// recordAttempt records the outcome of delivering the entry with the given sequence number: delivered
+// entries are removed, failed ones are retried after backoff or dead-lettered once maxAttempts is reached.
+func (s *outboxState) recordAttempt(seq int64, sendErr error, now time.Time, maxAttempts int, backoff func(attempt int) time.Duration) bool {
+	idx := -1
+	for i := range s.entries {
+		if s.entries[i].Seq == seq {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return false // Removed meanwhile, e.g. by another operator instance
+	}
+	entry := s.entries[idx]
+	if sendErr == nil {
+		s.entries = append(s.entries[:idx], s.entries[idx+1:]...)
+		if entry.Key != "" {
+			s.delivered[entry.Key] = now
+		}
+		return true
+	}
+
+	entry.Attempts++
+	entry.LastError = sendErr.Error()
+	if entry.Attempts < maxAttempts {
+		entry.NextAttempt = now.Add(backoff(entry.Attempts))
+		s.entries[idx] = entry
+		return true
+	}
+	s.entries = append(s.entries[:idx], s.entries[idx+1:]...)
+	s.deadLetter(entry, now)
+	return true
+}
+
+// deadLetterOverflow dead-letters the oldest entries beyond maxPendingEntries and returns how many.
+func (s *outboxState) deadLetterOverflow(now time.Time) int {
+	overflow := len(s.entries) - maxPendingEntries
+	if overflow <= 0 {
+		return 0
+	}
+	for _, entry := range s.entries[:overflow] {
+		entry.LastError = "outbox full"
+		s.deadLetter(entry, now)
+	}
+	s.entries = append([]OutboxEntry(nil), s.entries[overflow:]...)
+	return overflow
+}
+
+// deadLetter gives up on entry, keeping at most maxDeadLetters dead letters.
+func (s *outboxState) deadLetter(entry OutboxEntry, now time.Time) {
+	s.deadLetters = append(s.deadLetters, DeadLetter{OutboxEntry: entry, DeadLetteredAt: now})
+	if len(s.deadLetters) > maxDeadLetters {
+		s.deadLetters = s.deadLetters[len(s.deadLetters)-maxDeadLetters:]
+	}
+}
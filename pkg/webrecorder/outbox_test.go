@@ -0,0 +1,327 @@
+package webrecorder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// webhookServer records the steps of delivered events and fails while failing is set.
+type webhookServer struct {
+	mu        sync.Mutex
+	failing   bool
+	delivered []string
+}
+
+func (s *webhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failing {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var event WebhookEvent
+	_ = json.NewDecoder(r.Body).Decode(&event)
+	s.delivered = append(s.delivered, event.Step)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *webhookServer) setFailing(failing bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failing = failing
+}
+
+func (s *webhookServer) steps() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.delivered...)
+}
+
+func newTestOutbox(t *testing.T) (*Outbox, *Dispatcher, *[]DeliveryReport, *time.Time) {
+	t.Helper()
+	owner := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "uid-1"}}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(owner).Build()
+	outbox := NewOutbox(c, c)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	outbox.now = func() time.Time { return now }
+
+	var reports []DeliveryReport
+	dispatcher := NewDispatcher(outbox, func(_ context.Context, report DeliveryReport) error {
+		reports = append(reports, report)
+		return nil
+	})
+	dispatcher.maxAttempts = 3
+	dispatcher.initialInterval = time.Second
+	dispatcher.maxInterval = 3 * time.Second
+	return outbox, dispatcher, &reports, &now
+}
+
+func enqueue(t *testing.T, outbox *Outbox, url, key, step string) bool {
+	t.Helper()
+	owner := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "uid-1"}}
+	queued, err := outbox.Enqueue(context.Background(), owner, Endpoint{URL: url}, key, &WebhookEvent{ChangeID: "c1", Step: step})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	return queued
+}
+
+func TestOutboxNumbersEventsPerChange(t *testing.T) {
+	outbox, _, _, now := newTestOutbox(t)
+	owner := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "uid-1"}}
+	queue := func(o *Outbox, changeID, key string) {
+		t.Helper()
+		event := &WebhookEvent{ChangeID: changeID, Application: "app"}
+		if _, err := o.Enqueue(context.Background(), owner, Endpoint{URL: "http://example.invalid"}, key, event); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+	queue(outbox, "c1", "k1")
+	queue(outbox, "c1", "k1") // Duplicates do not use a number
+	queue(outbox, "c2", "")
+	// A second outbox on the same store stands in for a restarted operator
+	restarted := NewOutbox(outbox.client, outbox.reader)
+	restarted.now = outbox.now
+	queue(restarted, "c1", "")
+
+	_, state, err := outbox.load(context.Background(), types.NamespacedName{Namespace: "default", Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int64
+	for _, entry := range state.entries {
+		got = append(got, entry.Event.Sequence)
+	}
+	if len(got) != 3 || got[0] != 1 || got[1] != 1 || got[2] != 2 {
+		t.Errorf("sequence numbers = %v, want [1 1 2] for c1, c2, c1", got)
+	}
+
+	// The numbers of a change are forgotten with its last event
+	state.expire(now.Add(2*WebhookEventTTL), WebhookEventTTL)
+	if len(state.changes) != 0 {
+		t.Errorf("changes = %v, want expired", state.changes)
+	}
+}
+
+func TestRecorderLeavesEventsSentAroundTheOutboxUnnumbered(t *testing.T) {
+	sequences := make(chan int64, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event WebhookEvent
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		sequences <- event.Sequence
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	owner := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "uid-1"}}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(context.Context, client.WithWatch, client.ObjectKey, client.Object, ...client.GetOption) error {
+			return fmt.Errorf("etcd unavailable")
+		},
+	}).Build()
+	recorder := newWebhookEventRecorder(newHTTPClient(), Endpoint{URL: server.URL}.withDefaults(), "c1", "cluster")
+	recorder.outbox = NewOutbox(c, c)
+
+	recorder.AnnotatedEventf(owner, map[string]string{StepKey: "Apply"}, "Normal", "Applied", "applied")
+	select {
+	case sequence := <-sequences:
+		if sequence != 0 {
+			t.Errorf("sequence = %d, want none next to the sequence of the outbox", sequence)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the event was not sent directly after the outbox failed")
+	}
+}
+
+func TestOutboxForKeepsOutboxesApart(t *testing.T) {
+	outbox, dispatcher, reports, _ := newTestOutbox(t)
+	channels := NewOutboxFor(outbox.client, outbox.reader, "example.com/channel-outbox", "-channel-outbox")
+	channels.now = outbox.now
+	if !enqueue(t, channels, "http://example.invalid", "k1", "Apply") {
+		t.Fatal("event was not queued")
+	}
+	// Outboxes of different kinds do not share deduplication state
+	if !enqueue(t, outbox, "http://example.invalid", "k1", "Apply") {
+		t.Fatal("event was not queued in the default outbox")
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := outbox.client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "app-channel-outbox"}, cm); err != nil {
+		t.Fatalf("channel outbox ConfigMap: %v", err)
+	}
+	if cm.Labels["example.com/channel-outbox"] != "app" || cm.Labels[OutboxLabel] != "" {
+		t.Errorf("channel outbox labels = %v, want only the channel outbox label", cm.Labels)
+	}
+
+	// The default dispatcher only sees the default outbox
+	dispatcher.dispatchAll(context.Background())
+	if len(*reports) != 1 || (*reports)[0].Pending != 1 {
+		t.Errorf("reports = %+v, want one for the default outbox", *reports)
+	}
+}
+
+func TestOutboxEnqueueDeduplicatesAcrossInstances(t *testing.T) {
+	outbox, _, _, _ := newTestOutbox(t)
+	if !enqueue(t, outbox, "http://example.invalid", "k1", "Apply") {
+		t.Fatal("first event was not queued")
+	}
+
+	// A second outbox on the same store stands in for a restarted operator
+	restarted := NewOutbox(outbox.client, outbox.reader)
+	owner := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "uid-1"}}
+	if queued, err := restarted.Enqueue(context.Background(), owner, Endpoint{URL: "http://example.invalid"}, "k1", &WebhookEvent{}); err != nil || queued {
+		t.Errorf("duplicate event: queued=%v err=%v, want skipped", queued, err)
+	}
+	if !enqueue(t, outbox, "http://example.invalid", "", "Health") || !enqueue(t, outbox, "http://example.invalid", "", "Health") {
+		t.Error("events without a key must never be deduplicated")
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := outbox.client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "app" + OutboxConfigMapSuffix}, cm); err != nil {
+		t.Fatalf("outbox ConfigMap: %v", err)
+	}
+	if cm.Labels[OutboxLabel] != "app" || len(cm.OwnerReferences) != 1 || cm.OwnerReferences[0].Controller != nil {
+		t.Errorf("outbox metadata = %v / %v, want labelled and owned (not controlled) by the app", cm.Labels, cm.OwnerReferences)
+	}
+	state, err := decodeOutbox(cm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.entries) != 3 || state.entries[0].Seq != 1 || state.entries[2].Seq != 3 {
+		t.Errorf("entries = %+v, want 3 in sequence", state.entries)
+	}
+}
+
+func TestOutboxEnqueueDeadLettersOverflow(t *testing.T) {
+	outbox, _, _, _ := newTestOutbox(t)
+	drops := testutil.ToFloat64(droppedEvents.WithLabelValues(dropOutboxFull))
+
+	for i := 0; i < maxPendingEntries+2; i++ {
+		if !enqueue(t, outbox, "http://example.invalid", "", fmt.Sprintf("Step%d", i)) {
+			t.Fatalf("event %d was not queued", i)
+		}
+	}
+
+	_, state, err := outbox.load(context.Background(), types.NamespacedName{Namespace: "default", Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.entries) != maxPendingEntries || state.entries[0].Event.Step != "Step2" {
+		t.Errorf("%d pending entries starting at %s, want %d starting at Step2", len(state.entries), state.entries[0].Event.Step, maxPendingEntries)
+	}
+	if len(state.deadLetters) != 2 || state.deadLetters[0].Event.Step != "Step0" || state.deadLetters[1].LastError != "outbox full" {
+		t.Errorf("dead letters = %+v, want the two oldest events", state.deadLetters)
+	}
+	if got := testutil.ToFloat64(droppedEvents.WithLabelValues(dropOutboxFull)) - drops; got != 2 {
+		t.Errorf("dropped events = %v, want 2", got)
+	}
+}
+
+func TestDispatcherDeliversInOrderWithBackoff(t *testing.T) {
+	outbox, dispatcher, reports, now := newTestOutbox(t)
+	server := &webhookServer{failing: true}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	enqueue(t, outbox, ts.URL, "k1", "First")
+	enqueue(t, outbox, ts.URL, "k2", "Second")
+	ctx := context.Background()
+
+	dispatcher.dispatchAll(ctx)
+	if got := (*reports)[len(*reports)-1]; got.Pending != 2 || got.LastError == "" {
+		t.Errorf("report after a failed attempt = %+v, want 2 pending with the error", got)
+	}
+
+	// The endpoint recovers, but the head entry is not due before its backoff has passed
+	server.setFailing(false)
+	dispatcher.dispatchAll(ctx)
+	if steps := server.steps(); len(steps) != 0 {
+		t.Fatalf("delivered %v before the backoff elapsed", steps)
+	}
+	*now = now.Add(time.Second)
+	dispatcher.dispatchAll(ctx)
+	if steps := server.steps(); len(steps) != 2 || steps[0] != "First" || steps[1] != "Second" {
+		t.Errorf("delivered %v, want [First Second]", steps)
+	}
+	if got := (*reports)[len(*reports)-1]; got.Pending != 0 || len(got.DeadLetters) != 0 {
+		t.Errorf("final report = %+v, want nothing pending", got)
+	}
+
+	// Delivered keys are remembered, so the same event is not sent twice
+	if enqueue(t, outbox, ts.URL, "k1", "First") {
+		t.Error("a delivered event was queued again")
+	}
+}
+
+func TestDispatcherDeadLettersAndReplays(t *testing.T) {
+	outbox, dispatcher, reports, now := newTestOutbox(t)
+	server := &webhookServer{failing: true}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	enqueue(t, outbox, ts.URL, "k1", "Lost")
+	enqueue(t, outbox, ts.URL, "k2", "Next")
+	ctx := context.Background()
+	failures := testutil.ToFloat64(deliveryAttempts.WithLabelValues(deliveryFailed))
+	retries := testutil.ToFloat64(deliveryRetries)
+	drops := testutil.ToFloat64(droppedEvents.WithLabelValues(dropRetriesExhausted))
+
+	// Three failed attempts of each event: 1s and 2s apart, then both are dead-lettered
+	for i := 0; i < 6; i++ {
+		dispatcher.dispatchAll(ctx)
+		*now = now.Add(3 * time.Second)
+	}
+	last := (*reports)[len(*reports)-1]
+	if last.Pending != 0 || len(last.DeadLetters) != 2 || last.DeadLetters[0].Event.Step != "Lost" || last.DeadLetters[0].Attempts != 3 {
+		t.Fatalf("report = %+v, want both events dead-lettered after 3 attempts", last)
+	}
+	if got := testutil.ToFloat64(deliveryAttempts.WithLabelValues(deliveryFailed)) - failures; got != 6 {
+		t.Errorf("failed deliveries = %v, want 6", got)
+	}
+	if got := testutil.ToFloat64(deliveryRetries) - retries; got != 4 {
+		t.Errorf("retries = %v, want 4", got)
+	}
+	if got := testutil.ToFloat64(droppedEvents.WithLabelValues(dropRetriesExhausted)) - drops; got != 2 {
+		t.Errorf("dropped events = %v, want 2", got)
+	}
+
+	server.setFailing(false)
+	replayed, err := outbox.ReplayDeadLetters(ctx, types.NamespacedName{Namespace: "default", Name: "app"})
+	if err != nil || replayed != 2 {
+		t.Fatalf("ReplayDeadLetters = %d, %v, want 2", replayed, err)
+	}
+	dispatcher.dispatchAll(ctx)
+	if steps := server.steps(); len(steps) != 2 || steps[0] != "Lost" || steps[1] != "Next" {
+		t.Errorf("delivered %v after replay, want [Lost Next]", steps)
+	}
+
+	// Once the delivered keys expire the outbox is removed
+	*now = now.Add(2 * WebhookEventTTL)
+	dispatcher.dispatchAll(ctx)
+	if err := outbox.client.Get(ctx, client.ObjectKey{Namespace: "default", Name: OutboxName("app")}, &corev1.ConfigMap{}); err == nil {
+		t.Error("empty outbox was not deleted")
+	}
+}
+
+func TestRetryInterval(t *testing.T) {
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 30: 5 * time.Second} {
+		if got := retryInterval(attempt, time.Second, 5*time.Second); got != want {
+			t.Errorf("retryInterval(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
@@ -1,23 +1,24 @@
 package webrecorder
 
 import (
-	"bytes"
 	"context"
 	"crypto/sha256"
 	"crypto/tls"
-	"encoding/json"
 	"fmt"
 	"io"
-	"math"
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	configv1alpha1 "github.com/infinilabs/runtime-operator/api/config/v1alpha1"
 )
 
 // --- Constants for structured event annotations ---
@@ -29,6 +30,8 @@ const (
 	StatusKey = "infini.cloud/status"
 	// StepKey is the key used in annotations to specify the specific step within a phase (e.g., "StepScaleDownSetReplicas").
 	StepKey = "infini.cloud/step"
+	// ComponentKey is the key used in annotations to name the component an event is about, if any.
+	ComponentKey = "infini.cloud/component"
 )
 
 // --- Constants for event status values ---
@@ -45,12 +48,119 @@ const (
 // --- Constants for Webhook sender configuration ---
 const (
 	// WebhookRetryMaxAttempts is the maximum number of times to retry sending a webhook event upon failure.
-	WebhookRetryMaxAttempts = 3
+	WebhookRetryMaxAttempts = configv1alpha1.DefaultWebhookRetryMaxAttempts
 	// WebhookRetryInitialInterval is the base duration to wait before the first retry.
 	// Subsequent retries will use exponential backoff.
-	WebhookRetryInitialInterval = 10 * time.Second
+	WebhookRetryInitialInterval = configv1alpha1.DefaultWebhookRetryInitialInterval
+	// WebhookRetryMaxInterval caps the exponential backoff between retries.
+	WebhookRetryMaxInterval = configv1alpha1.DefaultWebhookRetryMaxInterval
+	// WebhookTimeout is the default timeout of a single webhook request.
+	WebhookTimeout = configv1alpha1.DefaultWebhookTimeout
+	// WebhookEventTTL is the default duration a sent event is remembered to suppress duplicates.
+	WebhookEventTTL = configv1alpha1.DefaultWebhookEventTTL
 )
 
+// Options tunes the webhook sender of recorders created afterwards by NewWebhookEventRecorder.
+type Options struct {
+	// EventTTL is how long a sent event is remembered to suppress duplicates.
+	EventTTL time.Duration
+	// Timeout bounds each webhook request.
+	Timeout time.Duration
+	// RetryMaxAttempts is how many times an event is sent before it is dropped (dead-lettered with an Outbox).
+	RetryMaxAttempts int
+	// RetryInitialInterval is the backoff before the first retry; it doubles on every further retry.
+	RetryInitialInterval time.Duration
+	// RetryMaxInterval caps the backoff between retries.
+	RetryMaxInterval time.Duration
+	// Outbox, when set, persists events for delivery by a Dispatcher instead of sending them from a goroutine.
+	Outbox *Outbox
+	// TLSConfig configures the connections to webhook endpoints, see NewTLSConfig.
+	// Nil verifies endpoints against the system roots.
+	TLSConfig *tls.Config
+	// SigningSecret signs the requests of recorders that are not given a secret of their own.
+	SigningSecret *SigningSecret
+	// Format is the payload format of recorders that are not given a format of their own.
+	Format Format
+	// SecretReader reads signing secrets for events sent without an Outbox.
+	SecretReader client.Reader
+}
+
+var defaultOptions = Options{
+	EventTTL:             WebhookEventTTL,
+	Timeout:              WebhookTimeout,
+	RetryMaxAttempts:     WebhookRetryMaxAttempts,
+	RetryInitialInterval: WebhookRetryInitialInterval,
+	RetryMaxInterval:     WebhookRetryMaxInterval,
+}
+
+// SetDefaultOptions replaces the options of recorders created afterwards. Zero fields keep their defaults.
+// It is meant to be called once at startup, before any recorder is created.
+func SetDefaultOptions(opts Options) {
+	if opts.EventTTL > 0 {
+		defaultOptions.EventTTL = opts.EventTTL
+	}
+	if opts.Timeout > 0 {
+		defaultOptions.Timeout = opts.Timeout
+	}
+	if opts.RetryMaxAttempts > 0 {
+		defaultOptions.RetryMaxAttempts = opts.RetryMaxAttempts
+	}
+	if opts.RetryInitialInterval > 0 {
+		defaultOptions.RetryInitialInterval = opts.RetryInitialInterval
+	}
+	if opts.RetryMaxInterval > 0 {
+		defaultOptions.RetryMaxInterval = opts.RetryMaxInterval
+	}
+	if opts.Outbox != nil {
+		defaultOptions.Outbox = opts.Outbox
+	}
+	if opts.TLSConfig != nil {
+		defaultOptions.TLSConfig = opts.TLSConfig
+	}
+	if opts.SigningSecret != nil {
+		defaultOptions.SigningSecret = opts.SigningSecret
+	}
+	if opts.Format != "" {
+		defaultOptions.Format = opts.Format
+	}
+	if opts.SecretReader != nil {
+		defaultOptions.SecretReader = opts.SecretReader
+	}
+}
+
+// Endpoint is where and how webhook events are sent.
+type Endpoint struct {
+	// URL is the webhook endpoint.
+	URL string `json:"url"`
+	// SigningSecret, if set, references the key requests are signed with.
+	SigningSecret *SigningSecret `json:"signingSecret,omitempty"`
+	// Format is the payload format; empty is FormatWebhook.
+	Format Format `json:"format,omitempty"`
+}
+
+// withDefaults fills the signing secret and format the endpoint leaves unset from the default options.
+func (e Endpoint) withDefaults() Endpoint {
+	if e.SigningSecret == nil {
+		e.SigningSecret = defaultOptions.SigningSecret
+	}
+	if e.Format == "" {
+		e.Format = defaultOptions.Format
+	}
+	return e
+}
+
+// retryInterval is the exponential backoff before retry number attempt (starting at 1), capped at max.
+func retryInterval(attempt int, initial, max time.Duration) time.Duration {
+	interval := initial
+	for i := 1; i < attempt && interval < max; i++ {
+		interval *= 2
+	}
+	if interval > max {
+		return max
+	}
+	return interval
+}
+
 // ResourceChange tracks resource changes (CPU, memory, disk, replicas)
 type ResourceChange struct {
 	// CPUBefore is the CPU value before the change
@@ -95,6 +205,56 @@ type WebhookEvent struct {
 	Step string `json:"step"`
 	// ResourceChange tracks changes in CPU, memory, disk, and replicas
 	ResourceChange *ResourceChange `json:"resource_change,omitempty"`
+	// Application is the name of the object the event is about.
+	Application string `json:"application,omitempty"`
+	// Component is the component the event is about, if any.
+	Component string `json:"component,omitempty"`
+	// Sequence numbers the events of a change, and of an application without change, from 1. It lets
+	// receivers restore the order of events that arrive out of order, e.g. after a retry.
+	Sequence int64 `json:"sequence,omitempty"`
+	// Summary describes the outcome of a change; only set on its terminal event.
+	Summary *ChangeSummary `json:"summary,omitempty"`
+	// TraceContext is the W3C trace context the event was recorded in, propagated in the headers
+	// of the webhook request rather than in the payload.
+	TraceContext map[string]string `json:"-"`
+}
+
+// ChangeSummary is the outcome of a change, attached to its terminal event.
+type ChangeSummary struct {
+	// State is how the change ended: "completed", "failed" or "superseded" by another change.
+	State string `json:"state"`
+	// Phase is the phase of the application when the change ended.
+	Phase string `json:"phase"`
+	// StartedAt is when the change started, in RFC3339 format.
+	StartedAt string `json:"started_at"`
+	// Duration is how long the change took, e.g. "1m30s".
+	Duration string `json:"duration"`
+	// Created and Updated list the objects the change created and updated, as "Kind/name".
+	Created []string `json:"created,omitempty"`
+	Updated []string `json:"updated,omitempty"`
+}
+
+// NewEvent returns the payload of an event about object, described by annotations (see PhaseKey,
+// StatusKey, StepKey, ComponentKey and InjectTraceContext), as recorders send it.
+func NewEvent(object runtime.Object, annotations map[string]string, resourceChange *ResourceChange, changeID, clusterID, eventtype, reason, message string) *WebhookEvent {
+	event := &WebhookEvent{
+		ChangeID:       changeID,
+		ClusterID:      clusterID,
+		Phase:          annotations[PhaseKey],
+		Level:          eventtype,
+		Message:        message,
+		Timestamp:      time.Now().UTC().Format(time.RFC3339Nano),
+		Payload:        map[string]string{"reason": reason},
+		Status:         annotations[StatusKey],
+		Step:           annotations[StepKey],
+		ResourceChange: resourceChange,
+		Component:      annotations[ComponentKey],
+		TraceContext:   traceContextOf(annotations),
+	}
+	if named, ok := object.(client.Object); ok {
+		event.Application = named.GetName()
+	}
+	return event
 }
 
 // WebhookEventRecorder is a custom implementation of the record.EventRecorder interface.
@@ -106,84 +266,113 @@ type WebhookEventRecorder struct {
 	recorder record.EventRecorder
 	// httpClient is used to send events to the webrecorder.
 	httpClient *http.Client
-	// webhookURL is the destination endpoint for the event payloads.
-	webhookURL string
+	// endpoint is where and how the event payloads are sent.
+	endpoint Endpoint
 	// eventID is a static identifier for the source of this event stream (e.g., a pod name).
 	eventID string
 	// clusterID is a static identifier for the cluster.
 	clusterID string
 	// logger is used for internal logging of the recorder itself.
 	logger logr.Logger
-	// sentEvents tracks sent events to avoid duplicates (key: hash of changeId+phase+step+status)
+	// sentEvents tracks sent events to avoid duplicates (key: hash of changeId+phase+step+status+reason+message)
 	sentEvents sync.Map
 	// eventTTL is how long to keep track of sent events (default: 1 hour)
 	eventTTL time.Duration
+	// retryMaxAttempts, retryInitialInterval and retryMaxInterval control the retry backoff of sendEvent.
+	retryMaxAttempts     int
+	retryInitialInterval time.Duration
+	retryMaxInterval     time.Duration
+	// outbox persists events for the Dispatcher; nil sends them directly.
+	outbox *Outbox
+	// secrets reads the signing secret of the endpoint.
+	secrets client.Reader
+	// registry, if set, owns the goroutines sending events directly and cancels them on shutdown.
+	registry *Registry
+	// sequence numbers the events sent without an outbox; the outbox numbers the events it persists.
+	sequence atomic.Int64
 }
 
 // NewWebhookEventRecorder creates a new WebhookEventRecorder.
 // It requires a webhookURL, identifiers for the event source (eventID) and cluster (clusterID),
 // and an existing recorder (typically from the controller-runtime manager) to wrap.
+//
+// Each call returns an independent recorder with its own connections and deduplication state;
+// controllers should obtain recorders from a Registry instead, which shares both across calls.
 func NewWebhookEventRecorder(webhookURL, eventID, clusterID string) record.EventRecorder {
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: true,
-		},
+	return newWebhookEventRecorder(newHTTPClient(), Endpoint{URL: webhookURL}.withDefaults(), eventID, clusterID)
+}
+
+func newWebhookEventRecorder(httpClient *http.Client, endpoint Endpoint, eventID, clusterID string) *WebhookEventRecorder {
+	return &WebhookEventRecorder{
+		endpoint:             endpoint,
+		eventID:              eventID,
+		clusterID:            clusterID,
+		logger:               log.Log.WithName("Web Event Recorder"),
+		httpClient:           httpClient,
+		eventTTL:             defaultOptions.EventTTL, // Keep track of sent events, 1 hour by default
+		retryMaxAttempts:     defaultOptions.RetryMaxAttempts,
+		retryInitialInterval: defaultOptions.RetryInitialInterval,
+		retryMaxInterval:     defaultOptions.RetryMaxInterval,
+		outbox:               defaultOptions.Outbox,
+		secrets:              defaultOptions.SecretReader,
 	}
-	wr := &WebhookEventRecorder{
-		webhookURL: webhookURL,
-		eventID:    eventID,
-		clusterID:  clusterID,
-		logger:     log.Log.WithName("Web Event Recorder"),
-		httpClient: &http.Client{
-			Transport: tr,
-			Timeout:   15 * time.Second,
+}
+
+// newHTTPClient returns the client used to post webhook events.
+func newHTTPClient() *http.Client {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if defaultOptions.TLSConfig != nil {
+		tlsConfig = defaultOptions.TLSConfig.Clone()
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
 		},
-		eventTTL: 1 * time.Hour, // Keep track of sent events for 1 hour
+		Timeout: defaultOptions.Timeout,
 	}
-
-	// Start cleanup goroutine to remove old entries
-	go wr.cleanupOldEvents()
-
-	return wr
 }
 
-// cleanupOldEvents periodically cleans up old event entries from the sentEvents map
-func (r *WebhookEventRecorder) cleanupOldEvents() {
-	ticker := time.NewTicker(10 * time.Minute)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		now := time.Now()
-		r.sentEvents.Range(func(key, value interface{}) bool {
-			if sentTime, ok := value.(time.Time); ok {
-				if now.Sub(sentTime) > r.eventTTL {
-					r.sentEvents.Delete(key)
-				}
-			}
-			return true
-		})
-	}
+// expireSentEvents forgets the events sent before cutoff and returns how many are still remembered.
+func (r *WebhookEventRecorder) expireSentEvents(cutoff time.Time) int {
+	remaining := 0
+	r.sentEvents.Range(func(key, value interface{}) bool {
+		if sentTime, ok := value.(time.Time); ok && sentTime.Before(cutoff) {
+			r.sentEvents.Delete(key)
+		} else {
+			remaining++
+		}
+		return true
+	})
+	return remaining
 }
 
 // generateEventKey creates a unique key for event deduplication
-// Key is based on: changeId + phase + step + status
-func (r *WebhookEventRecorder) generateEventKey(changeID, phase, step, status string) string {
-	data := fmt.Sprintf("%s:%s:%s:%s", changeID, phase, step, status)
+// Key is based on: changeId + phase + step + status + reason + message, so that distinct
+// events of one step (e.g. two ConfigMaps created by SyncConfigMap) are not collapsed.
+func (r *WebhookEventRecorder) generateEventKey(changeID, phase, step, status, reason, message string) string {
+	return EventKey(changeID, phase, step, status, reason, message)
+}
+
+// EventKey returns the key deduplicating an event of the stream identified by source, typically a change ID.
+func EventKey(source, phase, step, status, reason, message string) string {
+	data := fmt.Sprintf("%s:%s:%s:%s:%s:%s", source, phase, step, status, reason, message)
 	hash := sha256.Sum256([]byte(data))
 	return fmt.Sprintf("%x", hash[:16]) // Use first 16 bytes of hash
 }
 
-// shouldSendEvent checks if the event should be sent (not a duplicate)
-func (r *WebhookEventRecorder) shouldSendEvent(changeID, phase, step, status string) bool {
-	key := r.generateEventKey(changeID, phase, step, status)
-
+// shouldSendEvent checks if the event with the given key should be sent (not a duplicate).
+// Events remembered for longer than the TTL are sent again.
+func (r *WebhookEventRecorder) shouldSendEvent(key string) bool {
+	now := time.Now()
 	// Check if we've already sent this event
-	if _, exists := r.sentEvents.Load(key); exists {
-		return false // Duplicate, don't send
+	if sent, exists := r.sentEvents.Load(key); exists {
+		if sentTime, ok := sent.(time.Time); ok && now.Sub(sentTime) <= r.eventTTL {
+			return false // Duplicate, don't send
+		}
 	}
 
 	// Mark as sent
-	r.sentEvents.Store(key, time.Now())
+	r.sentEvents.Store(key, now)
 	return true
 }
 
@@ -220,6 +409,21 @@ func (r *WebhookEventRecorder) AnnotatedEventf(object runtime.Object, annotation
 // It sends the event to the underlying recorder AND asynchronously sends a structured
 // version of the event with resource change information to the configured webrecorder.
 func (r *WebhookEventRecorder) AnnotatedEventfWithResourceChange(object runtime.Object, annotations map[string]string, resourceChange *ResourceChange, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.annotatedEvent(object, annotations, resourceChange, nil, true, eventtype, reason, messageFmt, args...)
+}
+
+// SummaryEventf is like AnnotatedEventf, for the terminal event of a change carrying its summary.
+func (r *WebhookEventRecorder) SummaryEventf(object runtime.Object, annotations map[string]string, summary *ChangeSummary, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.annotatedEvent(object, annotations, nil, summary, true, eventtype, reason, messageFmt, args...)
+}
+
+// UndedupedEventf is like AnnotatedEventf, but the event is sent even when an event with the same
+// change ID, phase, step and status was sent before, e.g. for repeated health transitions.
+func (r *WebhookEventRecorder) UndedupedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.annotatedEvent(object, annotations, nil, nil, false, eventtype, reason, messageFmt, args...)
+}
+
+func (r *WebhookEventRecorder) annotatedEvent(object runtime.Object, annotations map[string]string, resourceChange *ResourceChange, summary *ChangeSummary, dedupe bool, eventtype, reason, messageFmt string, args ...interface{}) {
 	if r == nil {
 		return
 	}
@@ -230,7 +434,7 @@ func (r *WebhookEventRecorder) AnnotatedEventfWithResourceChange(object runtime.
 	}
 
 	// If no webrecorder URL is configured, do nothing further.
-	if r.webhookURL == "" {
+	if r.endpoint.URL == "" {
 		return
 	}
 
@@ -239,8 +443,14 @@ func (r *WebhookEventRecorder) AnnotatedEventfWithResourceChange(object runtime.
 	status := annotations[StatusKey]
 	step := annotations[StepKey]
 
+	message := fmt.Sprintf(messageFmt, args...)
+
 	// Check if we should send this event (deduplication)
-	if !r.shouldSendEvent(r.eventID, phase, step, status) {
+	var key string
+	if dedupe {
+		key = r.generateEventKey(r.eventID, phase, step, status, reason, message)
+	}
+	if key != "" && !r.shouldSendEvent(key) {
 		r.logger.V(1).Info("Skipping duplicate event",
 			"changeID", r.eventID,
 			"phase", phase,
@@ -250,100 +460,135 @@ func (r *WebhookEventRecorder) AnnotatedEventfWithResourceChange(object runtime.
 	}
 
 	// Construct the structured event payload.
-	eventData := &WebhookEvent{
-		ChangeID:       r.eventID,
-		ClusterID:      r.clusterID,
-		Phase:          phase,
-		Level:          eventtype,
-		Message:        fmt.Sprintf(messageFmt, args...),
-		Timestamp:      time.Now().UTC().Format(time.RFC3339Nano),
-		Payload:        map[string]string{"reason": reason},
-		Status:         status,
-		Step:           step,
-		ResourceChange: resourceChange,
+	eventData := NewEvent(object, annotations, resourceChange, r.eventID, r.clusterID, eventtype, reason, message)
+	eventData.Summary = summary
+
+	// Persist the event so it survives endpoint outages and operator restarts
+	if owner, ok := object.(client.Object); ok && r.outbox != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), r.httpClient.Timeout)
+		defer cancel()
+		queued, err := r.outbox.Enqueue(ctx, owner, r.endpoint, key, eventData)
+		if err == nil {
+			if !queued {
+				r.logger.V(1).Info("Skipping duplicate event", "changeID", r.eventID, "phase", phase, "step", step, "status", status)
+			}
+			return
+		}
+		r.logger.Error(err, "Failed to persist webhook event in the outbox, sending it directly")
+	}
+	if r.outbox == nil {
+		// The outbox numbers the events it queues. An event sent around it is left unnumbered rather
+		// than given a number of a separate sequence, which would collide with the queued events.
+		eventData.Sequence = r.sequence.Add(1)
 	}
 
 	// Send the event asynchronously to avoid blocking the reconciler.
-	go r.sendEvent(eventData)
-}
-
-// sendEvent marshals the event data and posts it to the webhook URL.
-// It implements a retry mechanism with exponential backoff for transient failures.
-func (r *WebhookEventRecorder) sendEvent(data *WebhookEvent) {
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		r.logger.Error(err, "Failed to marshal webhook event data, the event will not be sent")
+	if r.registry == nil {
+		go r.sendEvent(context.Background(), eventData)
 		return
 	}
+	if !r.registry.goSend(func(ctx context.Context) { r.sendEvent(ctx, eventData) }) {
+		droppedEvents.WithLabelValues(dropShutdown).Inc()
+		r.logger.Info("Dropping webhook event, the operator is shutting down", "changeID", r.eventID, "phase", phase, "step", step)
+	}
+}
 
-	for attempt := 0; attempt < WebhookRetryMaxAttempts; attempt++ {
+// sendEvent posts the event to the webhook URL, retrying with exponential backoff until ctx is done.
+// It is used without an Outbox; the event is lost when the operator restarts or all attempts fail.
+func (r *WebhookEventRecorder) sendEvent(ctx context.Context, data *WebhookEvent) {
+	for attempt := 0; attempt < r.retryMaxAttempts; attempt++ {
 		// For the first attempt, send immediately. For subsequent attempts, wait.
 		if attempt > 0 {
-			// Calculate exponential backoff: 2s, 4s, 8s
-			backoffDuration := WebhookRetryInitialInterval * time.Duration(math.Pow(2, float64(attempt-1)))
+			backoffDuration := retryInterval(attempt, r.retryInitialInterval, r.retryMaxInterval)
 			r.logger.Info("Webhook send failed. Retrying...",
-				"url", r.webhookURL,
-				"attempt", fmt.Sprintf("%d/%d", attempt+1, WebhookRetryMaxAttempts),
+				"url", r.endpoint.URL,
+				"attempt", fmt.Sprintf("%d/%d", attempt+1, r.retryMaxAttempts),
 				"retry_after", backoffDuration.String())
-			time.Sleep(backoffDuration)
-		}
-
-		// Use a context with a timeout for each individual request attempt.
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-
-		req, err := http.NewRequestWithContext(ctx, "POST", r.webhookURL, bytes.NewBuffer(jsonData))
-		if err != nil {
-			// This is a non-retriable error (failed to create the request itself).
-			r.logger.Error(err, "Failed to create webhook request, the event will not be sent")
-			return
-		}
-		req.Header.Set("Content-Type", "application/json")
-
-		resp, err := r.httpClient.Do(req)
-		if err != nil {
-			r.logger.Error(err, "Failed to send webhook event", "url", r.webhookURL)
-			if isNetworkError(err) {
-				r.logger.Info("Network error detected, skipping remaining retries", "error", err.Error())
+			deliveryRetries.Inc()
+			if !waitForRetry(ctx, backoffDuration) {
+				droppedEvents.WithLabelValues(dropShutdown).Inc()
+				r.logger.Info("Dropping webhook event, the operator is shutting down", "url", r.endpoint.URL)
 				return
 			}
-			continue // Proceed to the next retry attempt
 		}
-		defer resp.Body.Close()
-
-		// Read the response body
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		respBody := string(bodyBytes)
 
-		// On successful send, check the status code.
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			r.logger.V(1).Info("Successfully sent event to webhook", "url", r.webhookURL, "status", resp.Status)
-			return // Success, exit the function.
+		err := r.postEvent(ctx, data)
+		countDelivery(err)
+		if err == nil {
+			r.logger.V(1).Info("Successfully sent event to webhook", "url", r.endpoint.URL)
+			return
 		}
-
-		// If the status code indicates an error, treat it as a failure.
-		r.logger.Error(nil, "Webhook endpoint returned error status",
-			"url", r.webhookURL,
-			"status_code", resp.StatusCode,
-			"status", resp.Status,
-			"response_body", respBody)
+		r.logger.Error(err, "Failed to send webhook event", "url", r.endpoint.URL)
 	}
 
 	// If the loop completes, all retries have failed.
+	droppedEvents.WithLabelValues(dropRetriesExhausted).Inc()
 	r.logger.Error(nil, "Failed to send webhook event after all retries, dropping the event.",
-		"url", r.webhookURL,
-		"max_retries", WebhookRetryMaxAttempts)
+		"url", r.endpoint.URL,
+		"max_retries", r.retryMaxAttempts)
+}
+
+// postEvent sends the event once, signed when the recorder has a signing secret.
+func (r *WebhookEventRecorder) postEvent(ctx context.Context, data *WebhookEvent) error {
+	return postEvent(ctx, r.httpClient, r.endpoint, data, r.secrets)
 }
 
-// isNetworkError checks if the error is a network-related error that should not be retried
-func isNetworkError(err error) bool {
-	if err == nil {
+// waitForRetry waits for the backoff before a retry, counting the event as queued meanwhile.
+// It returns false when ctx is done first.
+func waitForRetry(ctx context.Context, backoff time.Duration) bool {
+	queuedEvents.WithLabelValues(queueRetry).Inc()
+	defer queuedEvents.WithLabelValues(queueRetry).Dec()
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
 		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// postEvent sends a single webhook request in the format of the endpoint, signed when it has a signing
+// secret, which is read with secrets. Connection failures and non-2xx responses are errors; both are worth
+// retrying, as the endpoint may just be restarting.
+func postEvent(ctx context.Context, httpClient *http.Client, endpoint Endpoint, data *WebhookEvent, secrets client.Reader) error {
+	var secret []byte
+	if endpoint.SigningSecret != nil {
+		var err error
+		if secret, err = endpoint.SigningSecret.resolve(ctx, secrets); err != nil {
+			return err
+		}
+	}
+
+	// Use a context with a timeout for each individual request attempt.
+	ctx, cancel := context.WithTimeout(ctx, httpClient.Timeout)
+	defer cancel()
+
+	req, body, err := newEventRequest(ctx, endpoint, data)
+	if err != nil {
+		return err
+	}
+	if len(secret) > 0 {
+		signRequest(req, secret, body, time.Now())
+	}
+
+	span := startDeliverySpan(ctx, req, data)
+	inFlightRequests.Inc()
+	defer inFlightRequests.Dec()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		endDeliverySpan(span, 0, err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		endDeliverySpan(span, resp.StatusCode, nil)
+		return nil
 	}
-	errStr := err.Error()
-	// Check for common non-retriable network errors
-	return strings.Contains(errStr, "connection refused") ||
-		strings.Contains(errStr, "EOF") ||
-		strings.Contains(errStr, "no such host") ||
-		strings.Contains(errStr, "network is unreachable")
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("webhook endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	endDeliverySpan(span, resp.StatusCode, err)
+	return err
 }
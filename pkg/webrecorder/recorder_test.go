@@ -27,8 +27,8 @@ func TestNewWebhookEventRecorder(t *testing.T) {
 		t.Error("NewWebhookEventRecorder() did not return WebhookEventRecorder type")
 	}
 
-	if webRecorder.webhookURL != webhookURL {
-		t.Errorf("webhookURL = %s, want %s", webRecorder.webhookURL, webhookURL)
+	if webRecorder.endpoint.URL != webhookURL {
+		t.Errorf("webhookURL = %s, want %s", webRecorder.endpoint.URL, webhookURL)
 	}
 	if webRecorder.eventID != eventID {
 		t.Errorf("eventID = %s, want %s", webRecorder.eventID, eventID)
@@ -0,0 +1,159 @@
+package webrecorder
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// registryCleanupInterval is how often expired events and idle recorders are dropped.
+	registryCleanupInterval = 10 * time.Minute
+	// registryShutdownTimeout bounds how long Start waits for in-flight events after the manager stops.
+	registryShutdownTimeout = 5 * time.Second
+)
+
+// registryKey identifies the recorder of one change reported to one endpoint.
+type registryKey struct {
+	webhookURL    string
+	format        Format
+	signingSecret SigningSecret
+	changeID      string
+	clusterID     string
+}
+
+// registryEntry is a recorder held by the registry with the time it was last handed out.
+type registryEntry struct {
+	recorder *WebhookEventRecorder
+	lastUsed time.Time
+}
+
+// Registry hands out WebhookEventRecorders shared per webhook URL and change ID, so events of a
+// change are deduplicated across reconciles and all recorders share one HTTP connection pool.
+// Recorders from the registry only send webhooks; Kubernetes events are left to the caller.
+//
+// Registry implements manager.Runnable on every replica: Start runs the single cleanup loop and,
+// when the manager stops, cancels the retries of events sent directly and waits for them.
+type Registry struct {
+	httpClient *http.Client
+	eventTTL   time.Duration
+	logger     logr.Logger
+	now        func() time.Time
+
+	// ctx is cancelled on shutdown to abort the events being sent.
+	ctx    context.Context
+	cancel context.CancelFunc
+	sends  sync.WaitGroup
+
+	mu        sync.Mutex
+	stopped   bool
+	recorders map[registryKey]*registryEntry
+}
+
+// NewRegistry returns an empty registry using the default options.
+func NewRegistry() *Registry {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Registry{
+		httpClient: newHTTPClient(),
+		eventTTL:   defaultOptions.EventTTL,
+		logger:     log.Log.WithName("webhook-recorders"),
+		now:        time.Now,
+		ctx:        ctx,
+		cancel:     cancel,
+		recorders:  make(map[registryKey]*registryEntry),
+	}
+}
+
+// Recorder returns the recorder of the change, creating it on first use. The signing secret and format
+// the endpoint leaves unset are taken from the default options.
+func (g *Registry) Recorder(endpoint Endpoint, changeID, clusterID string) *WebhookEventRecorder {
+	endpoint = endpoint.withDefaults()
+	key := registryKey{webhookURL: endpoint.URL, format: endpoint.Format, changeID: changeID, clusterID: clusterID}
+	if endpoint.SigningSecret != nil {
+		key.signingSecret = *endpoint.SigningSecret
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	entry, ok := g.recorders[key]
+	if !ok {
+		recorder := newWebhookEventRecorder(g.httpClient, endpoint, changeID, clusterID)
+		recorder.registry = g
+		entry = &registryEntry{recorder: recorder}
+		g.recorders[key] = entry
+		activeRecorders.Set(float64(len(g.recorders)))
+	}
+	entry.lastUsed = g.now()
+	return entry.recorder
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable; every replica records events.
+func (g *Registry) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable. It cleans up recorders until ctx is cancelled, then shuts down.
+func (g *Registry) Start(ctx context.Context) error {
+	ticker := time.NewTicker(registryCleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			g.shutdown(registryShutdownTimeout)
+			return nil
+		case <-ticker.C:
+			g.cleanup()
+		}
+	}
+}
+
+// cleanup forgets expired events and drops recorders that are idle and remember no event.
+func (g *Registry) cleanup() {
+	cutoff := g.now().Add(-g.eventTTL)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for key, entry := range g.recorders {
+		if entry.recorder.expireSentEvents(cutoff) == 0 && entry.lastUsed.Before(cutoff) {
+			delete(g.recorders, key)
+		}
+	}
+	activeRecorders.Set(float64(len(g.recorders)))
+}
+
+// goSend runs send in a goroutine tracked for shutdown. It returns false once the registry is stopped.
+func (g *Registry) goSend(send func(ctx context.Context)) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.stopped {
+		return false
+	}
+	g.sends.Add(1)
+	go func() {
+		defer g.sends.Done()
+		send(g.ctx)
+	}()
+	return true
+}
+
+// shutdown stops accepting events, cancels the ones being sent and waits up to timeout for them.
+func (g *Registry) shutdown(timeout time.Duration) {
+	g.mu.Lock()
+	g.stopped = true
+	g.mu.Unlock()
+	g.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		g.sends.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		g.logger.Info("Timed out waiting for webhook events being sent", "timeout", timeout.String())
+	}
+	g.httpClient.CloseIdleConnections()
+}
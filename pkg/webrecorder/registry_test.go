@@ -0,0 +1,128 @@
+package webrecorder
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRegistrySharesRecordersPerChange(t *testing.T) {
+	var requestCount int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requestCount, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	registry := NewRegistry()
+	first := registry.Recorder(Endpoint{URL: server.URL}, "change-123", "cluster-test")
+	if second := registry.Recorder(Endpoint{URL: server.URL}, "change-123", "cluster-test"); second != first {
+		t.Error("Expected the same recorder for the same URL and change ID")
+	}
+	other := registry.Recorder(Endpoint{URL: server.URL}, "change-456", "cluster-test")
+	if other == first {
+		t.Error("Expected a different recorder for another change ID")
+	}
+	if other.httpClient != first.httpClient {
+		t.Error("Expected recorders to share the HTTP client")
+	}
+
+	obj := &testObject{name: "test-app"}
+	annotations := map[string]string{PhaseKey: "Reconcile", StatusKey: StatusSuccess, StepKey: "SyncComponent"}
+	// Each reconcile looks the recorder up again; the event must still be sent once
+	for i := 0; i < 3; i++ {
+		registry.Recorder(Endpoint{URL: server.URL}, "change-123", "cluster-test").
+			AnnotatedEventf(obj, annotations, "Normal", "ReconcileCompleted", "Reconciliation completed")
+	}
+	time.Sleep(500 * time.Millisecond)
+
+	if count := atomic.LoadInt32(&requestCount); count != 1 {
+		t.Errorf("Expected 1 request, got %d (deduplication across lookups failed)", count)
+	}
+}
+
+func TestEventDeduplicationDifferentMessage(t *testing.T) {
+	var requestCount int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requestCount, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	recorder := NewRegistry().Recorder(Endpoint{URL: server.URL}, "change-123", "cluster-test")
+	obj := &testObject{name: "test-app"}
+	annotations := map[string]string{PhaseKey: "ApplyResources", StatusKey: StatusSuccess, StepKey: "SyncConfigMap"}
+	recorder.AnnotatedEventf(obj, annotations, "Normal", "ResourceCreated", "Created ConfigMap: a")
+	recorder.AnnotatedEventf(obj, annotations, "Normal", "ResourceCreated", "Created ConfigMap: b")
+	time.Sleep(500 * time.Millisecond)
+
+	if count := atomic.LoadInt32(&requestCount); count != 2 {
+		t.Errorf("Expected 2 requests for different resources of one step, got %d", count)
+	}
+}
+
+func TestRegistryCleanup(t *testing.T) {
+	registry := NewRegistry()
+	now := time.Now()
+	registry.now = func() time.Time { return now }
+
+	recorder := registry.Recorder(Endpoint{URL: "https://example.com/webhook"}, "change-123", "cluster-test")
+	recorder.sentEvents.Store("key", now)
+
+	// Events are still remembered within the TTL
+	now = now.Add(registry.eventTTL / 2)
+	registry.cleanup()
+	if len(registry.recorders) != 1 {
+		t.Fatalf("Expected the recorder to be kept, got %d recorders", len(registry.recorders))
+	}
+
+	// Once the events expired and the recorder stayed idle, it is dropped
+	now = now.Add(registry.eventTTL)
+	registry.cleanup()
+	if len(registry.recorders) != 0 {
+		t.Errorf("Expected the idle recorder to be dropped, got %d recorders", len(registry.recorders))
+	}
+	if registry.Recorder(Endpoint{URL: "https://example.com/webhook"}, "change-123", "cluster-test") == recorder {
+		t.Error("Expected a new recorder after cleanup")
+	}
+}
+
+func TestRegistryShutdownCancelsRetries(t *testing.T) {
+	var requestCount int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requestCount, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	registry := NewRegistry()
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() { stopped <- registry.Start(ctx) }()
+
+	recorder := registry.Recorder(Endpoint{URL: server.URL}, "change-123", "cluster-test")
+	recorder.retryInitialInterval = time.Hour
+	obj := &testObject{name: "test-app"}
+	recorder.AnnotatedEventf(obj, map[string]string{PhaseKey: "Reconcile", StatusKey: StatusFailure}, "Warning", "ReconcileFailed", "failed")
+	time.Sleep(200 * time.Millisecond)
+
+	// The failed event waits an hour for its retry; stopping the manager must not
+	cancel()
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("Start() returned %v", err)
+		}
+	case <-time.After(registryShutdownTimeout):
+		t.Fatal("Start() did not return after the context was cancelled")
+	}
+	if count := atomic.LoadInt32(&requestCount); count != 1 {
+		t.Errorf("Expected 1 request before shutdown, got %d", count)
+	}
+	if registry.goSend(func(context.Context) {}) {
+		t.Error("Expected no events to be sent after shutdown")
+	}
+}
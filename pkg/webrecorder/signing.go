@@ -0,0 +1,117 @@
+package webrecorder
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// --- Constants for signed webhook requests ---
+const (
+	// SignatureHeader carries the HMAC-SHA256 signature of a request, as "sha256=<hex>".
+	SignatureHeader = "X-Infini-Signature"
+	// TimestampHeader carries the Unix time, in seconds, the request was signed at.
+	// Receivers should reject requests whose timestamp is too old, to prevent replays.
+	TimestampHeader = "X-Infini-Timestamp"
+)
+
+// SigningSecret references the key of a Secret holding the HMAC key webhook requests are signed with.
+type SigningSecret struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Key       string `json:"key"`
+}
+
+func (s *SigningSecret) String() string {
+	return fmt.Sprintf("%s/%s[%s]", s.Namespace, s.Name, s.Key)
+}
+
+// resolve reads the signing key from the Secret.
+func (s *SigningSecret) resolve(ctx context.Context, reader client.Reader) ([]byte, error) {
+	if reader == nil {
+		return nil, fmt.Errorf("no client to read webhook signing secret %s", s)
+	}
+	secret := &corev1.Secret{}
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: s.Namespace, Name: s.Name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to read webhook signing secret %s: %w", s, err)
+	}
+	key, ok := secret.Data[s.Key]
+	if !ok || len(key) == 0 {
+		return nil, fmt.Errorf("webhook signing secret %s has no key %q", s, s.Key)
+	}
+	return key, nil
+}
+
+// Sign returns the signature of a request body sent at timestamp: the hex HMAC-SHA256, keyed with
+// secret, of the timestamp, a dot and the body, prefixed with "sha256=".
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// signRequest sets the timestamp and signature headers of req.
+func signRequest(req *http.Request, secret []byte, body []byte, now time.Time) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+}
+
+// TLSOptions configures how webhook endpoints are trusted and how the operator authenticates to them.
+type TLSOptions struct {
+	// CAFile is a PEM bundle of the certificate authorities trusted instead of the system roots.
+	CAFile string
+	// CertFile and KeyFile are the PEM client certificate and key presented to endpoints requiring one.
+	// They are read again on every handshake, so rotated files are picked up.
+	CertFile string
+	KeyFile  string
+	// InsecureSkipVerify disables the verification of endpoint certificates.
+	InsecureSkipVerify bool
+}
+
+// NewTLSConfig returns the TLS configuration of webhook requests, checking that the files can be loaded.
+func NewTLSConfig(opts TLSOptions) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read webhook CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("webhook CA bundle %s contains no PEM certificate", opts.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		if _, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile); err != nil {
+			return nil, fmt.Errorf("failed to load webhook client certificate: %w", err)
+		}
+		certFile, keyFile := opts.CertFile, opts.KeyFile
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+			if err != nil {
+				return nil, err
+			}
+			return &cert, nil
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,135 @@
+package webrecorder
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// signatureServer checks the signature of every request against secret and counts the valid ones.
+type signatureServer struct {
+	secret []byte
+	valid  chan bool
+}
+
+func (s *signatureServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	ok := r.Header.Get(TimestampHeader) != "" &&
+		r.Header.Get(SignatureHeader) == Sign(s.secret, r.Header.Get(TimestampHeader), body)
+	s.valid <- ok
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestSign(t *testing.T) {
+	// Receivers recompute the signature over "<timestamp>.<body>"
+	got := Sign([]byte("secret"), "1700000000", []byte(`{"change_id":"c1"}`))
+	want := "sha256=bbe52b7dc04db87b9827da4d980f6753900e884b356e14da78ca5ffe871b9723"
+	if got != want {
+		t.Errorf("Sign() = %q, want %q", got, want)
+	}
+	if Sign([]byte("secret"), "1700000001", []byte(`{"change_id":"c1"}`)) == got {
+		t.Error("Expected the timestamp to be part of the signature")
+	}
+	if Sign([]byte("other"), "1700000000", []byte(`{"change_id":"c1"}`)) == got {
+		t.Error("Expected the secret to be part of the signature")
+	}
+}
+
+func TestDispatcherSignsRequests(t *testing.T) {
+	outbox, dispatcher, _, _ := newTestOutbox(t)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhook-signing", Namespace: "default"},
+		Data:       map[string][]byte{"secret": []byte("s3cr3t")},
+	}
+	if err := outbox.client.Create(context.Background(), secret); err != nil {
+		t.Fatalf("failed to create secret: %v", err)
+	}
+	server := &signatureServer{secret: []byte("s3cr3t"), valid: make(chan bool, 1)}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	owner := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "uid-1"}}
+	ref := &SigningSecret{Namespace: "default", Name: "webhook-signing", Key: "secret"}
+	if _, err := outbox.Enqueue(context.Background(), owner, Endpoint{URL: ts.URL, SigningSecret: ref}, "k1", &WebhookEvent{ChangeID: "c1"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	dispatcher.dispatchAll(context.Background())
+
+	select {
+	case ok := <-server.valid:
+		if !ok {
+			t.Error("Expected a valid signature")
+		}
+	default:
+		t.Fatal("Expected the event to be delivered")
+	}
+}
+
+func TestDispatcherRetriesWhenSigningSecretIsMissing(t *testing.T) {
+	outbox, dispatcher, reports, _ := newTestOutbox(t)
+	server := &signatureServer{valid: make(chan bool, 1)}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	owner := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "uid-1"}}
+	ref := &SigningSecret{Namespace: "default", Name: "missing", Key: "secret"}
+	if _, err := outbox.Enqueue(context.Background(), owner, Endpoint{URL: ts.URL, SigningSecret: ref}, "k1", &WebhookEvent{ChangeID: "c1"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	dispatcher.dispatchAll(context.Background())
+
+	if len(server.valid) != 0 {
+		t.Error("Expected no unsigned request to be sent")
+	}
+	last := (*reports)[len(*reports)-1]
+	if last.Pending != 1 || last.LastError == "" {
+		t.Errorf("Expected the event to stay pending with an error, got %+v", last)
+	}
+}
+
+func TestNewTLSConfigTrustsCABundle(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	post := func(opts TLSOptions) error {
+		config, err := NewTLSConfig(opts)
+		if err != nil {
+			t.Fatalf("NewTLSConfig: %v", err)
+		}
+		httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: config}, Timeout: WebhookTimeout}
+		return postEvent(context.Background(), httpClient, Endpoint{URL: ts.URL}, &WebhookEvent{}, nil)
+	}
+
+	if err := post(TLSOptions{}); err == nil {
+		t.Error("Expected the self-signed endpoint to be rejected by default")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := post(TLSOptions{CAFile: caFile}); err != nil {
+		t.Errorf("Expected the endpoint to be trusted with its CA bundle, got %v", err)
+	}
+	if err := post(TLSOptions{InsecureSkipVerify: true}); err != nil {
+		t.Errorf("Expected the endpoint to be accepted in insecure mode, got %v", err)
+	}
+
+	if _, err := NewTLSConfig(TLSOptions{CAFile: filepath.Join(t.TempDir(), "missing.crt")}); err == nil {
+		t.Error("Expected an error for a missing CA bundle")
+	}
+	if _, err := NewTLSConfig(TLSOptions{CertFile: caFile}); err == nil {
+		t.Error("Expected an error for a client certificate without key")
+	}
+}
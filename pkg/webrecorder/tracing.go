@@ -0,0 +1,71 @@
+package webrecorder
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName names the tracer of webhook deliveries.
+const tracerName = "github.com/infinilabs/runtime-operator/pkg/webrecorder"
+
+// Keys of the W3C trace context in event annotations; see InjectTraceContext.
+const (
+	TraceParentKey = "traceparent"
+	TraceStateKey  = "tracestate"
+)
+
+// InjectTraceContext adds the trace context of ctx to the annotations of an event, with the global
+// propagator. The webhook request delivering the event continues that trace, so receivers can
+// correlate the event with the reconcile that recorded it.
+func InjectTraceContext(ctx context.Context, annotations map[string]string) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(annotations))
+}
+
+// traceContextOf returns the trace context carried by event annotations, or nil.
+func traceContextOf(annotations map[string]string) map[string]string {
+	if annotations[TraceParentKey] == "" {
+		return nil
+	}
+	traceContext := map[string]string{TraceParentKey: annotations[TraceParentKey]}
+	if state := annotations[TraceStateKey]; state != "" {
+		traceContext[TraceStateKey] = state
+	}
+	return traceContext
+}
+
+// startDeliverySpan starts the client span of a webhook request in the trace the event was recorded in,
+// and sets the trace context headers of the request to it.
+func startDeliverySpan(ctx context.Context, req *http.Request, data *WebhookEvent) trace.Span {
+	propagator := otel.GetTextMapPropagator()
+	ctx = propagator.Extract(ctx, propagation.MapCarrier(data.TraceContext))
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "DeliverWebhook",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.full", req.URL.Redacted()),
+			attribute.String("infini.change_id", data.ChangeID),
+			attribute.String("infini.cluster_id", data.ClusterID),
+			attribute.String("infini.application", data.Application),
+			attribute.String("infini.component", data.Component),
+		))
+	propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	return span
+}
+
+// endDeliverySpan ends the span of a webhook request with its outcome.
+func endDeliverySpan(span trace.Span, statusCode int, err error) {
+	if statusCode != 0 {
+		span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,96 @@
+package webrecorder
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// useTestTracing installs a recording tracer provider and the W3C propagator for the duration of the test.
+func useTestTracing(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	spans := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return spans
+}
+
+func TestNewEventKeepsTraceContextOutOfThePayload(t *testing.T) {
+	useTestTracing(t)
+	ctx, span := otel.Tracer("test").Start(context.Background(), "Reconcile")
+	defer span.End()
+
+	annotations := map[string]string{PhaseKey: "ApplyResources"}
+	InjectTraceContext(ctx, annotations)
+	if annotations[TraceParentKey] == "" {
+		t.Fatalf("no traceparent injected into %v", annotations)
+	}
+
+	event := NewEvent(&corev1.Service{}, annotations, nil, "c1", "cluster", "Normal", "Applied", "applied")
+	if event.TraceContext[TraceParentKey] != annotations[TraceParentKey] {
+		t.Errorf("TraceContext = %v, want the injected traceparent", event.TraceContext)
+	}
+	if _, found := event.Payload[TraceParentKey]; found {
+		t.Errorf("the trace context leaked into the payload: %v", event.Payload)
+	}
+	if NewEvent(&corev1.Service{}, map[string]string{}, nil, "c1", "cluster", "Normal", "Applied", "applied").TraceContext != nil {
+		t.Error("an event recorded outside a trace has a trace context")
+	}
+}
+
+func TestDispatcherPropagatesTraceContext(t *testing.T) {
+	spans := useTestTracing(t)
+	ctx, reconcileSpan := otel.Tracer("test").Start(context.Background(), "Reconcile")
+	reconcileSpan.End()
+
+	traceparents := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		traceparents <- r.Header.Get(TraceParentKey)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	outbox, dispatcher, _, _ := newTestOutbox(t)
+	annotations := map[string]string{StepKey: "SyncService"}
+	InjectTraceContext(ctx, annotations)
+	owner := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "uid-1"}}
+	event := NewEvent(owner, annotations, nil, "c1", "cluster", "Normal", "Applied", "applied")
+	if _, err := outbox.Enqueue(context.Background(), owner, Endpoint{URL: server.URL}, "k1", event); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	dispatcher.dispatchAll(context.Background())
+
+	// The request continues the reconcile's trace from a delivery span
+	var delivery sdktrace.ReadOnlySpan
+	for _, span := range spans.Ended() {
+		if span.Name() == "DeliverWebhook" {
+			delivery = span
+		}
+	}
+	if delivery == nil {
+		t.Fatal("no delivery span was recorded")
+	}
+	if delivery.SpanKind() != trace.SpanKindClient || delivery.Parent().SpanID() != reconcileSpan.SpanContext().SpanID() {
+		t.Errorf("delivery span is not a client child of the reconcile span: kind %v, parent %v", delivery.SpanKind(), delivery.Parent().SpanID())
+	}
+	want := "00-" + delivery.SpanContext().TraceID().String() + "-" + delivery.SpanContext().SpanID().String() + "-01"
+	if got := <-traceparents; got != want {
+		t.Errorf("traceparent header = %q, want %q", got, want)
+	}
+}